@@ -0,0 +1,80 @@
+package cli
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	cmd2 "github.com/axellelanca/urlshortener/cmd"
+	"github.com/axellelanca/urlshortener/internal/repository"
+	"github.com/axellelanca/urlshortener/internal/services"
+	"github.com/spf13/cobra"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+)
+
+// backupOutFlag stocke le chemin du fichier de sauvegarde (--out)
+var backupOutFlag string
+
+// backupFormatFlag stocke le format de sauvegarde demandé (--format)
+var backupFormatFlag string
+
+// BackupCmd représente la commande 'backup'
+var BackupCmd = &cobra.Command{
+	Use:   "backup",
+	Short: "Sauvegarde la base de liens dans un fichier, même pendant que le serveur tourne.",
+	Long: `Cette commande écrit une copie cohérente de la base de données dans le fichier indiqué.
+
+Avec SQLite, la sauvegarde utilise VACUUM INTO et produit une base directement réutilisable.
+Le format 'json' produit un dump logique, indépendant du driver, protégé par un checksum.
+
+Exemples:
+  url-shortener backup --out="backup.db"
+  url-shortener backup --out="backup.json" --format=json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if backupOutFlag == "" {
+			fmt.Println("Erreur : le flag --out est obligatoire.")
+			os.Exit(1)
+		}
+
+		cfg := cmd2.Cfg
+		if cfg == nil {
+			fmt.Println("Erreur : configuration introuvable.")
+			os.Exit(1)
+		}
+
+		db, err := gorm.Open(sqlite.Open(cfg.Database.Name), &gorm.Config{})
+		if err != nil {
+			log.Fatalf("FATAL : impossible d'ouvrir la base SQLite : %v", err)
+		}
+
+		sqlDB, err := db.DB()
+		if err != nil {
+			log.Fatalf("FATAL: Échec de l'obtention de la base de données SQL sous-jacente: %v", err)
+		}
+		defer sqlDB.Close()
+
+		backupService := services.NewBackupService(repository.NewBackupRepository(db))
+
+		format := backupFormatFlag
+		if format == "" {
+			format = backupService.DefaultFormat()
+		}
+
+		if err := backupService.Backup(backupOutFlag, format); err != nil {
+			fmt.Printf("Erreur : impossible de sauvegarder la base : %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("Sauvegarde (%s) écrite avec succès dans %s\n", format, backupOutFlag)
+	},
+}
+
+func init() {
+	BackupCmd.Flags().StringVarP(&backupOutFlag, "out", "o", "", "Fichier de sauvegarde à créer")
+	BackupCmd.Flags().StringVarP(&backupFormatFlag, "format", "f", "", "Format de sauvegarde : sqlite ou json (par défaut selon le driver)")
+	BackupCmd.MarkFlagRequired("out")
+
+	cmd2.RootCmd.AddCommand(BackupCmd)
+}
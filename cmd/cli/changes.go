@@ -0,0 +1,115 @@
+package cli
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	cmd2 "github.com/axellelanca/urlshortener/cmd"
+	"github.com/axellelanca/urlshortener/internal/repository"
+	"github.com/axellelanca/urlshortener/internal/services"
+	"github.com/glebarez/sqlite"
+	"github.com/spf13/cobra"
+	"gorm.io/gorm"
+)
+
+// changesCodeFlag et changesDomainFlag désignent le lien (--code, --domain)
+var changesCodeFlag, changesDomainFlag string
+
+// changesLimitFlag stocke le nombre maximal d'empreintes affichées (--limit)
+var changesLimitFlag int
+
+// ChangesCmd représente la commande 'changes'
+var ChangesCmd = &cobra.Command{
+	Use:   "changes",
+	Short: "Affiche l'historique des changements de contenu de la destination d'un lien surveillé.",
+	Long: `Cette commande affiche les empreintes du contenu de la destination d'un lien créé avec
+--watch ou --watch-selector, de la plus récente à la plus ancienne. Elles sont relevées par le serveur
+à l'intervalle du moniteur : la première sert de référence, les suivantes correspondent chacune à un changement.
+
+Exemples:
+  url-shortener changes --code="xyz123"
+  url-shortener changes --code="promo" --domain="sho.rt" --limit=5`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if changesCodeFlag == "" {
+			fmt.Println("Erreur : le flag --code est obligatoire.")
+			os.Exit(1)
+		}
+		if changesLimitFlag < 1 || changesLimitFlag > services.MaxContentHistoryLimit {
+			fmt.Printf("Erreur : --limit doit être compris entre 1 et %d.\n", services.MaxContentHistoryLimit)
+			os.Exit(1)
+		}
+
+		cfg := cmd2.Cfg
+		if cfg == nil {
+			fmt.Println("Erreur : configuration introuvable.")
+			os.Exit(1)
+		}
+
+		db, err := gorm.Open(sqlite.Open(cfg.Database.Name), &gorm.Config{})
+		if err != nil {
+			log.Fatalf("FATAL : impossible d'ouvrir la base SQLite : %v", err)
+		}
+		sqlDB, err := db.DB()
+		if err != nil {
+			log.Fatalf("FATAL: Échec de l'obtention de la base de données SQL sous-jacente: %v", err)
+		}
+		defer sqlDB.Close()
+
+		linkService := services.NewLinkService(repository.NewLinkRepository(db), services.LinkServiceOptions{})
+		domainService := services.NewDomainService(repository.NewDomainRepository(db), cfg.Server.BaseURL)
+
+		domainID, err := domainService.DomainIDForName(changesDomainFlag)
+		if err != nil {
+			fmt.Printf("Erreur : %v\n", err)
+			os.Exit(1)
+		}
+		link, err := linkService.GetLinkByShortCode(domainID, changesCodeFlag)
+		if err != nil {
+			if err == gorm.ErrRecordNotFound {
+				fmt.Println("Erreur : code court introuvable.")
+			} else {
+				fmt.Printf("Erreur inattendue : %v\n", err)
+			}
+			os.Exit(1)
+		}
+
+		snapshots, err := linkService.ContentHistory(link, changesLimitFlag)
+		if err != nil {
+			fmt.Printf("Erreur inattendue : %v\n", err)
+			os.Exit(1)
+		}
+
+		switch {
+		case !link.WatchContent:
+			fmt.Printf("Le contenu de la destination du lien %s n'est pas surveillé.\n", link.ShortCode)
+		case link.WatchSelector != "":
+			fmt.Printf("Contenu surveillé pour le lien %s : %s (sélecteur %q)\n", link.ShortCode, link.LongURL, link.WatchSelector)
+		default:
+			fmt.Printf("Contenu surveillé pour le lien %s : %s (page entière)\n", link.ShortCode, link.LongURL)
+		}
+		if len(snapshots) == 0 {
+			fmt.Println("Aucune empreinte relevée pour le moment.")
+			return
+		}
+
+		for i, snapshot := range snapshots {
+			// La plus ancienne empreinte de l'historique complet est la référence
+			label := fmt.Sprintf("changement mineur (%d/64 bits)", snapshot.Distance)
+			if i == len(snapshots)-1 && len(snapshots) < changesLimitFlag {
+				label = "référence"
+			} else if snapshot.Significant {
+				label = fmt.Sprintf("changement significatif (%d/64 bits)", snapshot.Distance)
+			}
+			fmt.Printf("%s\t%s\t%d mot(s)\n", snapshot.CreatedAt.Local().Format("2006-01-02 15:04"), label, snapshot.Words)
+		}
+	},
+}
+
+func init() {
+	ChangesCmd.Flags().StringVar(&changesCodeFlag, "code", "", "Code court du lien")
+	ChangesCmd.Flags().StringVar(&changesDomainFlag, "domain", "", "Domaine personnalisé du lien (domaine par défaut si vide)")
+	ChangesCmd.Flags().IntVar(&changesLimitFlag, "limit", services.DefaultContentHistoryLimit, "Nombre maximal d'empreintes affichées")
+	ChangesCmd.MarkFlagRequired("code")
+	cmd2.RootCmd.AddCommand(ChangesCmd)
+}
@@ -1,14 +1,21 @@
 package cli
 
 import (
+	"bufio"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
-	"net/url"
 	"os"
+	"strings"
+	"time"
 
 	cmd2 "github.com/axellelanca/urlshortener/cmd"
+	"github.com/axellelanca/urlshortener/internal/experiment"
+	"github.com/axellelanca/urlshortener/internal/models"
 	"github.com/axellelanca/urlshortener/internal/repository"
 	"github.com/axellelanca/urlshortener/internal/services"
+	"github.com/axellelanca/urlshortener/internal/targeting"
 	"github.com/glebarez/sqlite"
 	"github.com/spf13/cobra"
 
@@ -19,28 +26,104 @@ import (
 // TODO : Faire une variable longURLFlag qui stockera la valeur du flag --url
 var longURLFlag string
 
+// ownerFlag stocke le propriétaire optionnel du lien (--owner)
+var ownerFlag string
+
+// aliasFlag stocke le code court personnalisé optionnel (--alias)
+var aliasFlag string
+
+// dedupeFlag force ou désactive la déduplication pour cette commande (--dedupe), sinon le réglage global s'applique
+var dedupeFlag bool
+
+// domainFlag stocke le domaine personnalisé des liens créés (--domain), domaine par défaut sinon
+var domainFlag string
+
+// workspaceFlag stocke l'espace de travail des liens créés (--workspace), espace par défaut sinon
+var workspaceFlag string
+
+// passwordFlag stocke le mot de passe optionnel demandé avant la redirection (--password)
+var passwordFlag string
+
+// previewFlag impose la page d'aperçu avant chaque redirection (--preview)
+var previewFlag bool
+
+// rulesFileFlag stocke le chemin d'un fichier JSON de règles de redirection (--rules)
+var rulesFileFlag string
+
+// variantsFileFlag stocke le chemin d'un fichier JSON de variantes A/B pondérées (--variants)
+var variantsFileFlag string
+
+// forwardQueryFlag stocke le mode de transmission des paramètres du lien court à la destination (--forward-query)
+var forwardQueryFlag string
+
+// utmFlags stocke les modèles UTM ajoutés à la destination (--utm-source, --utm-medium, --utm-campaign)
+var utmFlags services.UTMTemplate
+
+// statusFlag stocke le code HTTP de la redirection (--status), 302 par défaut
+var statusFlag int
+
+// cacheControlFlag stocke l'en-tête Cache-Control de la redirection (--cache-control)
+var cacheControlFlag string
+
+// prefixFlag crée un lien préfixe : /{code}/reste redirige vers la destination suivie du reste (--prefix)
+var prefixFlag bool
+
+// maxViewsFlag stocke le nombre de redirections autorisées d'un lien à usage limité (--max-views)
+var maxViewsFlag int
+
+// selfDestructFlag efface la destination après la dernière redirection autorisée (--self-destruct)
+var selfDestructFlag bool
+
+// activeFromFlag et activeUntilFlag stockent la fenêtre d'activité du lien (--active-from, --active-until)
+var activeFromFlag, activeUntilFlag string
+
+// inactiveURLFlag stocke la destination servie hors de la fenêtre d'activité (--inactive-url)
+var inactiveURLFlag string
+
+// titleFlag, descriptionFlag et tagsFlag stockent le titre, les notes et les étiquettes du lien
+// (--title, --description, --tag)
+var titleFlag, descriptionFlag string
+var tagsFlag []string
+
+// watchFlag et watchSelectorFlag activent la surveillance du contenu de la destination (--watch, --watch-selector)
+var watchFlag bool
+var watchSelectorFlag string
+
+// urlsFileFlag stocke le chemin d'un fichier d'URLs à raccourcir en lot (--file)
+var urlsFileFlag string
+
 // CreateCmd représente la commande 'create'
 var CreateCmd = &cobra.Command{
 	Use:   "create",
 	Short: "Crée une URL courte à partir d'une URL longue.",
 	Long: `Cette commande raccourcit une URL longue fournie et affiche le code court généré.
 
-Exemple:
-  url-shortener create --url="https://www.google.com/search?q=go+lang"`,
+Avec --file, chaque ligne du fichier contient une URL, éventuellement suivie d'un alias
+séparé par un espace. Les lignes vides et celles commençant par '#' sont ignorées.
+Tous les liens valides sont créés dans une seule transaction.
+
+Exemples:
+  url-shortener create --url="https://www.google.com/search?q=go+lang"
+  url-shortener create --url="https://go.dev" --alias="golang"
+  url-shortener create --url="https://go.dev" --domain="sho.rt"
+  url-shortener create --url="https://intranet.example.com/doc" --password="s3cret"
+  url-shortener create --url="https://example.com/app" --rules="rules.json"
+  url-shortener create --url="https://example.com/landing" --variants="variants.json"
+  url-shortener create --url="https://example.com/promo" --utm-source="newsletter" --utm-campaign="{code}" --forward-query=merge
+  url-shortener create --url="https://example.com/" --status=301 --cache-control="public, max-age=86400"
+  url-shortener create --url="https://docs.example.com/" --alias="docs" --prefix
+  url-shortener create --url="https://shop.example.com/p/42" --watch-selector="main .fiche-produit"
+  url-shortener create --file="urls.txt" --owner="marketing"`,
 	Run: func(cmd *cobra.Command, args []string) {
 
 		// TODO 1: Valider que le flag --url a été fourni.
-		if longURLFlag == "" {
-			fmt.Println("Erreur : le flag --url est obligatoire.")
+		if (longURLFlag == "") == (urlsFileFlag == "") {
+			fmt.Println("Erreur : un et un seul des flags --url ou --file doit être fourni.")
 			os.Exit(1)
 		}
 
-		// TODO Validation basique du format de l'URL avec le package url et la fonction ParseRequestURI
-		// si erreur, os.Exit(1)
-		if _, err := url.ParseRequestURI(longURLFlag); err != nil {
-			fmt.Printf("Erreur : format d'URL invalide : %v\n", err)
-			os.Exit(1)
-		}
+		// La validation et la normalisation de l'URL sont faites par le LinkService,
+		// avec les mêmes règles que l'API.
 
 		// TODO : Charger la configuration chargée globalement via cmd.cfg
 		cfg := cmd2.Cfg
@@ -65,30 +148,291 @@ Exemple:
 
 		// TODO : Initialiser les repositories et services nécessaires NewLinkRepository & NewLinkService
 		linkRepo := repository.NewLinkRepository(db)
-		linkService := services.NewLinkService(linkRepo)
+		linkServiceOptions, err := services.LinkServiceOptionsFromConfig(cfg)
+		if err != nil {
+			log.Fatalf("FATAL : Configuration de création des liens invalide : %v", err)
+		}
+		linkService := services.NewLinkService(linkRepo, linkServiceOptions)
+		domainService := services.NewDomainService(repository.NewDomainRepository(db), cfg.Server.BaseURL)
+		if err := domainService.RegisterOwnHosts(linkServiceOptions.URLValidator); err != nil {
+			log.Fatalf("FATAL : %v", err)
+		}
+		workspace := resolveWorkspace(db, workspaceFlag)
+		linkService = linkService.ForWorkspace(workspace)
+		domainService = domainService.ForWorkspace(workspace.ID)
+
+		domainID, err := domainService.DomainIDForName(domainFlag)
+		if err != nil {
+			fmt.Printf("Erreur : %v\n", err)
+			os.Exit(1)
+		}
+
+		var deduplicate *bool
+		if cmd.Flags().Changed("dedupe") {
+			deduplicate = &dedupeFlag
+		}
+
+		rules, err := readRulesFile(rulesFileFlag)
+		if err != nil {
+			fmt.Printf("Erreur : impossible de lire les règles de redirection : %v\n", err)
+			os.Exit(1)
+		}
+		variants, err := readVariantsFile(variantsFileFlag)
+		if err != nil {
+			fmt.Printf("Erreur : impossible de lire les variantes A/B : %v\n", err)
+			os.Exit(1)
+		}
+
+		activeFrom, err := parseScheduleFlag(activeFromFlag)
+		if err != nil {
+			fmt.Printf("Erreur : --active-from invalide : %v\n", err)
+			os.Exit(1)
+		}
+		activeUntil, err := parseScheduleFlag(activeUntilFlag)
+		if err != nil {
+			fmt.Printf("Erreur : --active-until invalide : %v\n", err)
+			os.Exit(1)
+		}
+
+		if urlsFileFlag != "" {
+			createFromFile(linkService, domainService, domainID, deduplicate, rules, variants, activeFrom, activeUntil)
+			return
+		}
 
 		// TODO : Appeler le LinkService et la fonction CreateLink pour créer le lien court.
 		// os.Exit(1) si erreur
-		link, err := linkService.CreateLink(longURLFlag)
+		link, created, err := linkService.CreateLink(services.CreateLinkInput{
+			LongURL:         longURLFlag,
+			DomainID:        domainID,
+			Alias:           aliasFlag,
+			Owner:           ownerFlag,
+			Deduplicate:     deduplicate,
+			Password:        passwordFlag,
+			AlwaysPreview:   previewFlag,
+			Rules:           rules,
+			Variants:        variants,
+			QueryForwarding: forwardQueryFlag,
+			UTM:             utmFlags,
+			RedirectStatus:  statusFlag,
+			CacheControl:    cacheControlFlag,
+			Kind:            linkKind(),
+			MaxViews:        maxViewsFlag,
+			SelfDestruct:    selfDestructFlag,
+			ActiveFrom:      activeFrom,
+			ActiveUntil:     activeUntil,
+			InactiveURL:     inactiveURLFlag,
+			Title:           titleFlag,
+			Description:     descriptionFlag,
+			Tags:            tagsFlag,
+			WatchContent:    watchFlag,
+			WatchSelector:   watchSelectorFlag,
+		})
 		if err != nil {
-			fmt.Printf("Erreur : impossible de créer l'URL courte : %v\n", err)
+			if errors.Is(err, services.ErrInvalidURL) {
+				fmt.Printf("Erreur : URL refusée : %v\n", err)
+			} else if errors.Is(err, services.ErrInvalidPassword) {
+				fmt.Printf("Erreur : mot de passe refusé : %v\n", err)
+			} else if errors.Is(err, services.ErrInvalidRule) {
+				fmt.Printf("Erreur : règles de redirection refusées : %v\n", err)
+			} else if errors.Is(err, services.ErrInvalidVariant) {
+				fmt.Printf("Erreur : variantes A/B refusées : %v\n", err)
+			} else if errors.Is(err, services.ErrInvalidCampaign) {
+				fmt.Printf("Erreur : réglages de campagne refusés : %v\n", err)
+			} else if errors.Is(err, services.ErrInvalidRedirect) {
+				fmt.Printf("Erreur : réglages de redirection refusés : %v\n", err)
+			} else if errors.Is(err, services.ErrInvalidKind) {
+				fmt.Printf("Erreur : type de lien refusé : %v\n", err)
+			} else if errors.Is(err, services.ErrInvalidViewLimit) {
+				fmt.Printf("Erreur : limite de redirections refusée : %v\n", err)
+			} else if errors.Is(err, services.ErrInvalidSchedule) {
+				fmt.Printf("Erreur : fenêtre d'activité refusée : %v\n", err)
+			} else if errors.Is(err, services.ErrInvalidTag) || errors.Is(err, services.ErrInvalidTitle) {
+				fmt.Printf("Erreur : description du lien refusée : %v\n", err)
+			} else if errors.Is(err, services.ErrInvalidSelector) {
+				fmt.Printf("Erreur : sélecteur de contenu refusé : %v\n", err)
+			} else if errors.Is(err, services.ErrLinkQuotaExceeded) {
+				fmt.Printf("Erreur : quota de liens atteint : %v\n", err)
+			} else {
+				fmt.Printf("Erreur : impossible de créer l'URL courte : %v\n", err)
+			}
 			os.Exit(1)
 		}
 
-		fullShortURL := fmt.Sprintf("%s/%s", cfg.Server.BaseURL, link.ShortCode)
+		fullShortURL, err := domainService.ShortURL(link)
+		if err != nil {
+			fmt.Printf("Erreur : %v\n", err)
+			os.Exit(1)
+		}
+		if !created {
+			fmt.Printf("Cette URL est déjà raccourcie, lien existant:\n")
+			fmt.Printf("Code: %s\n", link.ShortCode)
+			fmt.Printf("URL complète: %s\n", fullShortURL)
+			return
+		}
 		fmt.Printf("URL courte créée avec succès:\n")
 		fmt.Printf("Code: %s\n", link.ShortCode)
 		fmt.Printf("URL complète: %s\n", fullShortURL)
 	},
 }
 
+// readRulesFile lit un tableau JSON de règles de redirection (aucune règle si path est vide).
+// Les champs inconnus sont refusés pour signaler les fautes de frappe.
+func readRulesFile(path string) ([]targeting.Rule, error) {
+	if path == "" {
+		return nil, nil
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var rules []targeting.Rule
+	decoder := json.NewDecoder(file)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&rules); err != nil {
+		return nil, fmt.Errorf("invalid JSON in %s: %w", path, err)
+	}
+	return rules, nil
+}
+
+// linkKind retourne le type des liens créés d'après le flag --prefix.
+func linkKind() string {
+	if prefixFlag {
+		return models.LinkKindPrefix
+	}
+	return models.LinkKindStandard
+}
+
+// parseScheduleFlag interprète une borne de fenêtre d'activité (aucune si value est vide).
+func parseScheduleFlag(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	bound, err := services.ParseScheduleTime(value)
+	if err != nil {
+		return nil, err
+	}
+	return &bound, nil
+}
+
+// readVariantsFile lit un tableau JSON de variantes A/B (aucune variante si path est vide).
+// Les champs inconnus sont refusés pour signaler les fautes de frappe.
+func readVariantsFile(path string) ([]experiment.Variant, error) {
+	if path == "" {
+		return nil, nil
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var variants []experiment.Variant
+	decoder := json.NewDecoder(file)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&variants); err != nil {
+		return nil, fmt.Errorf("invalid JSON in %s: %w", path, err)
+	}
+	return variants, nil
+}
+
+// createFromFile crée en un seul lot les liens listés dans le fichier --file et affiche un résultat par ligne.
+func createFromFile(linkService *services.LinkService, domainService *services.DomainService, domainID uint, deduplicate *bool, rules []targeting.Rule, variants []experiment.Variant, activeFrom, activeUntil *time.Time) {
+	file, err := os.Open(urlsFileFlag)
+	if err != nil {
+		fmt.Printf("Erreur : impossible d'ouvrir le fichier : %v\n", err)
+		os.Exit(1)
+	}
+	defer file.Close()
+
+	var inputs []services.CreateLinkInput
+	var lines []int
+	scanner := bufio.NewScanner(file)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		input := services.CreateLinkInput{LongURL: fields[0], DomainID: domainID, Owner: ownerFlag, Deduplicate: deduplicate, Password: passwordFlag, AlwaysPreview: previewFlag,
+			Rules: rules, Variants: variants, QueryForwarding: forwardQueryFlag, UTM: utmFlags,
+			RedirectStatus: statusFlag, CacheControl: cacheControlFlag, Kind: linkKind(),
+			MaxViews: maxViewsFlag, SelfDestruct: selfDestructFlag,
+			ActiveFrom: activeFrom, ActiveUntil: activeUntil, InactiveURL: inactiveURLFlag,
+			Title: titleFlag, Description: descriptionFlag, Tags: tagsFlag, WatchContent: watchFlag, WatchSelector: watchSelectorFlag}
+		if len(fields) > 1 {
+			input.Alias = fields[1]
+		}
+		inputs = append(inputs, input)
+		lines = append(lines, lineNumber)
+	}
+	if err := scanner.Err(); err != nil {
+		fmt.Printf("Erreur : impossible de lire le fichier : %v\n", err)
+		os.Exit(1)
+	}
+
+	results, err := linkService.CreateLinks(inputs)
+	if err != nil {
+		fmt.Printf("Erreur : impossible de créer les URLs courtes, aucun lien créé : %v\n", err)
+		os.Exit(1)
+	}
+
+	created, failed := 0, 0
+	for _, result := range results {
+		if result.Err != nil {
+			failed++
+			fmt.Printf("Ligne %d : erreur : %v\n", lines[result.Index], result.Err)
+			continue
+		}
+		status := "créé"
+		if result.Existing {
+			status = "existant"
+		} else {
+			created++
+		}
+		fullShortURL, err := domainService.ShortURL(result.Link)
+		if err != nil {
+			fullShortURL = result.Link.ShortCode
+		}
+		fmt.Printf("Ligne %d : %s -> %s (%s)\n", lines[result.Index], fullShortURL, result.Link.LongURL, status)
+	}
+	fmt.Printf("%d lien(s) traité(s), dont %d créé(s), %d erreur(s).\n", len(inputs)-failed, created, failed)
+}
+
 func init() {
 
 	// TODO : Définir le flag --url pour la commande create.
 	CreateCmd.Flags().StringVarP(&longURLFlag, "url", "u", "", "URL longue à raccourcir")
+	CreateCmd.Flags().StringVar(&ownerFlag, "owner", "", "Propriétaire du lien (optionnel)")
+	CreateCmd.Flags().StringVar(&aliasFlag, "alias", "", "Code court personnalisé (optionnel)")
+	CreateCmd.Flags().StringVar(&domainFlag, "domain", "", "Domaine personnalisé des liens créés (optionnel)")
+	CreateCmd.Flags().StringVar(&workspaceFlag, "workspace", "", "Espace de travail des liens créés (espace par défaut sinon)")
+	CreateCmd.Flags().BoolVar(&dedupeFlag, "dedupe", false, "Réutilise le lien existant si l'URL est déjà raccourcie (par défaut selon la configuration)")
+	CreateCmd.Flags().StringVar(&passwordFlag, "password", "", "Mot de passe demandé avant la redirection (optionnel)")
+	CreateCmd.Flags().BoolVar(&previewFlag, "preview", false, "Affiche toujours une page d'aperçu avant de rediriger (destinations peu fiables)")
+	CreateCmd.Flags().StringVar(&rulesFileFlag, "rules", "", "Fichier JSON de règles de redirection (OS, appareil, langue, pays, horaires), évaluées dans l'ordre")
+	CreateCmd.Flags().StringVar(&variantsFileFlag, "variants", "", "Fichier JSON de variantes A/B pondérées, servies à la place de l'URL longue")
+	CreateCmd.Flags().StringVar(&forwardQueryFlag, "forward-query", "", "Transmet les paramètres du lien court à la destination : merge (sans remplacer les siens) ou override")
+	CreateCmd.Flags().StringVar(&utmFlags.Source, "utm-source", "", "Valeur de utm_source ajoutée à la destination ({code}, {source}, {rule} et {variant} sont remplacés)")
+	CreateCmd.Flags().StringVar(&utmFlags.Medium, "utm-medium", "", "Valeur de utm_medium ajoutée à la destination")
+	CreateCmd.Flags().StringVar(&utmFlags.Campaign, "utm-campaign", "", "Valeur de utm_campaign ajoutée à la destination")
+	CreateCmd.Flags().IntVar(&statusFlag, "status", 0, "Code HTTP de la redirection : 301, 302 (par défaut), 307 ou 308")
+	CreateCmd.Flags().StringVar(&cacheControlFlag, "cache-control", "", "En-tête Cache-Control de la redirection (ex: \"no-store\" pour compter chaque visite)")
+	CreateCmd.Flags().BoolVar(&prefixFlag, "prefix", false, "Lien préfixe : /{code}/reste/du/chemin redirige vers l'URL suivie du reste du chemin")
+	CreateCmd.Flags().IntVar(&maxViewsFlag, "max-views", 0, "Nombre de redirections autorisées (1 pour un lien à usage unique), le lien répond 410 ensuite")
+	CreateCmd.Flags().BoolVar(&selfDestructFlag, "self-destruct", false, "Efface la destination après la dernière redirection autorisée (avec --max-views)")
+	CreateCmd.Flags().StringVar(&activeFromFlag, "active-from", "", "Date à partir de laquelle le lien redirige (ex: 2024-06-01 ou 2024-06-01T09:00:00+02:00)")
+	CreateCmd.Flags().StringVar(&activeUntilFlag, "active-until", "", "Date à partir de laquelle le lien ne redirige plus")
+	CreateCmd.Flags().StringVar(&inactiveURLFlag, "inactive-url", "", "Destination servie hors de la fenêtre d'activité, page d'indisponibilité sinon")
+	CreateCmd.Flags().StringVar(&titleFlag, "title", "", "Titre du lien, pour le retrouver (list --search)")
+	CreateCmd.Flags().StringVar(&descriptionFlag, "description", "", "Notes libres sur le lien")
+	CreateCmd.Flags().StringSliceVar(&tagsFlag, "tag", nil, "Étiquette du lien (répétable ou séparées par des virgules, ex: --tag=promo,newsletter)")
+	CreateCmd.Flags().BoolVar(&watchFlag, "watch", false, "Surveille le contenu de la destination et notifie ses changements significatifs (commande changes)")
+	CreateCmd.Flags().StringVar(&watchSelectorFlag, "watch-selector", "", "Sélecteur CSS du contenu surveillé (ex: \"main .fiche-produit\"), implique --watch")
+	CreateCmd.Flags().StringVar(&urlsFileFlag, "file", "", "Fichier contenant une URL par ligne, à raccourcir en lot")
 
-	// TODO :  Marquer le flag comme requis
-	CreateCmd.MarkFlagRequired("url")
+	// --url et --file sont mutuellement exclusifs : l'un des deux est vérifié dans Run
+	CreateCmd.MarkFlagsMutuallyExclusive("url", "file")
 
 	// TODO : Ajouter la commande à RootCmd
 	// RootCmd.AddCommand(CreateCmd)
@@ -0,0 +1,125 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+
+	cmd2 "github.com/axellelanca/urlshortener/cmd"
+	"github.com/axellelanca/urlshortener/internal/repository"
+	"github.com/axellelanca/urlshortener/internal/services"
+	"github.com/glebarez/sqlite"
+	"github.com/spf13/cobra"
+	"gorm.io/gorm"
+)
+
+// domainURLFlag stocke l'URL de base du domaine à ajouter (--url)
+var domainURLFlag string
+
+// domainWorkspaceFlag stocke l'espace de travail du domaine (--workspace) : propriétaire du domaine ajouté,
+// ou filtre de la liste des domaines
+var domainWorkspaceFlag string
+
+// DomainCmd regroupe les commandes de gestion des domaines courts personnalisés
+var DomainCmd = &cobra.Command{
+	Use:   "domain",
+	Short: "Gère les domaines courts personnalisés.",
+	Long: `Chaque domaine personnalisé sert ses propres liens, avec son propre espace de codes courts :
+le même code peut désigner deux liens différents sur deux domaines. Le domaine par défaut
+est celui de server.base_url, partagé par tous les espaces de travail ; un domaine personnalisé
+appartient à un espace de travail, seul à pouvoir y créer des liens.
+
+Le serveur doit recevoir les requêtes de ces domaines (DNS et proxy configurés en conséquence) :
+le lien est recherché d'après l'en-tête Host de la requête.`,
+}
+
+// DomainAddCmd représente la commande 'domain add'
+var DomainAddCmd = &cobra.Command{
+	Use:   "add",
+	Short: "Ajoute un domaine court personnalisé.",
+	Long: `Cette commande enregistre un nouveau domaine à partir de son URL de base,
+utilisée pour construire les URLs courtes complètes.
+
+Exemple:
+  url-shortener domain add --url="https://sho.rt" --workspace=marketing`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if domainURLFlag == "" {
+			fmt.Println("Erreur : le flag --url est obligatoire.")
+			os.Exit(1)
+		}
+
+		domainService, closeDB := openDomainService(domainWorkspaceFlag)
+		defer closeDB()
+
+		domain, err := domainService.AddDomain(domainURLFlag)
+		if err != nil {
+			if errors.Is(err, services.ErrInvalidDomain) || errors.Is(err, services.ErrDomainExists) {
+				fmt.Printf("Erreur : %v\n", err)
+			} else {
+				fmt.Printf("Erreur : impossible d'ajouter le domaine : %v\n", err)
+			}
+			os.Exit(1)
+		}
+
+		fmt.Printf("Domaine ajouté avec succès: %s (%s)\n", domain.Host, domain.BaseURL)
+	},
+}
+
+// DomainListCmd représente la commande 'domain list'
+var DomainListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Liste les domaines courts personnalisés.",
+	Run: func(cmd *cobra.Command, args []string) {
+		domainService, closeDB := openDomainService(domainWorkspaceFlag)
+		defer closeDB()
+
+		domains, err := domainService.ListDomains()
+		if err != nil {
+			fmt.Printf("Erreur : impossible de lister les domaines : %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("Domaine par défaut: %s\n", cmd2.Cfg.Server.BaseURL)
+		for _, domain := range domains {
+			fmt.Printf("%s (%s), ajouté le %s\n", domain.Host, domain.BaseURL, domain.CreatedAt.Format("2006-01-02"))
+		}
+	},
+}
+
+// openDomainService ouvre la base configurée et retourne un DomainService, restreint à l'espace de travail
+// workspaceName s'il est indiqué, ainsi que la fonction de fermeture de la connexion.
+func openDomainService(workspaceName string) (*services.DomainService, func()) {
+	cfg := cmd2.Cfg
+	if cfg == nil {
+		fmt.Println("Erreur : configuration introuvable.")
+		os.Exit(1)
+	}
+
+	db, err := gorm.Open(sqlite.Open(cfg.Database.Name), &gorm.Config{})
+	if err != nil {
+		log.Fatalf("FATAL : impossible d'ouvrir la base SQLite : %v", err)
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		log.Fatalf("FATAL: Échec de l'obtention de la base de données SQL sous-jacente: %v", err)
+	}
+
+	domainService := services.NewDomainService(repository.NewDomainRepository(db), cfg.Server.BaseURL)
+	if workspaceName != "" {
+		domainService = domainService.ForWorkspace(resolveWorkspace(db, workspaceName).ID)
+	}
+	return domainService, func() { sqlDB.Close() }
+}
+
+func init() {
+	DomainAddCmd.Flags().StringVarP(&domainURLFlag, "url", "u", "", "URL de base du domaine (ex: https://sho.rt)")
+	DomainAddCmd.Flags().StringVar(&domainWorkspaceFlag, "workspace", "", "Espace de travail propriétaire du domaine (espace par défaut sinon)")
+	DomainAddCmd.MarkFlagRequired("url")
+	DomainListCmd.Flags().StringVar(&domainWorkspaceFlag, "workspace", "", "Liste uniquement les domaines de cet espace de travail")
+
+	DomainCmd.AddCommand(DomainAddCmd)
+	DomainCmd.AddCommand(DomainListCmd)
+	cmd2.RootCmd.AddCommand(DomainCmd)
+}
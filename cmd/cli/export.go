@@ -0,0 +1,114 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"os"
+
+	cmd2 "github.com/axellelanca/urlshortener/cmd"
+	"github.com/axellelanca/urlshortener/internal/repository"
+	"github.com/axellelanca/urlshortener/internal/services"
+	"github.com/spf13/cobra"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+)
+
+// Flags de la commande export
+var (
+	exportFormatFlag     string
+	exportOutFlag        string
+	exportWithClicksFlag bool
+	exportOwnerFlag      string
+	exportFromFlag       string
+	exportToFlag         string
+)
+
+// ExportCmd représente la commande 'export'
+var ExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Exporte les liens (et optionnellement leurs clics) en CSV ou JSON lines.",
+	Long: `Cette commande exporte les liens en flux, sans charger toute la base en mémoire.
+Les liens peuvent être filtrés par propriétaire et par date de création (--from inclus, --to exclus).
+Sans --out, l'export est écrit sur la sortie standard.
+
+Exemples:
+  url-shortener export --format=csv --out="links.csv"
+  url-shortener export --format=ndjson --clicks --owner="marketing" --from="2024-01-01" --to="2024-02-01"`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !services.IsValidExportFormat(exportFormatFlag) {
+			fmt.Println("Erreur : le flag --format doit valoir 'csv', 'jsonl' ou 'ndjson'.")
+			os.Exit(1)
+		}
+
+		filter := repository.LinkFilter{Owner: exportOwnerFlag}
+		var err error
+		if exportFromFlag != "" {
+			if filter.CreatedFrom, err = services.ParseExportDate(exportFromFlag); err != nil {
+				fmt.Printf("Erreur : date --from invalide : %v\n", err)
+				os.Exit(1)
+			}
+		}
+		if exportToFlag != "" {
+			if filter.CreatedTo, err = services.ParseExportDate(exportToFlag); err != nil {
+				fmt.Printf("Erreur : date --to invalide : %v\n", err)
+				os.Exit(1)
+			}
+		}
+
+		cfg := cmd2.Cfg
+		if cfg == nil {
+			fmt.Println("Erreur : configuration introuvable.")
+			os.Exit(1)
+		}
+
+		db, err := gorm.Open(sqlite.Open(cfg.Database.Name), &gorm.Config{})
+		if err != nil {
+			log.Fatalf("FATAL : impossible d'ouvrir la base SQLite : %v", err)
+		}
+
+		sqlDB, err := db.DB()
+		if err != nil {
+			log.Fatalf("FATAL: Échec de l'obtention de la base de données SQL sous-jacente: %v", err)
+		}
+		defer sqlDB.Close()
+
+		exportService := services.NewExportService(repository.NewLinkRepository(db), repository.NewClickRepository(db))
+
+		out := os.Stdout
+		if exportOutFlag != "" {
+			out, err = os.Create(exportOutFlag)
+			if err != nil {
+				fmt.Printf("Erreur : impossible de créer le fichier : %v\n", err)
+				os.Exit(1)
+			}
+			defer out.Close()
+		}
+
+		writer := bufio.NewWriter(out)
+		err = exportService.Export(writer, services.ExportOptions{
+			Format:     exportFormatFlag,
+			WithClicks: exportWithClicksFlag,
+			Filter:     filter,
+		})
+		if err == nil {
+			err = writer.Flush()
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Erreur : l'export a échoué : %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	ExportCmd.Flags().StringVarP(&exportFormatFlag, "format", "f", services.ExportFormatCSV, "Format : csv, jsonl ou ndjson")
+	ExportCmd.Flags().StringVarP(&exportOutFlag, "out", "o", "", "Fichier de sortie (par défaut la sortie standard)")
+	ExportCmd.Flags().BoolVar(&exportWithClicksFlag, "clicks", false, "Inclut les clics de chaque lien")
+	ExportCmd.Flags().StringVar(&exportOwnerFlag, "owner", "", "Filtre sur le propriétaire des liens")
+	ExportCmd.Flags().StringVar(&exportFromFlag, "from", "", "Date de création minimale (incluse)")
+	ExportCmd.Flags().StringVar(&exportToFlag, "to", "", "Date de création maximale (exclue)")
+
+	cmd2.RootCmd.AddCommand(ExportCmd)
+}
@@ -0,0 +1,149 @@
+package cli
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	cmd2 "github.com/axellelanca/urlshortener/cmd"
+	"github.com/axellelanca/urlshortener/internal/repository"
+	"github.com/axellelanca/urlshortener/internal/services"
+	"github.com/spf13/cobra"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+)
+
+// importFormatFlag stocke le format du fichier à importer (--format)
+var importFormatFlag string
+
+// importFileFlag stocke le chemin du fichier à importer (--file)
+var importFileFlag string
+
+// importBatchSizeFlag stocke la taille des lots (--batch-size), 0 pour une seule transaction
+var importBatchSizeFlag int
+
+// importCheckpointFlag stocke le fichier de reprise utilisé en mode lots (--checkpoint)
+var importCheckpointFlag string
+
+// importWorkspaceFlag stocke l'espace de travail des liens importés (--workspace), espace par défaut sinon
+var importWorkspaceFlag string
+
+// ImportCmd représente la commande 'import'
+var ImportCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Importe des liens existants en conservant leurs codes courts.",
+	Long: `Cette commande recrée des liens provenant d'un autre raccourcisseur en conservant
+leurs codes courts, leurs dates de création et, si disponibles, leurs clics historiques.
+
+Formats supportés : csv (short_code,long_url,created_at,clicks), jsonl, bitly, yourls.
+
+Par défaut, tout l'import est réalisé dans une seule transaction. Avec --batch-size,
+chaque lot est validé séparément et la progression est enregistrée dans un fichier de reprise :
+relancer la même commande reprend l'import là où il s'était arrêté.
+
+Exemples:
+  url-shortener import --format=csv --file="links.csv"
+  url-shortener import --format=bitly --file="bitly_export.csv" --batch-size=500`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if importFileFlag == "" {
+			fmt.Println("Erreur : le flag --file est obligatoire.")
+			os.Exit(1)
+		}
+
+		cfg := cmd2.Cfg
+		if cfg == nil {
+			fmt.Println("Erreur : configuration introuvable.")
+			os.Exit(1)
+		}
+
+		file, err := os.Open(importFileFlag)
+		if err != nil {
+			fmt.Printf("Erreur : impossible d'ouvrir le fichier : %v\n", err)
+			os.Exit(1)
+		}
+		records, issues, err := services.ParseImportFile(file, importFormatFlag)
+		file.Close()
+		if err != nil {
+			fmt.Printf("Erreur : impossible de lire le fichier : %v\n", err)
+			os.Exit(1)
+		}
+
+		checkpoint := importCheckpointFlag
+		if checkpoint == "" {
+			checkpoint = importFileFlag + ".checkpoint"
+		}
+		skip := 0
+		if importBatchSizeFlag > 0 {
+			if content, err := os.ReadFile(checkpoint); err == nil {
+				skip, err = strconv.Atoi(strings.TrimSpace(string(content)))
+				if err != nil || skip < 0 || skip > len(records) {
+					fmt.Printf("Erreur : fichier de reprise %s invalide.\n", checkpoint)
+					os.Exit(1)
+				}
+				fmt.Printf("Reprise de l'import après %d enregistrement(s) déjà traité(s).\n", skip)
+			}
+		}
+
+		db, err := gorm.Open(sqlite.Open(cfg.Database.Name), &gorm.Config{})
+		if err != nil {
+			log.Fatalf("FATAL : impossible d'ouvrir la base SQLite : %v", err)
+		}
+
+		sqlDB, err := db.DB()
+		if err != nil {
+			log.Fatalf("FATAL: Échec de l'obtention de la base de données SQL sous-jacente: %v", err)
+		}
+		defer sqlDB.Close()
+
+		linkRepo := repository.NewLinkRepository(db)
+		linkServiceOptions, err := services.LinkServiceOptionsFromConfig(cfg)
+		if err != nil {
+			log.Fatalf("FATAL : Configuration de création des liens invalide : %v", err)
+		}
+		linkService := services.NewLinkService(linkRepo, linkServiceOptions).ForWorkspace(resolveWorkspace(db, importWorkspaceFlag))
+
+		var onBatch func(processed int) error
+		if importBatchSizeFlag > 0 {
+			onBatch = func(processed int) error {
+				return os.WriteFile(checkpoint, []byte(strconv.Itoa(skip+processed)), 0o644)
+			}
+		}
+
+		report, err := linkService.ImportLinks(records[skip:], importBatchSizeFlag, onBatch)
+		if report != nil {
+			issues = append(issues, report.Invalid...)
+			sort.Slice(issues, func(i, j int) bool { return issues[i].Line < issues[j].Line })
+			for _, issue := range report.Conflicts {
+				fmt.Printf("Conflit ligne %d : le code '%s' existe déjà.\n", issue.Line, issue.ShortCode)
+			}
+			for _, issue := range issues {
+				fmt.Printf("Ligne %d ignorée : %s\n", issue.Line, issue.Reason)
+			}
+		}
+		if err != nil {
+			fmt.Printf("Erreur : l'import a échoué : %v\n", err)
+			os.Exit(1)
+		}
+
+		if importBatchSizeFlag > 0 {
+			os.Remove(checkpoint)
+		}
+		fmt.Printf("Import terminé : %d lien(s) importé(s), %d conflit(s), %d ligne(s) invalide(s).\n",
+			report.Imported, len(report.Conflicts), len(issues))
+	},
+}
+
+func init() {
+	ImportCmd.Flags().StringVarP(&importFormatFlag, "format", "f", services.ImportFormatCSV, "Format du fichier : csv, jsonl, bitly ou yourls")
+	ImportCmd.Flags().StringVar(&importFileFlag, "file", "", "Fichier à importer")
+	ImportCmd.Flags().IntVar(&importBatchSizeFlag, "batch-size", 0, "Taille des lots (0 = une seule transaction)")
+	ImportCmd.Flags().StringVar(&importCheckpointFlag, "checkpoint", "", "Fichier de reprise (par défaut <file>.checkpoint)")
+	ImportCmd.Flags().StringVar(&importWorkspaceFlag, "workspace", "", "Espace de travail des liens importés (espace par défaut sinon)")
+	ImportCmd.MarkFlagRequired("file")
+
+	cmd2.RootCmd.AddCommand(ImportCmd)
+}
@@ -0,0 +1,117 @@
+package cli
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	cmd2 "github.com/axellelanca/urlshortener/cmd"
+	"github.com/axellelanca/urlshortener/internal/models"
+	"github.com/axellelanca/urlshortener/internal/repository"
+	"github.com/axellelanca/urlshortener/internal/services"
+	"github.com/glebarez/sqlite"
+	"github.com/spf13/cobra"
+	"gorm.io/gorm"
+)
+
+// listOwnerFlag restreint la liste aux liens d'un propriétaire (--owner)
+var listOwnerFlag string
+
+// listStatusFlag restreint la liste selon la fenêtre d'activité : scheduled, active ou ended (--status)
+var listStatusFlag string
+
+// listTagFlag restreint la liste aux liens portant une étiquette (--tag)
+var listTagFlag string
+
+// listSearchFlag stocke les mots recherchés dans le titre, la description, l'URL, le code et les étiquettes (--search)
+var listSearchFlag string
+
+// listLimitFlag stocke le nombre maximal de liens affichés (--limit)
+var listLimitFlag int
+
+// statusLabels traduit les états de fenêtre d'activité pour l'affichage.
+var statusLabels = map[string]string{
+	models.LinkStatusScheduled: "programmé",
+	models.LinkStatusActive:    "actif",
+	models.LinkStatusEnded:     "terminé",
+}
+
+// ListCmd représente la commande 'list'
+var ListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Liste les liens courts, du plus récent au plus ancien.",
+	Long: `Cette commande affiche les liens enregistrés, avec leur état vis-à-vis de leur fenêtre
+d'activité : programmé (pas encore ouvert), actif ou terminé.
+Avec --search, seuls les liens dont le titre, la description, l'URL, le code court ou les étiquettes
+contiennent tous les mots recherchés sont affichés, les plus pertinents d'abord.
+
+Exemples:
+  url-shortener list
+  url-shortener list --status=scheduled --owner="marketing"
+  url-shortener list --tag=newsletter --search="soldes hiver"`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if listStatusFlag != "" && !services.IsValidLinkStatus(listStatusFlag) {
+			fmt.Println("Erreur : --status doit valoir scheduled, active ou ended.")
+			os.Exit(1)
+		}
+		if listLimitFlag < 1 {
+			fmt.Println("Erreur : --limit doit être positif.")
+			os.Exit(1)
+		}
+
+		cfg := cmd2.Cfg
+		if cfg == nil {
+			fmt.Println("Erreur : configuration introuvable.")
+			os.Exit(1)
+		}
+
+		db, err := gorm.Open(sqlite.Open(cfg.Database.Name), &gorm.Config{})
+		if err != nil {
+			log.Fatalf("FATAL : impossible d'ouvrir la base SQLite : %v", err)
+		}
+		sqlDB, err := db.DB()
+		if err != nil {
+			log.Fatalf("FATAL: Échec de l'obtention de la base de données SQL sous-jacente: %v", err)
+		}
+		defer sqlDB.Close()
+
+		linkService := services.NewLinkService(repository.NewLinkRepository(db), services.LinkServiceOptions{})
+		filter := repository.LinkFilter{Owner: listOwnerFlag, Tag: strings.ToLower(listTagFlag), Status: listStatusFlag}
+		links, total, err := linkService.SearchLinks(listSearchFlag, filter, 0, listLimitFlag)
+		if err != nil {
+			fmt.Printf("Erreur : impossible de lister les liens : %v\n", err)
+			os.Exit(1)
+		}
+
+		now := time.Now()
+		for i := range links {
+			link := &links[i]
+			fmt.Printf("%s\t%s\t%s", link.ShortCode, statusLabels[services.LinkStatus(link, now)], link.LongURL)
+			if title := services.DisplayTitle(link); title != "" {
+				fmt.Printf("\t%q", title)
+			}
+			if len(link.Tags) > 0 {
+				fmt.Printf("\t[%s]", strings.Join(services.TagNames(link), ", "))
+			}
+			if link.ActiveFrom != nil {
+				fmt.Printf("\tà partir du %s", link.ActiveFrom.Local().Format("2006-01-02 15:04"))
+			}
+			if link.ActiveUntil != nil {
+				fmt.Printf("\tjusqu'au %s", link.ActiveUntil.Local().Format("2006-01-02 15:04"))
+			}
+			fmt.Println()
+		}
+		fmt.Printf("%d lien(s) affiché(s) sur %d.\n", len(links), total)
+	},
+}
+
+func init() {
+	ListCmd.Flags().StringVar(&listOwnerFlag, "owner", "", "Propriétaire des liens à lister (optionnel)")
+	ListCmd.Flags().StringVar(&listStatusFlag, "status", "", "Fenêtre d'activité : scheduled (programmés), active ou ended (terminés)")
+	ListCmd.Flags().StringVar(&listTagFlag, "tag", "", "Étiquette des liens à lister (optionnel)")
+	ListCmd.Flags().StringVar(&listSearchFlag, "search", "", "Mots recherchés (titre, description, URL, code court, étiquettes)")
+	ListCmd.Flags().IntVar(&listLimitFlag, "limit", 50, "Nombre maximal de liens affichés")
+	cmd2.RootCmd.AddCommand(ListCmd)
+}
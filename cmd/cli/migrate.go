@@ -7,6 +7,8 @@ import (
 
 	cmd2 "github.com/axellelanca/urlshortener/cmd"
 	"github.com/axellelanca/urlshortener/internal/models"
+	"github.com/axellelanca/urlshortener/internal/repository"
+	"github.com/axellelanca/urlshortener/internal/services"
 	"github.com/spf13/cobra"
 
 	// "gorm.io/driver/sqlite"
@@ -46,14 +48,45 @@ basées sur les modèles Go.`,
 
 		// TODO 3: Exécuter les migrations automatiques de GORM.
 		// Utilisez db.AutoMigrate() et passez-lui les pointeurs vers tous vos modèles.
-		err = db.AutoMigrate(
-			&models.Link{},
-			&models.Click{},
-		)
+		err = db.AutoMigrate(models.All...)
 		if err != nil {
 			log.Fatalf("Erreur lors des migrations GORM : %v", err)
 		}
 
+		// Depuis l'introduction des domaines, les codes courts sont uniques par domaine et non plus globalement :
+		// l'ancien index unique sur short_code seul est remplacé par idx_links_domain_short_code.
+		if db.Migrator().HasIndex(&models.Link{}, "idx_links_short_code") {
+			if err := db.Migrator().DropIndex(&models.Link{}, "idx_links_short_code"); err != nil {
+				log.Fatalf("Erreur lors de la suppression de l'ancien index des codes courts : %v", err)
+			}
+		}
+
+		// Depuis l'introduction des espaces de travail, les clés d'idempotence sont uniques par espace :
+		// l'ancien index unique sur key seul est remplacé par idx_idempotency_keys_workspace_key.
+		if db.Migrator().HasIndex(&models.IdempotencyKey{}, "idx_idempotency_keys_key") {
+			if err := db.Migrator().DropIndex(&models.IdempotencyKey{}, "idx_idempotency_keys_key"); err != nil {
+				log.Fatalf("Erreur lors de la suppression de l'ancien index des clés d'idempotence : %v", err)
+			}
+		}
+
+		// Les liens créés avant l'introduction de la déduplication n'ont pas encore d'empreinte d'URL.
+		linkService := services.NewLinkService(repository.NewLinkRepository(db), services.LinkServiceOptions{})
+		backfilled, err := linkService.BackfillURLHashes()
+		if err != nil {
+			log.Fatalf("Erreur lors du calcul des empreintes d'URL : %v", err)
+		}
+		if backfilled > 0 {
+			fmt.Printf("Empreinte de déduplication calculée pour %d lien(s) existant(s).\n", backfilled)
+		}
+
+		// Index de recherche plein texte des liens (SQLite) : sans lui, la recherche se rabat sur des comparaisons LIKE.
+		indexed, err := linkService.EnsureSearchIndex()
+		if err != nil {
+			log.Printf("Avertissement : index de recherche plein texte indisponible, recherche par comparaison simple : %v", err)
+		} else if indexed {
+			fmt.Println("Index de recherche plein texte des liens créé.")
+		}
+
 		// Pas touche au log
 		fmt.Println("Migrations de la base de données exécutées avec succès.")
 	},
@@ -0,0 +1,169 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	cmd2 "github.com/axellelanca/urlshortener/cmd"
+	"github.com/axellelanca/urlshortener/internal/qr"
+	"github.com/axellelanca/urlshortener/internal/repository"
+	"github.com/axellelanca/urlshortener/internal/services"
+	"github.com/glebarez/sqlite"
+	"github.com/spf13/cobra"
+	"gorm.io/gorm"
+)
+
+// Flags de la commande qr
+var (
+	qrCodeFlag       string // Code court du lien (--code)
+	qrOutFlag        string // Fichier de sortie (--out)
+	qrFormatFlag     string // png ou svg ; déduit de l'extension de --out par défaut
+	qrSizeFlag       int    // Taille de l'image en pixels
+	qrECCFlag        string // Niveau de correction d'erreur : L, M, Q ou H
+	qrMarginFlag     int    // Marge en modules (-1 : valeur configurée)
+	qrForegroundFlag string // Couleur des modules (rrggbb)
+	qrBackgroundFlag string // Couleur du fond (rrggbb)
+	qrLogoFlag       string // Image centrée sur le QR code
+	qrSourceFlag     string // Marqueur de source des scans
+	qrDomainFlag     string // Domaine personnalisé du lien
+)
+
+// QRCmd représente la commande 'qr'
+var QRCmd = &cobra.Command{
+	Use:   "qr",
+	Short: "Génère le QR code d'un lien court (PNG ou SVG).",
+	Long: `Cette commande génère le QR code de l'URL courte complète d'un lien et l'écrit dans un fichier.
+Le QR code est produit localement, sans appel réseau. Un marqueur de source (par défaut celui de
+qr.source_value) est ajouté à l'URL encodée pour distinguer les scans dans les statistiques.
+
+Exemples:
+  url-shortener qr --code="xyz123" --out=xyz123.png
+  url-shortener qr --code="xyz123" --out=affiche.svg --size=1024 --ecc=H --logo=logo.png --source=affiche`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if qrCodeFlag == "" || qrOutFlag == "" {
+			fmt.Println("Erreur : les flags --code et --out sont obligatoires.")
+			os.Exit(1)
+		}
+
+		format := qrFormatFlag
+		if format == "" {
+			format = strings.TrimPrefix(strings.ToLower(filepath.Ext(qrOutFlag)), ".")
+			if format != qr.FormatSVG {
+				format = qr.FormatPNG
+			}
+		}
+
+		req := services.QRRequest{
+			Render: qr.Options{
+				Format: format,
+				Size:   qrSizeFlag,
+				ECC:    strings.ToUpper(qrECCFlag),
+				Margin: qrMarginFlag,
+			},
+			Source: qrSourceFlag,
+		}
+		// Une marge explicitement nulle est représentée par une valeur négative (0 désigne la marge par défaut)
+		switch {
+		case qrMarginFlag == 0:
+			req.Render.Margin = -1
+		case qrMarginFlag < 0:
+			req.Render.Margin = 0
+		}
+
+		var err error
+		if req.Render.Foreground, err = qr.ParseColor(qrForegroundFlag); err != nil {
+			fmt.Printf("Erreur : %v\n", err)
+			os.Exit(1)
+		}
+		if req.Render.Background, err = qr.ParseColor(qrBackgroundFlag); err != nil {
+			fmt.Printf("Erreur : %v\n", err)
+			os.Exit(1)
+		}
+		if qrLogoFlag != "" {
+			if req.Render.Logo, err = qr.LoadLogo(qrLogoFlag); err != nil {
+				fmt.Printf("Erreur : %v\n", err)
+				os.Exit(1)
+			}
+		}
+
+		cfg := cmd2.Cfg
+		if cfg == nil {
+			fmt.Println("Erreur : configuration introuvable.")
+			os.Exit(1)
+		}
+
+		db, err := gorm.Open(sqlite.Open(cfg.Database.Name), &gorm.Config{})
+		if err != nil {
+			log.Fatalf("FATAL : impossible d'ouvrir la base SQLite : %v", err)
+		}
+
+		sqlDB, err := db.DB()
+		if err != nil {
+			log.Fatalf("FATAL: Échec de l'obtention de la base de données SQL sous-jacente: %v", err)
+		}
+		defer sqlDB.Close()
+
+		qrServiceOptions, err := services.QRServiceOptionsFromConfig(cfg)
+		if err != nil {
+			log.Fatalf("FATAL : Configuration des QR codes invalide : %v", err)
+		}
+		linkService := services.NewLinkService(repository.NewLinkRepository(db), services.LinkServiceOptions{})
+		domainService := services.NewDomainService(repository.NewDomainRepository(db), cfg.Server.BaseURL)
+		qrService := services.NewQRService(domainService, qrServiceOptions)
+
+		domainID, err := domainService.DomainIDForName(qrDomainFlag)
+		if err != nil {
+			fmt.Printf("Erreur : %v\n", err)
+			os.Exit(1)
+		}
+
+		link, err := linkService.GetLinkByShortCode(domainID, qrCodeFlag)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				fmt.Println("Erreur : code court introuvable.")
+			} else {
+				fmt.Printf("Erreur inattendue : %v\n", err)
+			}
+			os.Exit(1)
+		}
+
+		// Le QR code est produit en mémoire pour ne pas laisser de fichier partiel en cas d'erreur.
+		var image bytes.Buffer
+		if err := qrService.Render(&image, link, req); err != nil {
+			fmt.Printf("Erreur : impossible de générer le QR code : %v\n", err)
+			os.Exit(1)
+		}
+		if err := os.WriteFile(qrOutFlag, image.Bytes(), 0o644); err != nil {
+			fmt.Printf("Erreur : impossible d'écrire %s : %v\n", qrOutFlag, err)
+			os.Exit(1)
+		}
+
+		content, _ := qrService.Content(link, qrSourceFlag)
+		fmt.Printf("QR code (%s) écrit dans %s\n", format, qrOutFlag)
+		fmt.Printf("URL encodée: %s\n", content)
+	},
+}
+
+func init() {
+	QRCmd.Flags().StringVarP(&qrCodeFlag, "code", "c", "", "Code court du lien")
+	QRCmd.Flags().StringVarP(&qrOutFlag, "out", "o", "", "Fichier de sortie (.png ou .svg)")
+	QRCmd.Flags().StringVar(&qrFormatFlag, "format", "", "Format de sortie : png ou svg (déduit de l'extension par défaut)")
+	QRCmd.Flags().IntVar(&qrSizeFlag, "size", 0, "Taille de l'image en pixels (qr.size par défaut)")
+	QRCmd.Flags().StringVar(&qrECCFlag, "ecc", "", "Niveau de correction d'erreur : L, M, Q ou H (qr.ecc par défaut)")
+	QRCmd.Flags().IntVar(&qrMarginFlag, "margin", -1, "Marge en modules (qr.margin par défaut)")
+	QRCmd.Flags().StringVar(&qrForegroundFlag, "fg", "", "Couleur des modules, rrggbb (qr.foreground par défaut)")
+	QRCmd.Flags().StringVar(&qrBackgroundFlag, "bg", "", "Couleur du fond, rrggbb (qr.background par défaut)")
+	QRCmd.Flags().StringVar(&qrLogoFlag, "logo", "", "Image (PNG ou JPEG) à placer au centre du QR code")
+	QRCmd.Flags().StringVar(&qrSourceFlag, "source", "", "Marqueur de source des scans (qr.source_value par défaut)")
+	QRCmd.Flags().StringVar(&qrDomainFlag, "domain", "", "Domaine personnalisé du lien (optionnel)")
+
+	QRCmd.MarkFlagRequired("code")
+	QRCmd.MarkFlagRequired("out")
+
+	cmd2.RootCmd.AddCommand(QRCmd)
+}
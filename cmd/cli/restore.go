@@ -0,0 +1,125 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+
+	cmd2 "github.com/axellelanca/urlshortener/cmd"
+	"github.com/axellelanca/urlshortener/internal/repository"
+	"github.com/axellelanca/urlshortener/internal/services"
+	"github.com/spf13/cobra"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+)
+
+// restoreInFlag stocke le chemin du fichier de sauvegarde à restaurer (--in)
+var restoreInFlag string
+
+// restoreModeFlag stocke le mode de restauration (--mode)
+var restoreModeFlag string
+
+// restoreDryRunFlag indique qu'il faut seulement afficher ce qui changerait (--dry-run)
+var restoreDryRunFlag bool
+
+// RestoreCmd représente la commande 'restore'
+var RestoreCmd = &cobra.Command{
+	Use:   "restore",
+	Short: "Restaure la base de liens depuis un fichier de sauvegarde.",
+	Long: `Cette commande vérifie l'intégrité d'une sauvegarde (base SQLite ou dump JSON)
+puis l'applique à la base configurée.
+
+En mode 'merge' (par défaut), les lignes absentes sont ajoutées et les lignes existantes conservées :
+une ligne en conflit (même identifiant, même code court...) est ignorée, avec les lignes qui en dépendent
+(ex: les clics d'un lien ignoré).
+En mode 'replace', les tables sont vidées puis rechargées depuis la sauvegarde ; une table absente
+de la sauvegarde (ex: sauvegarde plus ancienne que la base) est conservée telle quelle.
+Avec --dry-run, rien n'est modifié : un résumé des changements est affiché.
+
+Exemples:
+  url-shortener restore --in="backup.db" --dry-run
+  url-shortener restore --in="backup.json" --mode=replace`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if restoreInFlag == "" {
+			fmt.Println("Erreur : le flag --in est obligatoire.")
+			os.Exit(1)
+		}
+		if restoreModeFlag != services.RestoreModeMerge && restoreModeFlag != services.RestoreModeReplace {
+			fmt.Println("Erreur : le flag --mode doit valoir 'merge' ou 'replace'.")
+			os.Exit(1)
+		}
+
+		cfg := cmd2.Cfg
+		if cfg == nil {
+			fmt.Println("Erreur : configuration introuvable.")
+			os.Exit(1)
+		}
+
+		data, format, err := services.LoadBackup(restoreInFlag)
+		if err != nil {
+			if errors.Is(err, services.ErrInvalidBackup) {
+				fmt.Printf("Erreur : la sauvegarde est corrompue ou illisible : %v\n", err)
+			} else {
+				fmt.Printf("Erreur inattendue : %v\n", err)
+			}
+			os.Exit(1)
+		}
+		fmt.Printf("Sauvegarde %s vérifiée (format %s).\n", restoreInFlag, format)
+
+		db, err := gorm.Open(sqlite.Open(cfg.Database.Name), &gorm.Config{})
+		if err != nil {
+			log.Fatalf("FATAL : impossible d'ouvrir la base SQLite : %v", err)
+		}
+
+		sqlDB, err := db.DB()
+		if err != nil {
+			log.Fatalf("FATAL: Échec de l'obtention de la base de données SQL sous-jacente: %v", err)
+		}
+		defer sqlDB.Close()
+
+		backupService := services.NewBackupService(repository.NewBackupRepository(db))
+
+		plans, err := backupService.PlanRestore(data, restoreModeFlag)
+		if err != nil {
+			fmt.Printf("Erreur : impossible d'analyser la sauvegarde : %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("Résumé de la restauration (mode %s) :\n", restoreModeFlag)
+		for _, plan := range plans {
+			if plan.Missing {
+				fmt.Printf("  %-10s absente de la sauvegarde, conservée (actuel=%d)\n", plan.Table, plan.CurrentRows)
+				continue
+			}
+			fmt.Printf("  %-10s sauvegarde=%d actuel=%d à insérer=%d ignorées=%d à supprimer=%d\n",
+				plan.Table, plan.BackupRows, plan.CurrentRows, plan.ToInsert, plan.ToSkip, plan.ToDelete)
+		}
+
+		if restoreDryRunFlag {
+			fmt.Println("Dry-run : aucune modification effectuée.")
+			return
+		}
+
+		inserted, err := backupService.Restore(data, restoreModeFlag)
+		if err != nil {
+			fmt.Printf("Erreur : la restauration a échoué, aucune modification appliquée : %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Println("Restauration effectuée avec succès :")
+		for _, plan := range plans {
+			fmt.Printf("  %-10s %d ligne(s) insérée(s)\n", plan.Table, inserted[plan.Table])
+		}
+	},
+}
+
+func init() {
+	RestoreCmd.Flags().StringVarP(&restoreInFlag, "in", "i", "", "Fichier de sauvegarde à restaurer")
+	RestoreCmd.Flags().StringVarP(&restoreModeFlag, "mode", "m", services.RestoreModeMerge, "Mode de restauration : merge ou replace")
+	RestoreCmd.Flags().BoolVar(&restoreDryRunFlag, "dry-run", false, "Affiche les changements sans modifier la base")
+	RestoreCmd.MarkFlagRequired("in")
+
+	cmd2.RootCmd.AddCommand(RestoreCmd)
+}
@@ -4,8 +4,13 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"sort"
+	"strings"
+	"time"
 
 	cmd2 "github.com/axellelanca/urlshortener/cmd"
+	"github.com/axellelanca/urlshortener/internal/experiment"
+	"github.com/axellelanca/urlshortener/internal/models"
 	"github.com/axellelanca/urlshortener/internal/repository"
 	"github.com/axellelanca/urlshortener/internal/services"
 	"github.com/spf13/cobra"
@@ -18,6 +23,9 @@ import (
 // TODO : variable shortCodeFlag qui stockera la valeur du flag --code
 var shortCodeFlag string
 
+// statsDomainFlag stocke le domaine personnalisé du lien (--domain), domaine par défaut sinon
+var statsDomainFlag string
+
 // StatsCmd représente la commande 'stats'
 var StatsCmd = &cobra.Command{
 	Use:   "stats",
@@ -25,8 +33,9 @@ var StatsCmd = &cobra.Command{
 	Long: `Cette commande permet de récupérer et d'afficher le nombre total de clics
 pour une URL courte spécifique en utilisant son code.
 
-Exemple:
-  url-shortener stats --code="xyz123"`,
+Exemples:
+  url-shortener stats --code="xyz123"
+  url-shortener stats --code="promo" --domain="sho.rt"`,
 	Run: func(cmd *cobra.Command, args []string) {
 
 		// TODO : Valider que le flag --code a été fourni.
@@ -58,14 +67,21 @@ Exemple:
 
 		// TODO : Initialiser les repositories et services nécessaires NewLinkRepository & NewLinkService
 		linkRepo := repository.NewLinkRepository(db)
-		linkService := services.NewLinkService(linkRepo)
+		linkService := services.NewLinkService(linkRepo, services.LinkServiceOptions{})
+		domainService := services.NewDomainService(repository.NewDomainRepository(db), cfg.Server.BaseURL)
+
+		domainID, err := domainService.DomainIDForName(statsDomainFlag)
+		if err != nil {
+			fmt.Printf("Erreur : %v\n", err)
+			os.Exit(1)
+		}
 
 		// TODO 5: Appeler GetLinkStats pour récupérer le lien et ses statistiques.
 		// Attention, la fonction retourne 3 valeurs
 		// Pour l'erreur, utilisez gorm.ErrRecordNotFound
 		// Si erreur, os.Exit(1)
 
-		link, totalClicks, err := linkService.GetLinkStats(shortCodeFlag)
+		link, totalClicks, err := linkService.GetLinkStats(domainID, shortCodeFlag)
 		if err != nil {
 			if err == gorm.ErrRecordNotFound {
 				fmt.Println("Erreur : code court introuvable.")
@@ -77,13 +93,125 @@ Exemple:
 
 		fmt.Printf("Statistiques pour le code court: %s\n", link.ShortCode)
 		fmt.Printf("URL longue: %s\n", link.LongURL)
+		if err := linkService.LoadTags(link); err != nil {
+			fmt.Printf("Erreur inattendue : %v\n", err)
+			os.Exit(1)
+		}
+		if link.Title != "" {
+			fmt.Printf("Titre: %s\n", link.Title)
+		}
+		if link.Description != "" {
+			fmt.Printf("Description: %s\n", link.Description)
+		}
+		if len(link.Tags) > 0 {
+			fmt.Printf("Étiquettes: %s\n", strings.Join(services.TagNames(link), ", "))
+		}
+		if link.PageFetchedAt != nil {
+			fmt.Printf("Page de destination (récupérée le %s):\n", link.PageFetchedAt.Local().Format("2006-01-02 15:04"))
+			if link.PageTitle != "" {
+				fmt.Printf("  Titre: %s\n", link.PageTitle)
+			}
+			if link.PageDescription != "" {
+				fmt.Printf("  Description: %s\n", link.PageDescription)
+			}
+			if link.PageImage != "" {
+				fmt.Printf("  Image: %s\n", link.PageImage)
+			}
+			if link.PageFavicon != "" {
+				fmt.Printf("  Icône: %s\n", link.PageFavicon)
+			}
+		}
+		if link.WatchSelector != "" {
+			fmt.Printf("Contenu surveillé: %q (voir la commande changes)\n", link.WatchSelector)
+		} else if link.WatchContent {
+			fmt.Printf("Contenu surveillé: page entière (voir la commande changes)\n")
+		}
 		fmt.Printf("Total de clics: %d\n", totalClicks)
+
+		breakdown, err := linkService.GetClickBreakdown(link.ID)
+		if err != nil {
+			fmt.Printf("Erreur inattendue : %v\n", err)
+			os.Exit(1)
+		}
+		printClickCounts("source", breakdown.BySource)
+		printClickCounts("règle", breakdown.ByRule)
+		printClickCounts("pays", breakdown.ByCountry)
+		if report := linkService.VariantReport(link, breakdown.ByVariant); report != nil {
+			printVariantReport(report)
+		}
+		if link.ActiveFrom != nil || link.ActiveUntil != nil {
+			fmt.Printf("Fenêtre d'activité: %s", statusLabels[services.LinkStatus(link, time.Now())])
+			if link.ActiveFrom != nil {
+				fmt.Printf(", à partir du %s", link.ActiveFrom.Local().Format("2006-01-02 15:04"))
+			}
+			if link.ActiveUntil != nil {
+				fmt.Printf(", jusqu'au %s", link.ActiveUntil.Local().Format("2006-01-02 15:04"))
+			}
+			fmt.Println()
+			if link.InactiveURL != "" {
+				fmt.Printf("URL hors fenêtre: %s\n", link.InactiveURL)
+			}
+		}
+		if services.ViewLimited(link) {
+			fmt.Printf("Usage limité: %d/%d redirection(s) consommée(s)", link.Views, link.MaxViews)
+			if link.SelfDestruct {
+				fmt.Printf(", destination effacée après la dernière")
+			}
+			fmt.Println()
+		}
+		if link.Kind == models.LinkKindPrefix {
+			fmt.Printf("Lien préfixe: les chemins sous /%s/ sont ajoutés à l'URL longue\n", link.ShortCode)
+		}
+		if link.CacheControl != "" {
+			fmt.Printf("Redirection: %d (Cache-Control: %s)\n", services.RedirectStatusOf(link), link.CacheControl)
+		} else {
+			fmt.Printf("Redirection: %d\n", services.RedirectStatusOf(link))
+		}
+		if utm := services.UTMTemplateOf(link); !utm.IsZero() {
+			fmt.Printf("Modèle UTM: source=%q medium=%q campaign=%q\n", utm.Source, utm.Medium, utm.Campaign)
+		}
+		if link.QueryForwarding != services.QueryForwardingNone {
+			fmt.Printf("Transmission des paramètres de requête: %s\n", link.QueryForwarding)
+		}
+		if link.PasswordHash != "" {
+			fmt.Printf("Lien protégé par mot de passe, tentatives erronées: %d\n", link.FailedPasswordAttempts)
+		}
 	},
 }
 
+// printClickCounts affiche une répartition des clics, triée par valeur.
+func printClickCounts(label string, counts map[string]int) {
+	values := make([]string, 0, len(counts))
+	for value := range counts {
+		values = append(values, value)
+	}
+	sort.Strings(values)
+	for _, value := range values {
+		fmt.Printf("  dont %s %s: %d\n", label, value, counts[value])
+	}
+}
+
+// printVariantReport affiche la répartition A/B d'un lien et l'indicateur de significativité de l'écart aux poids.
+func printVariantReport(report *experiment.Report) {
+	fmt.Println("Répartition A/B:")
+	for _, variant := range report.Variants {
+		fmt.Printf("  variante %s (poids %d, attendu %.1f%%): %d clic(s), %.1f%% -> %s\n", variant.Name, variant.Weight,
+			variant.ExpectedShare*100, variant.Clicks, variant.ObservedShare*100, variant.Target)
+	}
+	switch {
+	case !report.EnoughData:
+		fmt.Println("  Pas encore assez de clics pour évaluer la répartition.")
+	case report.Significant:
+		fmt.Printf("  Écart significatif avec les poids configurés (khi-deux %.2f, p = %.4f).\n", report.ChiSquare, report.PValue)
+	default:
+		fmt.Printf("  Répartition conforme aux poids configurés (khi-deux %.2f, p = %.4f).\n", report.ChiSquare, report.PValue)
+	}
+}
+
 func init() {
 	// TODO : Définir le flag --code pour la commande stats.
 	StatsCmd.Flags().StringVarP(&shortCodeFlag, "code", "c", "", "Code court dont vous voulez les statistiques")
+	StatsCmd.Flags().StringVar(&statsDomainFlag, "domain", "", "Domaine personnalisé du lien (optionnel)")
 
 	// TODO Marquer le flag comme requis
 	StatsCmd.MarkFlagRequired("code")
@@ -0,0 +1,258 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+
+	cmd2 "github.com/axellelanca/urlshortener/cmd"
+	"github.com/axellelanca/urlshortener/internal/models"
+	"github.com/axellelanca/urlshortener/internal/repository"
+	"github.com/axellelanca/urlshortener/internal/services"
+	"github.com/glebarez/sqlite"
+	"github.com/spf13/cobra"
+	"gorm.io/gorm"
+)
+
+// workspaceMaxLinksFlag et workspaceMaxClicksFlag stockent les quotas d'un espace de travail (--max-links, --max-clicks)
+var workspaceMaxLinksFlag, workspaceMaxClicksFlag int
+
+// workspaceKeyNameFlag stocke le libellé d'une nouvelle clé d'API (--name)
+var workspaceKeyNameFlag string
+
+// moveFromFlag et moveToFlag stockent les espaces de travail d'origine et de destination d'un déplacement (--from, --to)
+var moveFromFlag, moveToFlag string
+
+// moveAllFlag déplace tous les liens de l'espace de travail d'origine (--all)
+var moveAllFlag bool
+
+// WorkspaceCmd regroupe les commandes d'administration des espaces de travail
+var WorkspaceCmd = &cobra.Command{
+	Use:   "workspace",
+	Short: "Administre les espaces de travail (équipes) et leurs clés d'API.",
+	Long: `Chaque espace de travail a ses propres liens, clics, domaines et clés d'API : une requête de l'API
+faite avec la clé d'un espace ne voit que les liens de cet espace. Les requêtes sans clé, ainsi que les
+données antérieures aux espaces de travail, relèvent de l'espace par défaut ("default"), qui n'a pas de quota.
+
+Les codes courts du domaine par défaut restent partagés entre tous les espaces de travail.`,
+}
+
+// WorkspaceCreateCmd représente la commande 'workspace create'
+var WorkspaceCreateCmd = &cobra.Command{
+	Use:   "create <nom>",
+	Short: "Crée un espace de travail.",
+	Long: `Cette commande crée un espace de travail, avec des quotas optionnels (0 pour illimité) :
+--max-links limite le nombre de liens de l'espace, --max-clicks le nombre de clics par mois calendaire (UTC),
+au-delà duquel les liens de l'espace ne redirigent plus (HTTP 429) jusqu'au mois suivant.
+
+Exemple:
+  url-shortener workspace create marketing --max-links=1000 --max-clicks=100000`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		workspaceService, closeDB := openWorkspaceService()
+		defer closeDB()
+
+		workspace, err := workspaceService.CreateWorkspace(args[0], services.WorkspaceQuotas{
+			MaxLinks:          workspaceMaxLinksFlag,
+			MaxClicksPerMonth: workspaceMaxClicksFlag,
+		})
+		if err != nil {
+			if errors.Is(err, services.ErrInvalidWorkspace) || errors.Is(err, services.ErrWorkspaceExists) {
+				fmt.Printf("Erreur : %v\n", err)
+			} else {
+				fmt.Printf("Erreur : impossible de créer l'espace de travail : %v\n", err)
+			}
+			os.Exit(1)
+		}
+
+		fmt.Printf("Espace de travail créé avec succès: %s (%s)\n", workspace.Name, quotasLabel(workspace))
+		fmt.Printf("Créez une clé d'API avec : url-shortener workspace key %s\n", workspace.Name)
+	},
+}
+
+// WorkspaceListCmd représente la commande 'workspace list'
+var WorkspaceListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Liste les espaces de travail et leurs quotas.",
+	Run: func(cmd *cobra.Command, args []string) {
+		workspaceService, closeDB := openWorkspaceService()
+		defer closeDB()
+
+		workspaces, err := workspaceService.ListWorkspaces()
+		if err != nil {
+			fmt.Printf("Erreur : impossible de lister les espaces de travail : %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("%s (espace par défaut, sans quota)\n", models.DefaultWorkspaceName)
+		for i := range workspaces {
+			workspace := &workspaces[i]
+			fmt.Printf("%s (%s), créé le %s\n", workspace.Name, quotasLabel(workspace), workspace.CreatedAt.Format("2006-01-02"))
+		}
+	},
+}
+
+// WorkspaceQuotaCmd représente la commande 'workspace quota'
+var WorkspaceQuotaCmd = &cobra.Command{
+	Use:   "quota <nom>",
+	Short: "Modifie les quotas d'un espace de travail.",
+	Long: `Cette commande remplace les quotas d'un espace de travail (0 pour illimité).
+Les liens existants au-delà d'un quota de liens abaissé sont conservés.
+
+Exemple:
+  url-shortener workspace quota marketing --max-links=5000 --max-clicks=0`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		workspaceService, closeDB := openWorkspaceService()
+		defer closeDB()
+
+		workspace := getWorkspaceOrExit(workspaceService, args[0])
+		quotas := services.WorkspaceQuotas{MaxLinks: workspace.MaxLinks, MaxClicksPerMonth: workspace.MaxClicksPerMonth}
+		if cmd.Flags().Changed("max-links") {
+			quotas.MaxLinks = workspaceMaxLinksFlag
+		}
+		if cmd.Flags().Changed("max-clicks") {
+			quotas.MaxClicksPerMonth = workspaceMaxClicksFlag
+		}
+		if err := workspaceService.SetQuotas(workspace, quotas); err != nil {
+			fmt.Printf("Erreur : %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("Quotas de %s mis à jour (%s)\n", workspace.Name, quotasLabel(workspace))
+	},
+}
+
+// WorkspaceKeyCmd représente la commande 'workspace key'
+var WorkspaceKeyCmd = &cobra.Command{
+	Use:   "key <nom>",
+	Short: "Crée une clé d'API donnant accès à un espace de travail.",
+	Long: `Cette commande génère une clé d'API pour un espace de travail. La clé n'est affichée qu'une fois :
+seule son empreinte est enregistrée. Elle s'utilise avec l'en-tête "Authorization: Bearer <clé>"
+(ou "X-API-Key: <clé>").
+
+Exemple:
+  url-shortener workspace key marketing --name="intégration CRM"`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		workspaceService, closeDB := openWorkspaceService()
+		defer closeDB()
+
+		workspace := getWorkspaceOrExit(workspaceService, args[0])
+		key, err := workspaceService.CreateAPIKey(workspace, workspaceKeyNameFlag)
+		if err != nil {
+			fmt.Printf("Erreur : impossible de créer la clé d'API : %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("Clé d'API de %s (conservez-la, elle ne sera plus affichée):\n%s\n", workspace.Name, key)
+	},
+}
+
+// WorkspaceMoveCmd représente la commande 'workspace move'
+var WorkspaceMoveCmd = &cobra.Command{
+	Use:   "move [codes...]",
+	Short: "Déplace des liens, avec leurs clics, d'un espace de travail à un autre.",
+	Long: `Cette commande déplace les liens désignés par leurs codes courts (domaine par défaut), ou tous
+les liens de l'espace d'origine avec --all, vers l'espace de destination. Leurs clics les suivent.
+Le quota de liens de l'espace de destination s'applique ; les liens d'un domaine personnalisé restent
+dans l'espace de travail de leur domaine. Soit tous les liens sont déplacés, soit aucun.
+
+Exemples:
+  url-shortener workspace move --from=default --to=marketing promo24 soldes
+  url-shortener workspace move --from=marketing --to=ventes --all`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if moveAllFlag == (len(args) > 0) {
+			fmt.Println("Erreur : indiquez soit des codes courts, soit --all.")
+			os.Exit(1)
+		}
+
+		workspaceService, closeDB := openWorkspaceService()
+		defer closeDB()
+
+		moved, err := workspaceService.MoveLinks(services.MoveLinksInput{
+			From:       getWorkspaceOrExit(workspaceService, moveFromFlag),
+			To:         getWorkspaceOrExit(workspaceService, moveToFlag),
+			ShortCodes: args,
+			All:        moveAllFlag,
+		})
+		if err != nil {
+			fmt.Printf("Erreur : %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("%d lien(s) déplacé(s) de %s vers %s.\n", moved, moveFromFlag, moveToFlag)
+	},
+}
+
+// quotasLabel décrit les quotas d'un espace de travail pour l'affichage.
+func quotasLabel(workspace *models.Workspace) string {
+	limit := func(value int) string {
+		if value == 0 {
+			return "illimité"
+		}
+		return fmt.Sprint(value)
+	}
+	return fmt.Sprintf("liens: %s, clics/mois: %s", limit(workspace.MaxLinks), limit(workspace.MaxClicksPerMonth))
+}
+
+// getWorkspaceOrExit retourne l'espace de travail portant ce nom (l'espace par défaut si le nom est vide),
+// ou termine la commande s'il n'existe pas.
+func getWorkspaceOrExit(workspaceService *services.WorkspaceService, name string) *models.Workspace {
+	workspace, err := workspaceService.GetWorkspace(name)
+	if err != nil {
+		fmt.Printf("Erreur : %v\n", err)
+		os.Exit(1)
+	}
+	return workspace
+}
+
+// resolveWorkspace retourne l'espace de travail demandé par le flag --workspace d'une commande
+// (l'espace par défaut si le flag est vide), ou termine la commande s'il n'existe pas.
+func resolveWorkspace(db *gorm.DB, name string) *models.Workspace {
+	workspaceService := services.NewWorkspaceService(repository.NewWorkspaceRepository(db), repository.NewLinkRepository(db))
+	return getWorkspaceOrExit(workspaceService, name)
+}
+
+// openWorkspaceService ouvre la base configurée et retourne un WorkspaceService ainsi que la fonction de fermeture de la connexion.
+func openWorkspaceService() (*services.WorkspaceService, func()) {
+	cfg := cmd2.Cfg
+	if cfg == nil {
+		fmt.Println("Erreur : configuration introuvable.")
+		os.Exit(1)
+	}
+
+	db, err := gorm.Open(sqlite.Open(cfg.Database.Name), &gorm.Config{})
+	if err != nil {
+		log.Fatalf("FATAL : impossible d'ouvrir la base SQLite : %v", err)
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		log.Fatalf("FATAL: Échec de l'obtention de la base de données SQL sous-jacente: %v", err)
+	}
+
+	workspaceService := services.NewWorkspaceService(repository.NewWorkspaceRepository(db), repository.NewLinkRepository(db))
+	return workspaceService, func() { sqlDB.Close() }
+}
+
+func init() {
+	WorkspaceCreateCmd.Flags().IntVar(&workspaceMaxLinksFlag, "max-links", 0, "Nombre maximal de liens (0 pour illimité)")
+	WorkspaceCreateCmd.Flags().IntVar(&workspaceMaxClicksFlag, "max-clicks", 0, "Nombre maximal de clics par mois (0 pour illimité)")
+	WorkspaceQuotaCmd.Flags().IntVar(&workspaceMaxLinksFlag, "max-links", 0, "Nombre maximal de liens (0 pour illimité)")
+	WorkspaceQuotaCmd.Flags().IntVar(&workspaceMaxClicksFlag, "max-clicks", 0, "Nombre maximal de clics par mois (0 pour illimité)")
+	WorkspaceKeyCmd.Flags().StringVar(&workspaceKeyNameFlag, "name", "", "Libellé de la clé (optionnel)")
+	WorkspaceMoveCmd.Flags().StringVar(&moveFromFlag, "from", "", "Espace de travail d'origine (\"default\" pour l'espace par défaut)")
+	WorkspaceMoveCmd.Flags().StringVar(&moveToFlag, "to", "", "Espace de travail de destination (\"default\" pour l'espace par défaut)")
+	WorkspaceMoveCmd.Flags().BoolVar(&moveAllFlag, "all", false, "Déplace tous les liens de l'espace d'origine")
+	WorkspaceMoveCmd.MarkFlagRequired("from")
+	WorkspaceMoveCmd.MarkFlagRequired("to")
+
+	WorkspaceCmd.AddCommand(WorkspaceCreateCmd)
+	WorkspaceCmd.AddCommand(WorkspaceListCmd)
+	WorkspaceCmd.AddCommand(WorkspaceQuotaCmd)
+	WorkspaceCmd.AddCommand(WorkspaceKeyCmd)
+	WorkspaceCmd.AddCommand(WorkspaceMoveCmd)
+	cmd2.RootCmd.AddCommand(WorkspaceCmd)
+}
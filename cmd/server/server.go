@@ -51,13 +51,45 @@ puis lance le serveur HTTP.`,
 		// Créez des instances de GormLinkRepository et GormClickRepository.
 		linkRepo := repository.NewLinkRepository(db)
 		clickRepo := repository.NewClickRepository(db)
+		domainRepo := repository.NewDomainRepository(db)
+		workspaceRepo := repository.NewWorkspaceRepository(db)
 
 		// Laissez le log
 		log.Println("Repositories initialisés.")
 
 		// TODO : Initialiser les services métiers.
 		// Créez des instances de LinkService et ClickService, en leur passant les repositories nécessaires.
-		linkService := services.NewLinkService(linkRepo)
+		linkServiceOptions, err := services.LinkServiceOptionsFromConfig(cfg)
+		if err != nil {
+			log.Fatalf("Configuration de création des liens invalide : %v", err)
+		}
+		// Les métadonnées des destinations des liens (titre, OpenGraph, icône) sont récupérées en tâche de fond,
+		// sans jamais se connecter à une adresse privée : elles sont ensuite renvoyées par l'API.
+		if cfg.Monitor.PageInfo.Enabled {
+			pageInfoFetcher := monitor.NewPageInfoFetcher(linkRepo, monitor.NewPublicHTTPClient(),
+				int64(cfg.Monitor.PageInfo.MaxKB)<<10, time.Duration(cfg.Monitor.IntervalMinutes)*time.Minute)
+			linkServiceOptions.PageInfo = pageInfoFetcher
+			go pageInfoFetcher.Start()
+		}
+		// Les quotas de clics des espaces de travail sont vérifiés à chaque redirection.
+		linkServiceOptions.Workspaces = workspaceRepo
+		linkService := services.NewLinkService(linkRepo, linkServiceOptions)
+		workspaceService := services.NewWorkspaceService(workspaceRepo, linkRepo)
+		domainService := services.NewDomainService(domainRepo, cfg.Server.BaseURL)
+		if err := domainService.RegisterOwnHosts(linkServiceOptions.URLValidator); err != nil {
+			log.Fatalf("Erreur lors du chargement des domaines : %v", err)
+		}
+		exportService := services.NewExportService(linkRepo, clickRepo)
+		qrServiceOptions, err := services.QRServiceOptionsFromConfig(cfg)
+		if err != nil {
+			log.Fatalf("Configuration des QR codes invalide : %v", err)
+		}
+		qrService := services.NewQRService(domainService, qrServiceOptions)
+		passwordGateOptions, err := services.PasswordGateOptionsFromConfig(cfg)
+		if err != nil {
+			log.Fatalf("Configuration des liens protégés invalide : %v", err)
+		}
+		passwordGate := services.NewPasswordGate(linkRepo, passwordGateOptions)
 		// clickService := services.NewClickService(clickRepo)
 
 		// Laissez le log
@@ -67,7 +99,8 @@ puis lance le serveur HTTP.`,
 		// Le channel est bufferisé avec la taille configurée.
 		// Passez le channel et le clickRepo aux workers.
 		clickEvents := make(chan models.ClickEvent, cfg.Workers.Clicks.ChannelBufferSize)
-		workers.StartClickWorkers(cfg.Workers.Clicks.NumberOfWorkers, clickEvents, clickRepo)
+		api.ClickEventsChannel = clickEvents
+		workers.StartClickWorkers(cfg.Workers.Clicks.NumberOfWorkers, clickEvents, clickRepo, linkServiceOptions.GeoIP)
 
 		// TODO : Remplacer les XXX par les bonnes variables
 		log.Printf("Channel d'événements de clic initialisé avec un buffer de %d. %d worker(s) de clics démarré(s).",
@@ -83,10 +116,30 @@ puis lance le serveur HTTP.`,
 
 		log.Printf("Moniteur d'URLs démarré avec un intervalle de %v.", monitorInterval)
 
+		// Les liens existants sont re-vérifiés périodiquement contre les listes de blocage.
+		if !linkServiceOptions.Blocklist.Empty() {
+			rescanInterval := time.Duration(cfg.Screening.RescanIntervalMinutes) * time.Minute
+			scanner := monitor.NewBlocklistScanner(linkRepo, linkServiceOptions.Blocklist, rescanInterval, monitor.LogNotifier{})
+			go scanner.Start()
+		}
+
+		// Le contenu des destinations des liens surveillés (watch_content) est relevé à l'intervalle du moniteur,
+		// sans jamais se connecter à une adresse privée.
+		if cfg.Monitor.Content.Enabled {
+			contentWatcher := monitor.NewContentWatcher(linkRepo, monitor.NewPublicHTTPClient(), int64(cfg.Monitor.Content.MaxKB)<<10,
+				cfg.Monitor.Content.Threshold, monitorInterval, monitor.LogNotifier{})
+			go contentWatcher.Start()
+		}
+
 		// TODO : Configurer le routeur Gin et les handlers API.
 		// Passez les services nécessaires aux fonctions de configuration des routes.
 		router := gin.Default()
-		api.SetupRoutes(router, linkService, cfg.Workers.Clicks.ChannelBufferSize)
+		// Sans proxy de confiance, l'adresse du visiteur est celle de la connexion : un en-tête X-Forwarded-For
+		// envoyé par le client lui-même ne doit pas permettre de contourner la limite des mots de passe.
+		if err := router.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+			log.Fatalf("FATAL: server.trusted_proxies invalide : %v", err)
+		}
+		api.SetupRoutes(router, cfg, linkService, domainService, exportService, qrService, passwordGate, workspaceService, urlMonitor)
 
 		// Pas toucher au log
 		log.Println("Routes API configurées.")
@@ -1,13 +1,24 @@
 package api
 
 import (
+    "bytes"
     "errors"
+    "fmt"
     "log"
     "net/http"
+    "net/url"
+    "strconv"
+    "strings"
     "time"
 
+    "github.com/axellelanca/urlshortener/internal/config"
+    "github.com/axellelanca/urlshortener/internal/experiment"
     "github.com/axellelanca/urlshortener/internal/models"
+    "github.com/axellelanca/urlshortener/internal/monitor"
+    "github.com/axellelanca/urlshortener/internal/qr"
+    "github.com/axellelanca/urlshortener/internal/repository"
     "github.com/axellelanca/urlshortener/internal/services"
+    "github.com/axellelanca/urlshortener/internal/targeting"
     "github.com/gin-gonic/gin"
     "gorm.io/gorm"
 )
@@ -15,16 +26,31 @@ import (
 // ClickEventsChannel est un channel bufferisé pour l'envoi asynchrone des événements de clic
 var ClickEventsChannel chan models.ClickEvent
 
-// SetupRoutes configure toutes les routes de l'API Gin et initialise le channel avec la taille du buffer
-func SetupRoutes(router *gin.Engine, linkService *services.LinkService, clickChannelBuffer int) {
+// SetupRoutes configure toutes les routes de l'API Gin et initialise le channel avec la taille du buffer configurée
+// Les routes /api/v1 sont restreintes à l'espace de travail de la clé d'API de la requête ; les redirections
+// relèvent de tous les espaces de travail.
+func SetupRoutes(router *gin.Engine, cfg *config.Config, linkService *services.LinkService, domainService *services.DomainService, exportService *services.ExportService, qrService *services.QRService, passwordGate *services.PasswordGate, workspaceService *services.WorkspaceService, urlMonitor *monitor.UrlMonitor) {
     if ClickEventsChannel == nil {
-        ClickEventsChannel = make(chan models.ClickEvent, clickChannelBuffer)
+        ClickEventsChannel = make(chan models.ClickEvent, cfg.Workers.Clicks.ChannelBufferSize)
     }
 
+    router.Use(HTTPSMiddleware(cfg.Server.TrustedProxies))
     router.GET("/health", HealthCheckHandler)
-    router.POST("/api/v1/links", CreateShortLinkHandler(linkService))
-    router.GET("/api/v1/links/:shortCode/stats", GetLinkStatsHandler(linkService))
-    router.GET("/:shortCode", RedirectHandler(linkService))
+    scoped := WorkspaceServices{Links: linkService, Domains: domainService, Export: exportService}
+    v1 := router.Group("/api/v1", WorkspaceMiddleware(workspaceService, scoped, cfg.Workspaces.RequireAPIKey))
+    v1.POST("/links", CreateShortLinkHandler())
+    v1.GET("/links", ListLinksHandler())
+    v1.GET("/links/search", SearchLinksHandler())
+    v1.POST("/links/batch", CreateShortLinksBatchHandler(cfg.Server.MaxBatchSize))
+    v1.GET("/links/:shortCode/stats", GetLinkStatsHandler())
+    v1.GET("/links/:shortCode/qr", GetLinkQRHandler(qrService))
+    v1.GET("/links/:shortCode/content-history", GetLinkContentHistoryHandler())
+    v1.GET("/export", ExportHandler())
+    router.GET("/:shortCode", RedirectHandler(linkService, domainService, passwordGate, urlMonitor, cfg.QR.SourceParam))
+    router.POST("/:shortCode", RedirectHandler(linkService, domainService, passwordGate, urlMonitor, cfg.QR.SourceParam))
+    // Chemins sous un lien préfixe (/{shortCode}/reste/du/chemin)
+    router.GET("/:shortCode/*path", RedirectHandler(linkService, domainService, passwordGate, urlMonitor, cfg.QR.SourceParam))
+    router.POST("/:shortCode/*path", RedirectHandler(linkService, domainService, passwordGate, urlMonitor, cfg.QR.SourceParam))
 }
 
 // HealthCheckHandler retourne simplement {"status": "ok"}
@@ -34,12 +60,103 @@ func HealthCheckHandler(c *gin.Context) {
 
 // CreateLinkRequest est le JSON attendu lors de la création d'un lien
 type CreateLinkRequest struct {
-    LongURL string `json:"long_url" binding:"required,url"`
+    LongURL         string               `json:"long_url" binding:"required"` // Validée et normalisée par le LinkService
+    Domain          string               `json:"domain"`                      // Optionnel : domaine personnalisé (ex: "sho.rt"), domaine par défaut sinon
+    Alias           string               `json:"alias"`
+    Owner           string               `json:"owner" binding:"max=100"`
+    Metadata        map[string]string    `json:"metadata"`
+    Deduplicate     *bool                `json:"deduplicate"`      // Optionnel : remplace le réglage global de déduplication
+    Password        string               `json:"password"`         // Optionnel : mot de passe demandé avant la redirection
+    AlwaysPreview   bool                 `json:"always_preview"`   // Optionnel : affiche toujours la page d'aperçu avant de rediriger
+    Rules           []targeting.Rule     `json:"rules"`            // Optionnel : règles de redirection (OS, appareil, langue, pays, horaires) évaluées dans l'ordre
+    Variants        []experiment.Variant `json:"variants"`         // Optionnel : destinations A/B pondérées, servies à la place de l'URL longue
+    QueryForwarding string               `json:"query_forwarding"` // Optionnel : "merge" ou "override" transmet les paramètres du lien court à la destination
+    UTM             services.UTMTemplate `json:"utm"`              // Optionnel : paramètres utm_source, utm_medium et utm_campaign ajoutés à la destination
+    RedirectStatus  int                  `json:"redirect_status"`  // Optionnel : 301, 302 (par défaut), 307 ou 308
+    CacheControl    string               `json:"cache_control"`    // Optionnel : en-tête Cache-Control de la redirection (ex: "no-store", "public, max-age=86400")
+    Kind            string               `json:"kind"`             // Optionnel : "prefix" pour que /{shortCode}/reste redirige vers la destination suivie du reste
+    MaxViews        int                  `json:"max_views"`        // Optionnel : nombre de redirections autorisées (1 pour un lien à usage unique), 410 ensuite
+    SelfDestruct    bool                 `json:"self_destruct"`    // Optionnel : efface la destination après la dernière redirection (avec max_views)
+    ActiveFrom      *time.Time           `json:"active_from"`      // Optionnel : date RFC 3339 à partir de laquelle le lien redirige
+    ActiveUntil     *time.Time           `json:"active_until"`     // Optionnel : date RFC 3339 à partir de laquelle le lien ne redirige plus
+    InactiveURL     string               `json:"inactive_url"`     // Optionnel : destination servie hors de la fenêtre d'activité, page d'indisponibilité sinon
+    Title           string               `json:"title"`            // Optionnel : titre du lien, pour le retrouver
+    Description     string               `json:"description"`      // Optionnel : notes libres sur le lien
+    Tags            []string             `json:"tags"`             // Optionnel : étiquettes libres (ex: ["campagne-2024", "newsletter"])
+    WatchContent    bool                 `json:"watch_content"`    // Optionnel : notifie les changements significatifs du contenu de la destination
+    WatchSelector   string               `json:"watch_selector"`   // Optionnel : sélecteur CSS du contenu surveillé (ex: "main .fiche-produit")
 }
 
-// CreateShortLinkHandler crée un lien court et renvoie le résultat JSON
-func CreateShortLinkHandler(linkService *services.LinkService) gin.HandlerFunc {
+// toInput convertit la requête en paramètres de création pour le LinkService, en résolvant son domaine
+func (r CreateLinkRequest) toInput(domainService *services.DomainService) (services.CreateLinkInput, error) {
+    domainID, err := domainService.DomainIDForName(r.Domain)
+    if err != nil {
+        return services.CreateLinkInput{}, err
+    }
+    return services.CreateLinkInput{
+        LongURL:         r.LongURL,
+        DomainID:        domainID,
+        Alias:           r.Alias,
+        Owner:           r.Owner,
+        Metadata:        r.Metadata,
+        Deduplicate:     r.Deduplicate,
+        Password:        r.Password,
+        AlwaysPreview:   r.AlwaysPreview,
+        Rules:           r.Rules,
+        Variants:        r.Variants,
+        QueryForwarding: r.QueryForwarding,
+        UTM:             r.UTM,
+        RedirectStatus:  r.RedirectStatus,
+        CacheControl:    r.CacheControl,
+        Kind:            r.Kind,
+        MaxViews:        r.MaxViews,
+        SelfDestruct:    r.SelfDestruct,
+        ActiveFrom:      r.ActiveFrom,
+        ActiveUntil:     r.ActiveUntil,
+        InactiveURL:     r.InactiveURL,
+        Title:           r.Title,
+        Description:     r.Description,
+        Tags:            r.Tags,
+        WatchContent:    r.WatchContent,
+        WatchSelector:   r.WatchSelector,
+    }, nil
+}
+
+// BatchCreateLinksRequest est le JSON attendu lors de la création d'un lot de liens.
+// Les éléments ne sont pas validés par Gin : chaque élément invalide est signalé individuellement.
+type BatchCreateLinksRequest struct {
+    Links []CreateLinkRequest `json:"links" binding:"required,min=1"`
+}
+
+// createLinkErrorStatus associe une erreur de création de lien au code HTTP à renvoyer
+func createLinkErrorStatus(err error) int {
+    switch {
+    case errors.Is(err, services.ErrInvalidURL), errors.Is(err, services.ErrInvalidAlias), errors.Is(err, services.ErrUnknownDomain),
+        errors.Is(err, services.ErrInvalidPassword), errors.Is(err, services.ErrInvalidRule), errors.Is(err, services.ErrInvalidVariant),
+        errors.Is(err, services.ErrInvalidCampaign), errors.Is(err, services.ErrInvalidRedirect),
+        errors.Is(err, services.ErrInvalidKind), errors.Is(err, services.ErrInvalidViewLimit),
+        errors.Is(err, services.ErrInvalidSchedule), errors.Is(err, services.ErrInvalidTag), errors.Is(err, services.ErrInvalidTitle),
+        errors.Is(err, services.ErrInvalidSelector):
+        return http.StatusBadRequest
+    case errors.Is(err, services.ErrAliasTaken):
+        return http.StatusConflict
+    case errors.Is(err, services.ErrLinkQuotaExceeded):
+        return http.StatusForbidden
+    case errors.Is(err, services.ErrIdempotencyKeyReused), errors.Is(err, services.ErrBlockedDestination):
+        return http.StatusUnprocessableEntity
+    default:
+        return http.StatusInternalServerError
+    }
+}
+
+// CreateShortLinkHandler crée un lien court et renvoie le résultat JSON.
+// Avec l'en-tête Idempotency-Key, rejouer la requête renvoie le lien déjà créé (200) au lieu d'un doublon ;
+// il en va de même lorsque la déduplication retrouve un lien existant.
+func CreateShortLinkHandler() gin.HandlerFunc {
     return func(c *gin.Context) {
+        scope := workspaceServices(c)
+        linkService, domainService := scope.Links, scope.Domains
+
         var req CreateLinkRequest
         if err := c.ShouldBindJSON(&req); err != nil {
             c.JSON(http.StatusBadRequest, gin.H{
@@ -49,17 +166,30 @@ func CreateShortLinkHandler(linkService *services.LinkService) gin.HandlerFunc {
             return
         }
 
-        // Validation de la longueur de l'URL
-        if len(req.LongURL) > 2048 {
+        idempotencyKey := c.GetHeader("Idempotency-Key")
+        if len(idempotencyKey) > 255 {
             c.JSON(http.StatusBadRequest, gin.H{
-                "error":   "Invalid URL",
-                "message": "URL is too long (maximum 2048 characters)",
+                "error":   "Invalid request",
+                "message": "Idempotency-Key header is too long (maximum 255 characters)",
             })
             return
         }
 
-        link, err := linkService.CreateLink(req.LongURL)
+        var link *models.Link
+        var created bool
+        input, err := req.toInput(domainService)
+        if err == nil {
+            input.IdempotencyKey = idempotencyKey
+            link, created, err = linkService.CreateLink(input)
+        }
         if err != nil {
+            if status := createLinkErrorStatus(err); status != http.StatusInternalServerError {
+                c.JSON(status, gin.H{
+                    "error":   "Invalid link",
+                    "message": err.Error(),
+                })
+                return
+            }
             log.Printf("Error creating link: %v", err)
             c.JSON(http.StatusInternalServerError, gin.H{
                 "error":   "Internal server error",
@@ -68,19 +198,261 @@ func CreateShortLinkHandler(linkService *services.LinkService) gin.HandlerFunc {
             return
         }
 
-        c.JSON(http.StatusCreated, gin.H{
+        fullShortURL, err := domainService.ShortURL(link)
+        if err != nil {
+            log.Printf("Error building short URL for %s: %v", link.ShortCode, err)
+        }
+
+        status := http.StatusCreated
+        if !created {
+            status = http.StatusOK
+        }
+        c.JSON(status, gin.H{
             "short_code":     link.ShortCode,
             "long_url":       link.LongURL,
-            "full_short_url": "http://localhost:8080/" + link.ShortCode,
+            "owner":          link.Owner,
+            "full_short_url": fullShortURL,
             "created_at":     link.CreatedAt,
         })
     }
 }
 
-// RedirectHandler redirige vers l'URL longue et enregistre le clic de façon asynchrone
-func RedirectHandler(linkService *services.LinkService) gin.HandlerFunc {
+// CreateShortLinksBatchHandler crée un lot de liens courts dans une seule transaction
+// et renvoie un résultat par élément, dans l'ordre de la requête
+func CreateShortLinksBatchHandler(maxBatchSize int) gin.HandlerFunc {
     return func(c *gin.Context) {
-        shortCode := c.Param("shortCode")
+        scope := workspaceServices(c)
+        linkService, domainService := scope.Links, scope.Domains
+
+        var req BatchCreateLinksRequest
+        if err := c.ShouldBindJSON(&req); err != nil {
+            c.JSON(http.StatusBadRequest, gin.H{
+                "error":   "Invalid request",
+                "message": err.Error(),
+            })
+            return
+        }
+
+        if len(req.Links) > maxBatchSize {
+            c.JSON(http.StatusRequestEntityTooLarge, gin.H{
+                "error":   "Batch too large",
+                "message": fmt.Sprintf("A batch may contain at most %d links", maxBatchSize),
+            })
+            return
+        }
+
+        // Les éléments dont le domaine est inconnu sont écartés du lot et signalés à leur position
+        inputs := make([]services.CreateLinkInput, 0, len(req.Links))
+        positions := make([]int, 0, len(req.Links))
+        domainErrors := make(map[int]error)
+        for i, item := range req.Links {
+            input, err := item.toInput(domainService)
+            if err != nil {
+                domainErrors[i] = err
+                continue
+            }
+            inputs = append(inputs, input)
+            positions = append(positions, i)
+        }
+
+        batchResults, err := linkService.CreateLinks(inputs)
+        if err != nil {
+            log.Printf("Error creating link batch: %v", err)
+            c.JSON(http.StatusInternalServerError, gin.H{
+                "error":   "Internal server error",
+                "message": "Failed to create short links, no link was created",
+            })
+            return
+        }
+
+        results := make([]services.BatchLinkResult, len(req.Links))
+        for i, domainErr := range domainErrors {
+            results[i] = services.BatchLinkResult{Index: i, Err: domainErr}
+        }
+        for _, result := range batchResults {
+            result.Index = positions[result.Index]
+            results[result.Index] = result
+        }
+
+        created := 0
+        items := make([]gin.H, len(results))
+        for i, result := range results {
+            if result.Err != nil {
+                items[i] = gin.H{
+                    "index":  result.Index,
+                    "status": createLinkErrorStatus(result.Err),
+                    "error":  result.Err.Error(),
+                }
+                continue
+            }
+            status := http.StatusCreated
+            if result.Existing {
+                status = http.StatusOK
+            } else {
+                created++
+            }
+            fullShortURL, err := domainService.ShortURL(result.Link)
+            if err != nil {
+                log.Printf("Error building short URL for %s: %v", result.Link.ShortCode, err)
+            }
+            items[i] = gin.H{
+                "index":          result.Index,
+                "status":         status,
+                "short_code":     result.Link.ShortCode,
+                "long_url":       result.Link.LongURL,
+                "full_short_url": fullShortURL,
+            }
+        }
+
+        failed := 0
+        for _, result := range results {
+            if result.Err != nil {
+                failed++
+            }
+        }
+
+        c.JSON(http.StatusMultiStatus, gin.H{
+            "created": created,
+            "failed":  failed,
+            "results": items,
+        })
+    }
+}
+
+// Pagination de la liste des liens (GET /api/v1/links).
+const (
+    defaultListLimit = 50
+    maxListLimit     = 500
+    maxSearchLength  = 200 // Longueur maximale d'une recherche (GET /api/v1/links/search)
+)
+
+// ListLinksHandler renvoie une page des liens, du plus récent au plus ancien.
+// Paramètres : owner, tag, status=scheduled|active|ended (fenêtre d'activité), limit (1 à 500), offset
+func ListLinksHandler() gin.HandlerFunc {
+    return func(c *gin.Context) {
+        scope := workspaceServices(c)
+        linkService, domainService := scope.Links, scope.Domains
+
+        filter, limit, offset, ok := parseLinkPage(c)
+        if !ok {
+            return
+        }
+        links, total, err := linkService.ListLinks(filter, offset, limit)
+        respondLinkPage(c, domainService, links, total, limit, offset, err)
+    }
+}
+
+// SearchLinksHandler renvoie une page des liens dont le titre, la description, l'URL, le code court ou les étiquettes
+// contiennent tous les mots recherchés, les plus pertinents d'abord.
+// Paramètres : q (obligatoire), puis les mêmes filtres et la même pagination que ListLinksHandler
+func SearchLinksHandler() gin.HandlerFunc {
+    return func(c *gin.Context) {
+        scope := workspaceServices(c)
+        linkService, domainService := scope.Links, scope.Domains
+
+        query := strings.TrimSpace(c.Query("q"))
+        if query == "" || len(query) > maxSearchLength {
+            c.JSON(http.StatusBadRequest, gin.H{
+                "error":   "Invalid request",
+                "message": fmt.Sprintf("q must be between 1 and %d characters", maxSearchLength),
+            })
+            return
+        }
+        filter, limit, offset, ok := parseLinkPage(c)
+        if !ok {
+            return
+        }
+        links, total, err := linkService.SearchLinks(query, filter, offset, limit)
+        respondLinkPage(c, domainService, links, total, limit, offset, err)
+    }
+}
+
+// parseLinkPage lit les filtres et la pagination d'une liste de liens. En cas de paramètre invalide,
+// l'erreur est déjà envoyée au client et ok vaut false.
+func parseLinkPage(c *gin.Context) (filter repository.LinkFilter, limit, offset int, ok bool) {
+    filter = repository.LinkFilter{Owner: c.Query("owner"), Tag: strings.ToLower(c.Query("tag")), Status: c.Query("status")}
+    if filter.Status != "" && !services.IsValidLinkStatus(filter.Status) {
+        c.JSON(http.StatusBadRequest, gin.H{
+            "error":   "Invalid status",
+            "message": "Status must be one of scheduled, active, ended",
+        })
+        return filter, 0, 0, false
+    }
+    limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultListLimit)))
+    if err != nil || limit < 1 || limit > maxListLimit {
+        c.JSON(http.StatusBadRequest, gin.H{
+            "error":   "Invalid request",
+            "message": fmt.Sprintf("limit must be between 1 and %d", maxListLimit),
+        })
+        return filter, 0, 0, false
+    }
+    offset, err = strconv.Atoi(c.DefaultQuery("offset", "0"))
+    if err != nil || offset < 0 {
+        c.JSON(http.StatusBadRequest, gin.H{
+            "error":   "Invalid request",
+            "message": "offset must be a positive number",
+        })
+        return filter, 0, 0, false
+    }
+    return filter, limit, offset, true
+}
+
+// respondLinkPage envoie une page de liens (ou l'erreur survenue en la récupérant).
+func respondLinkPage(c *gin.Context, domainService *services.DomainService, links []models.Link, total int64, limit, offset int, err error) {
+    if err != nil {
+        log.Printf("Error listing links: %v", err)
+        c.JSON(http.StatusInternalServerError, gin.H{
+            "error":   "Internal server error",
+            "message": "Failed to list links",
+        })
+        return
+    }
+
+    now := time.Now()
+    items := make([]gin.H, 0, len(links))
+    for i := range links {
+        link := &links[i]
+        fullShortURL, err := domainService.ShortURL(link)
+        if err != nil {
+            log.Printf("Error building short URL for %s: %v", link.ShortCode, err)
+        }
+        items = append(items, gin.H{
+            "short_code":     link.ShortCode,
+            "long_url":       link.LongURL,
+            "owner":          link.Owner,
+            "full_short_url": fullShortURL,
+            "title":          services.DisplayTitle(link),
+            "favicon":        link.PageFavicon,
+            "tags":           services.TagNames(link),
+            "created_at":     link.CreatedAt,
+            "disabled":       link.Disabled,
+            "status":         services.LinkStatus(link, now),
+            "active_from":    link.ActiveFrom,
+            "active_until":   link.ActiveUntil,
+        })
+    }
+    c.JSON(http.StatusOK, gin.H{
+        "links":  items,
+        "total":  total,
+        "limit":  limit,
+        "offset": offset,
+    })
+}
+
+// RedirectHandler redirige vers l'URL longue et enregistre le clic de façon asynchrone.
+// Le code de redirection (302 par défaut) et l'en-tête Cache-Control sont ceux choisis pour le lien.
+// Pour un lien préfixe, le reste du chemin (/{shortCode}/reste) est ajouté à la destination.
+// Le lien est recherché dans le domaine désigné par l'en-tête Host de la requête.
+// Le paramètre sourceParam (ex: ?src=qr), s'il est présent, est enregistré comme source du clic.
+// Pour un lien protégé par mot de passe, un formulaire est servi à la place tant que le visiteur n'a pas
+// de cookie d'accès valide ; le formulaire est soumis en POST sur la même URL.
+// Avec le suffixe "+" (/{shortCode}+) ou ?preview=1, ou pour un lien AlwaysPreview, une page d'aperçu
+// est affichée à la place de la redirection, sans compter de clic.
+// Un lien A/B répartit ses visiteurs entre ses variantes selon leurs poids ; la variante attribuée est mémorisée
+// par un cookie et enregistrée sur le clic.
+func RedirectHandler(linkService *services.LinkService, domainService *services.DomainService, passwordGate *services.PasswordGate, urlMonitor *monitor.UrlMonitor, sourceParam string) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        shortCode, preview := previewRequested(c)
 
         // Validation du shortCode
         if len(shortCode) == 0 || len(shortCode) > 10 {
@@ -91,7 +463,17 @@ func RedirectHandler(linkService *services.LinkService) gin.HandlerFunc {
             return
         }
 
-        link, err := linkService.GetLinkByShortCode(shortCode)
+        domainID, err := domainService.DomainIDForHost(c.Request.Host)
+        if err != nil {
+            log.Printf("Error resolving domain %s: %v", c.Request.Host, err)
+            c.JSON(http.StatusInternalServerError, gin.H{
+                "error":   "Internal server error",
+                "message": "Failed to retrieve link",
+            })
+            return
+        }
+
+        link, err := linkService.GetLinkByShortCode(domainID, shortCode)
         if err != nil {
             if errors.Is(err, gorm.ErrRecordNotFound) {
                 c.JSON(http.StatusNotFound, gin.H{
@@ -108,11 +490,136 @@ func RedirectHandler(linkService *services.LinkService) gin.HandlerFunc {
             return
         }
 
+        // Un lien désactivé (ex: destination bloquée après sa création) ne redirige plus
+        if link.Disabled {
+            c.JSON(http.StatusGone, gin.H{
+                "error":   "Link disabled",
+                "message": "This short link has been disabled",
+            })
+            return
+        }
+
+        // En dehors de sa fenêtre d'activité, un lien programmé sert son URL de repli ou la page d'indisponibilité
+        if status := services.LinkStatus(link, time.Now()); status != models.LinkStatusActive {
+            serveInactiveLink(c, linkService, link, status)
+            return
+        }
+
+        // Un lien à usage limité dont toutes les redirections ont été consommées ne redirige plus
+        if services.ViewsExhausted(link) {
+            respondLinkUsedUp(c)
+            return
+        }
+
+        // Les liens d'un espace de travail ayant épuisé son quota de clics du mois ne redirigent plus
+        if err := linkService.CheckClickQuota(link, time.Now()); err != nil {
+            if errors.Is(err, services.ErrClickQuotaExceeded) {
+                nextPeriod := services.QuotaPeriodStart(time.Now()).AddDate(0, 1, 0)
+                c.Header("Retry-After", strconv.Itoa(int(time.Until(nextPeriod).Seconds())+1))
+                c.JSON(http.StatusTooManyRequests, gin.H{
+                    "error":   "Quota exceeded",
+                    "message": "This short link has reached its monthly click quota",
+                })
+                return
+            }
+            log.Printf("Error checking click quota for %s: %v", shortCode, err)
+            c.JSON(http.StatusInternalServerError, gin.H{
+                "error":   "Internal server error",
+                "message": "Failed to retrieve link",
+            })
+            return
+        }
+
+        // Un chemin après le code court n'est servi que par un lien préfixe ; une barre oblique finale seule
+        // (ex: /abc/) désigne le lien lui-même
+        remainder := c.Param("path")
+        if remainder == "/" && link.Kind != models.LinkKindPrefix {
+            remainder = ""
+        }
+        if remainder != "" && link.Kind != models.LinkKindPrefix {
+            c.JSON(http.StatusNotFound, gin.H{
+                "error":   "Link not found",
+                "message": "The requested short link does not exist",
+            })
+            return
+        }
+
+        if !checkLinkPassword(c, passwordGate, link) {
+            return
+        }
+
+        // La destination dépend des règles de redirection du lien, évaluées d'après les en-têtes du visiteur
+        visitor := targeting.NewRequest(c.Request.UserAgent(), c.GetHeader("Accept-Language"), time.Now())
+        target, rule := linkService.RedirectTarget(link, visitor, c.ClientIP())
+        if link.RedirectRules != "" {
+            c.Header("Vary", "User-Agent, Accept-Language")
+        }
+        // Sans règle applicable, un lien A/B sert la variante attribuée au visiteur
+        var variant string
+        if rule == "" {
+            if chosen, ok := chooseVariant(c, linkService, link); ok {
+                target, variant = chosen.Target, chosen.Name
+            }
+        }
+        monitored := target == link.LongURL
+        // Lien préfixe : le reste du chemin est ajouté à la destination retenue
+        if remainder != "" {
+            target, err = services.JoinPath(target, remainder)
+            if err != nil {
+                c.JSON(http.StatusBadRequest, gin.H{
+                    "error":   "Invalid path",
+                    "message": err.Error(),
+                })
+                return
+            }
+        }
+
+        var source string
+        if sourceParam != "" {
+            source = services.NormalizeClickSource(c.Query(sourceParam))
+        }
+        // Paramètres UTM du lien et paramètres transmis depuis le lien court
+        destination := linkService.RedirectURL(link, target, forwardedQuery(c, sourceParam), services.RedirectContext{
+            Source:  source,
+            Rule:    rule,
+            Variant: variant,
+        })
+
+        // Les robots (aperçus de messagerie, antivirus...) ne consomment pas les redirections d'un lien à usage
+        // limité : ils reçoivent la page d'aperçu, qui ne révèle pas la destination
+        limited := services.ViewLimited(link)
+        if preview || (link.AlwaysPreview && c.Query(confirmParam) != "1") || (limited && visitor.Device == targeting.DeviceBot) {
+            renderPreviewPage(c, link, destination, monitored, urlMonitor)
+            return
+        }
+
+        // La redirection est consommée en base avant d'être servie : une requête concurrente qui arrive
+        // après la dernière redirection reçoit 410
+        if limited {
+            consumed, err := linkService.ConsumeView(link)
+            if err != nil {
+                log.Printf("Error consuming view for %s: %v", shortCode, err)
+                c.JSON(http.StatusInternalServerError, gin.H{
+                    "error":   "Internal server error",
+                    "message": "Failed to retrieve link",
+                })
+                return
+            }
+            if !consumed {
+                respondLinkUsedUp(c)
+                return
+            }
+        }
+
         clickEvent := models.ClickEvent{
-            LinkID:    link.ID,
-            Timestamp: time.Now(),
-            IPAddress:        c.ClientIP(),
-            UserAgent: c.Request.UserAgent(),
+            LinkID:      link.ID,
+            WorkspaceID: link.WorkspaceID,
+            Timestamp:   time.Now(),
+            IPAddress:   c.ClientIP(),
+            UserAgent:   c.Request.UserAgent(),
+            Source:      source,
+            Rule:        rule,
+            Variant:     variant,
         }
 
         select {
@@ -122,13 +629,44 @@ func RedirectHandler(linkService *services.LinkService) gin.HandlerFunc {
             log.Printf("Warning: ClickEventsChannel is full, dropping click event for %s.", shortCode)
         }
 
-        c.Redirect(http.StatusFound, link.LongURL)
+        if limited {
+            // Une redirection consommée ne doit pas être rejouée depuis un cache
+            c.Header("Cache-Control", "no-store")
+        } else if link.CacheControl != "" {
+            c.Header("Cache-Control", link.CacheControl)
+        }
+        c.Redirect(services.RedirectStatusOf(link), destination)
     }
 }
 
-// GetLinkStatsHandler renvoie les statistiques (nombre total de clics) pour un lien donné
-func GetLinkStatsHandler(linkService *services.LinkService) gin.HandlerFunc {
+// respondLinkUsedUp répond 410 pour un lien à usage limité dont toutes les redirections ont été consommées.
+func respondLinkUsedUp(c *gin.Context) {
+    c.Header("Cache-Control", "no-store")
+    c.JSON(http.StatusGone, gin.H{
+        "error":   "Link used up",
+        "message": "This short link has already been used",
+    })
+}
+
+// forwardedQuery retourne les paramètres de la requête du lien court qui peuvent être transmis à la destination,
+// sans ceux destinés au raccourcisseur lui-même (aperçu, confirmation, marqueur de source).
+func forwardedQuery(c *gin.Context, sourceParam string) url.Values {
+    query := c.Request.URL.Query()
+    query.Del(previewParam)
+    query.Del(confirmParam)
+    if sourceParam != "" {
+        query.Del(sourceParam)
+    }
+    return query
+}
+
+// GetLinkStatsHandler renvoie les statistiques (nombre total de clics) pour un lien donné.
+// Le paramètre optionnel domain désigne le domaine personnalisé du lien.
+func GetLinkStatsHandler() gin.HandlerFunc {
     return func(c *gin.Context) {
+        scope := workspaceServices(c)
+        linkService, domainService := scope.Links, scope.Domains
+
         shortCode := c.Param("shortCode")
 
         // Validation du shortCode
@@ -140,7 +678,24 @@ func GetLinkStatsHandler(linkService *services.LinkService) gin.HandlerFunc {
             return
         }
 
-        link, totalClicks, err := linkService.GetLinkStats(shortCode)
+        domainID, err := domainService.DomainIDForName(c.Query("domain"))
+        if err != nil {
+            if errors.Is(err, services.ErrUnknownDomain) {
+                c.JSON(http.StatusNotFound, gin.H{
+                    "error":   "Domain not found",
+                    "message": err.Error(),
+                })
+                return
+            }
+            log.Printf("Error resolving domain %s: %v", c.Query("domain"), err)
+            c.JSON(http.StatusInternalServerError, gin.H{
+                "error":   "Internal server error",
+                "message": "Failed to retrieve statistics",
+            })
+            return
+        }
+
+        link, totalClicks, err := linkService.GetLinkStats(domainID, shortCode)
         if err != nil {
             if errors.Is(err, gorm.ErrRecordNotFound) {
                 c.JSON(http.StatusNotFound, gin.H{
@@ -157,11 +712,316 @@ func GetLinkStatsHandler(linkService *services.LinkService) gin.HandlerFunc {
             return
         }
 
+        breakdown, err := linkService.GetClickBreakdown(link.ID)
+        if err != nil {
+            log.Printf("Error retrieving click breakdown for %s: %v", shortCode, err)
+            c.JSON(http.StatusInternalServerError, gin.H{
+                "error":   "Internal server error",
+                "message": "Failed to retrieve statistics",
+            })
+            return
+        }
+        if err := linkService.LoadTags(link); err != nil {
+            log.Printf("Error retrieving tags for %s: %v", shortCode, err)
+            c.JSON(http.StatusInternalServerError, gin.H{
+                "error":   "Internal server error",
+                "message": "Failed to retrieve statistics",
+            })
+            return
+        }
+
         c.JSON(http.StatusOK, gin.H{
-            "short_code":   link.ShortCode,
-            "long_url":    link.LongURL,
-            "total_clicks": totalClicks,
-            "created_at":   link.CreatedAt,
+            "short_code":               link.ShortCode,
+            "long_url":                 link.LongURL,
+            "total_clicks":             totalClicks,
+            "clicks_by_source":         breakdown.BySource,
+            "clicks_by_rule":           breakdown.ByRule,
+            "clicks_by_country":        breakdown.ByCountry,
+            "created_at":               link.CreatedAt,
+            "disabled":                 link.Disabled,
+            "password_protected":       link.PasswordHash != "",
+            "failed_password_attempts": link.FailedPasswordAttempts,
+            "always_preview":           link.AlwaysPreview,
+            "split_test":               linkService.VariantReport(link, breakdown.ByVariant),
+            "query_forwarding":         link.QueryForwarding,
+            "utm":                      services.UTMTemplateOf(link),
+            "redirect_status":          services.RedirectStatusOf(link),
+            "cache_control":            link.CacheControl,
+            "kind":                     link.Kind,
+            "max_views":                link.MaxViews,
+            "views":                    link.Views,
+            "remaining_views":          services.RemainingViews(link),
+            "self_destruct":            link.SelfDestruct,
+            "status":                   services.LinkStatus(link, time.Now()),
+            "active_from":              link.ActiveFrom,
+            "active_until":             link.ActiveUntil,
+            "inactive_url":             link.InactiveURL,
+            "title":                    link.Title,
+            "description":              link.Description,
+            "tags":                     services.TagNames(link),
+            "page": gin.H{
+                "title":       link.PageTitle,
+                "description": link.PageDescription,
+                "image":       link.PageImage,
+                "favicon":     link.PageFavicon,
+                "fetched_at":  link.PageFetchedAt,
+            },
+            "watch_content":  link.WatchContent,
+            "watch_selector": link.WatchSelector,
         })
     }
 }
+
+// GetLinkContentHistoryHandler renvoie l'historique des changements de contenu de la destination d'un lien surveillé
+// (watch_content), du plus récent au plus ancien ; le plus ancien est l'empreinte de référence.
+// Paramètres : limit (1 à 100, 20 par défaut), domain (domaine personnalisé du lien)
+func GetLinkContentHistoryHandler() gin.HandlerFunc {
+    return func(c *gin.Context) {
+        scope := workspaceServices(c)
+        linkService, domainService := scope.Links, scope.Domains
+
+        shortCode := c.Param("shortCode")
+
+        // Validation du shortCode
+        if len(shortCode) == 0 || len(shortCode) > 10 {
+            c.JSON(http.StatusBadRequest, gin.H{
+                "error":   "Invalid short code",
+                "message": "Short code must be between 1 and 10 characters",
+            })
+            return
+        }
+
+        limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(services.DefaultContentHistoryLimit)))
+        if err != nil || limit < 1 || limit > services.MaxContentHistoryLimit {
+            c.JSON(http.StatusBadRequest, gin.H{
+                "error":   "Invalid request",
+                "message": fmt.Sprintf("limit must be between 1 and %d", services.MaxContentHistoryLimit),
+            })
+            return
+        }
+
+        domainID, err := domainService.DomainIDForName(c.Query("domain"))
+        if err != nil {
+            if errors.Is(err, services.ErrUnknownDomain) {
+                c.JSON(http.StatusNotFound, gin.H{
+                    "error":   "Domain not found",
+                    "message": err.Error(),
+                })
+                return
+            }
+            log.Printf("Error resolving domain %s: %v", c.Query("domain"), err)
+            c.JSON(http.StatusInternalServerError, gin.H{
+                "error":   "Internal server error",
+                "message": "Failed to retrieve content history",
+            })
+            return
+        }
+
+        link, err := linkService.GetLinkByShortCode(domainID, shortCode)
+        if err != nil {
+            if errors.Is(err, gorm.ErrRecordNotFound) {
+                c.JSON(http.StatusNotFound, gin.H{
+                    "error":   "Link not found",
+                    "message": "The requested short link does not exist",
+                })
+                return
+            }
+            log.Printf("Error retrieving link for %s: %v", shortCode, err)
+            c.JSON(http.StatusInternalServerError, gin.H{
+                "error":   "Internal server error",
+                "message": "Failed to retrieve content history",
+            })
+            return
+        }
+
+        snapshots, err := linkService.ContentHistory(link, limit)
+        if err != nil {
+            log.Printf("Error retrieving content history for %s: %v", shortCode, err)
+            c.JSON(http.StatusInternalServerError, gin.H{
+                "error":   "Internal server error",
+                "message": "Failed to retrieve content history",
+            })
+            return
+        }
+
+        changes := make([]gin.H, 0, len(snapshots))
+        for _, snapshot := range snapshots {
+            changes = append(changes, gin.H{
+                "recorded_at": snapshot.CreatedAt,
+                "hash":        snapshot.Hash,
+                "simhash":     snapshot.SimHash,
+                "words":       snapshot.Words,
+                "distance":    snapshot.Distance,
+                "significant": snapshot.Significant,
+            })
+        }
+        c.JSON(http.StatusOK, gin.H{
+            "short_code":     link.ShortCode,
+            "watch_content":  link.WatchContent,
+            "watch_selector": link.WatchSelector,
+            "changes":        changes,
+        })
+    }
+}
+
+// GetLinkQRHandler renvoie le QR code (PNG ou SVG) de l'URL courte complète d'un lien.
+// Paramètres : format=png|svg, size (pixels), ecc=L|M|Q|H, margin (modules), fg et bg (rrggbb),
+// logo=true (logo configuré), source (marqueur de source des scans), domain (domaine personnalisé du lien)
+func GetLinkQRHandler(qrService *services.QRService) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        scope := workspaceServices(c)
+        linkService, domainService := scope.Links, scope.Domains
+
+        shortCode := c.Param("shortCode")
+
+        // Validation du shortCode
+        if len(shortCode) == 0 || len(shortCode) > 10 {
+            c.JSON(http.StatusBadRequest, gin.H{
+                "error":   "Invalid short code",
+                "message": "Short code must be between 1 and 10 characters",
+            })
+            return
+        }
+
+        req, err := parseQRRequest(c)
+        if err != nil {
+            c.JSON(http.StatusBadRequest, gin.H{
+                "error":   "Invalid QR code options",
+                "message": err.Error(),
+            })
+            return
+        }
+
+        domainID, err := domainService.DomainIDForName(c.Query("domain"))
+        if err != nil {
+            if errors.Is(err, services.ErrUnknownDomain) {
+                c.JSON(http.StatusNotFound, gin.H{
+                    "error":   "Domain not found",
+                    "message": err.Error(),
+                })
+                return
+            }
+            log.Printf("Error resolving domain %s: %v", c.Query("domain"), err)
+            c.JSON(http.StatusInternalServerError, gin.H{
+                "error":   "Internal server error",
+                "message": "Failed to generate QR code",
+            })
+            return
+        }
+
+        link, err := linkService.GetLinkByShortCode(domainID, shortCode)
+        if err != nil {
+            if errors.Is(err, gorm.ErrRecordNotFound) {
+                c.JSON(http.StatusNotFound, gin.H{
+                    "error":   "Link not found",
+                    "message": "The requested short link does not exist",
+                })
+                return
+            }
+            log.Printf("Error retrieving link for %s: %v", shortCode, err)
+            c.JSON(http.StatusInternalServerError, gin.H{
+                "error":   "Internal server error",
+                "message": "Failed to generate QR code",
+            })
+            return
+        }
+
+        // L'image est produite en mémoire avant d'être envoyée, pour pouvoir encore renvoyer une erreur JSON.
+        var image bytes.Buffer
+        if err := qrService.Render(&image, link, req); err != nil {
+            if errors.Is(err, services.ErrInvalidQROptions) {
+                c.JSON(http.StatusBadRequest, gin.H{
+                    "error":   "Invalid QR code options",
+                    "message": err.Error(),
+                })
+                return
+            }
+            log.Printf("Error generating QR code for %s: %v", shortCode, err)
+            c.JSON(http.StatusInternalServerError, gin.H{
+                "error":   "Internal server error",
+                "message": "Failed to generate QR code",
+            })
+            return
+        }
+
+        c.Data(http.StatusOK, qr.ContentType(req.Render.Format), image.Bytes())
+    }
+}
+
+// parseQRRequest lit les options de QR code des paramètres de requête
+func parseQRRequest(c *gin.Context) (services.QRRequest, error) {
+    req := services.QRRequest{
+        Render: qr.Options{
+            Format: c.DefaultQuery("format", qr.FormatPNG),
+            ECC:    c.Query("ecc"),
+        },
+        UseLogo: c.Query("logo") == "true",
+        Source:  c.Query("source"),
+    }
+
+    var err error
+    if size := c.Query("size"); size != "" {
+        if req.Render.Size, err = strconv.Atoi(size); err != nil {
+            return req, errors.New("size must be a number of pixels")
+        }
+    }
+    if margin := c.Query("margin"); margin != "" {
+        if req.Render.Margin, err = strconv.Atoi(margin); err != nil {
+            return req, errors.New("margin must be a number of modules")
+        }
+        // Une marge explicitement nulle est représentée par une valeur négative (0 désigne la marge par défaut)
+        if req.Render.Margin == 0 {
+            req.Render.Margin = -1
+        }
+    }
+    if req.Render.Foreground, err = qr.ParseColor(c.Query("fg")); err != nil {
+        return req, err
+    }
+    if req.Render.Background, err = qr.ParseColor(c.Query("bg")); err != nil {
+        return req, err
+    }
+    return req, nil
+}
+
+// ExportHandler envoie en flux l'export des liens (et optionnellement de leurs clics)
+// Paramètres : format=csv|jsonl|ndjson, clicks=true, owner, from, to (dates de création)
+func ExportHandler() gin.HandlerFunc {
+    return func(c *gin.Context) {
+        exportService := workspaceServices(c).Export
+        opts := services.ExportOptions{
+            Format:     c.DefaultQuery("format", services.ExportFormatJSONL),
+            WithClicks: c.Query("clicks") == "true",
+            Filter:     repository.LinkFilter{Owner: c.Query("owner")},
+        }
+        if !services.IsValidExportFormat(opts.Format) {
+            c.JSON(http.StatusBadRequest, gin.H{
+                "error":   "Invalid format",
+                "message": "Format must be one of csv, jsonl, ndjson",
+            })
+            return
+        }
+
+        var err error
+        if from := c.Query("from"); from != "" {
+            if opts.Filter.CreatedFrom, err = services.ParseExportDate(from); err != nil {
+                c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date", "message": err.Error()})
+                return
+            }
+        }
+        if to := c.Query("to"); to != "" {
+            if opts.Filter.CreatedTo, err = services.ParseExportDate(to); err != nil {
+                c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date", "message": err.Error()})
+                return
+            }
+        }
+
+        c.Header("Content-Type", services.ExportContentType(opts.Format))
+        c.Header("Content-Disposition", "attachment; filename=links."+opts.Format)
+        c.Status(http.StatusOK)
+
+        // Les en-têtes sont déjà envoyés : une erreur en cours de flux ne peut plus qu'être loggée.
+        if err := exportService.Export(c.Writer, opts); err != nil {
+            log.Printf("Error streaming export: %v", err)
+        }
+    }
+}
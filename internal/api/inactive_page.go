@@ -0,0 +1,86 @@
+package api
+
+import (
+	"html/template"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+	"github.com/axellelanca/urlshortener/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// inactivePageTemplate est la page intégrée servie hors de la fenêtre d'activité d'un lien, lorsque
+// ni le lien (URL de repli) ni la configuration (links.inactive_page_file) n'en prévoient d'autre.
+var inactivePageTemplate = template.Must(template.New("inactive").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="robots" content="noindex">
+<title>{{if eq .Status "scheduled"}}Not yet available{{else}}No longer available{{end}}</title>
+<style>
+body { font-family: sans-serif; display: flex; justify-content: center; margin-top: 15vh; color: #222; }
+main { max-width: 40em; }
+</style>
+</head>
+<body>
+<main>
+{{if eq .Status "scheduled"}}<h1>Not yet available</h1>
+<p>This link goes live on {{.ActiveFrom}}. Please come back later.</p>
+{{else}}<h1>No longer available</h1>
+<p>This link was available until {{.ActiveUntil}}.</p>
+{{end}}</main>
+</body>
+</html>
+`))
+
+// serveInactiveLink répond pour un lien consulté hors de sa fenêtre d'activité (status : models.LinkStatusScheduled
+// ou LinkStatusEnded) : redirection temporaire vers son URL de repli s'il en a une, sinon page d'indisponibilité,
+// avec le code 503 (et Retry-After) avant l'ouverture de la fenêtre et 410 après sa fermeture. Aucun clic n'est compté.
+func serveInactiveLink(c *gin.Context, linkService *services.LinkService, link *models.Link, status string) {
+	// La réponse change à l'ouverture ou à la fermeture de la fenêtre : elle ne doit pas être mise en cache
+	c.Header("Cache-Control", "no-store")
+	if link.InactiveURL != "" {
+		c.Redirect(http.StatusFound, link.InactiveURL)
+		return
+	}
+
+	code := http.StatusGone
+	if status == models.LinkStatusScheduled {
+		code = http.StatusServiceUnavailable
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(time.Until(*link.ActiveFrom).Seconds()))))
+	}
+
+	page := linkService.InactivePage()
+	if page == nil {
+		page = inactivePageTemplate
+	}
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	c.Status(code)
+	err := page.Execute(c.Writer, struct {
+		ShortCode   string
+		Status      string
+		ActiveFrom  string
+		ActiveUntil string
+	}{
+		ShortCode:   link.ShortCode,
+		Status:      status,
+		ActiveFrom:  formatScheduleBound(link.ActiveFrom),
+		ActiveUntil: formatScheduleBound(link.ActiveUntil),
+	})
+	if err != nil {
+		log.Printf("Error rendering inactive link page: %v", err)
+	}
+}
+
+// formatScheduleBound formate une borne de fenêtre d'activité pour la page d'indisponibilité (vide si absente).
+func formatScheduleBound(bound *time.Time) string {
+	if bound == nil {
+		return ""
+	}
+	return bound.UTC().Format(time.RFC1123)
+}
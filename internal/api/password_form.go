@@ -0,0 +1,116 @@
+package api
+
+import (
+	"errors"
+	"html/template"
+	"log"
+	"net/http"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+	"github.com/axellelanca/urlshortener/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// passwordFormTemplate est la page servie à la place de la redirection pour un lien protégé par mot de passe.
+var passwordFormTemplate = template.Must(template.New("password").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="robots" content="noindex">
+<title>Protected link</title>
+<style>
+body { font-family: sans-serif; display: flex; justify-content: center; margin-top: 15vh; color: #222; }
+form { width: 20em; }
+input { width: 100%; box-sizing: border-box; padding: .5em; margin: .5em 0; }
+.error { color: #b00020; }
+</style>
+</head>
+<body>
+<form method="post" action="{{.Action}}">
+<h1>Protected link</h1>
+<p>This link is password protected.</p>
+{{if .Error}}<p class="error">{{.Error}}</p>{{end}}
+<input type="password" name="password" placeholder="Password" autofocus required>
+<input type="submit" value="Continue">
+</form>
+</body>
+</html>
+`))
+
+// renderPasswordForm affiche le formulaire de mot de passe, accompagné d'un éventuel message d'erreur.
+func renderPasswordForm(c *gin.Context, status int, message string) {
+	c.Header("Cache-Control", "no-store")
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	c.Status(status)
+	err := passwordFormTemplate.Execute(c.Writer, struct {
+		Action string
+		Error  string
+	}{
+		Action: c.Request.URL.RequestURI(),
+		Error:  message,
+	})
+	if err != nil {
+		log.Printf("Error rendering password form: %v", err)
+	}
+}
+
+// passwordCookiePath retourne le chemin du cookie d'accès : le code court, afin que l'accès accordé couvre le lien
+// avec ou sans barre oblique finale, et tous les chemins sous un lien préfixe.
+func passwordCookiePath(link *models.Link) string {
+	return "/" + link.ShortCode
+}
+
+// checkLinkPassword contrôle l'accès à un lien protégé par mot de passe. Elle retourne true si la redirection
+// peut avoir lieu (lien non protégé ou cookie d'accès valide) ; sinon la réponse a déjà été écrite :
+// formulaire (GET), erreur de saisie ou, pour un mot de passe correct (POST), pose du cookie d'accès puis
+// redirection vers la même URL en GET.
+func checkLinkPassword(c *gin.Context, passwordGate *services.PasswordGate, link *models.Link) bool {
+	if link.PasswordHash == "" {
+		if c.Request.Method == http.MethodPost {
+			c.JSON(http.StatusMethodNotAllowed, gin.H{
+				"error":   "Method not allowed",
+				"message": "This short link is not password protected",
+			})
+			return false
+		}
+		return true
+	}
+
+	if cookie, err := c.Cookie(passwordGate.CookieName(link)); err == nil && passwordGate.Authorized(link, cookie) {
+		return true
+	}
+	if c.Request.Method != http.MethodPost {
+		renderPasswordForm(c, http.StatusUnauthorized, "")
+		return false
+	}
+
+	token, err := passwordGate.Unlock(link, c.ClientIP(), c.PostForm("password"))
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrTooManyAttempts):
+			renderPasswordForm(c, http.StatusTooManyRequests, "Too many failed attempts. Please try again later.")
+		case errors.Is(err, services.ErrWrongPassword):
+			renderPasswordForm(c, http.StatusUnauthorized, "Incorrect password.")
+		default:
+			log.Printf("Error checking password for link %d: %v", link.ID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Internal server error",
+				"message": "Failed to check password",
+			})
+		}
+		return false
+	}
+
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     passwordGate.CookieName(link),
+		Value:    token,
+		Path:     passwordCookiePath(link),
+		MaxAge:   int(passwordGate.CookieTTL().Seconds()),
+		HttpOnly: true,
+		Secure:   secureRequest(c),
+		SameSite: http.SameSiteLaxMode,
+	})
+	c.Redirect(http.StatusSeeOther, c.Request.URL.RequestURI())
+	return false
+}
@@ -0,0 +1,145 @@
+package api
+
+import (
+	"encoding/json"
+	"html/template"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+	"github.com/axellelanca/urlshortener/internal/monitor"
+	"github.com/axellelanca/urlshortener/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// Demandes d'aperçu (suffixe du code court et paramètres de requête).
+const (
+	previewParam  = "preview" // ?preview=1 affiche l'aperçu au lieu de rediriger (équivalent au suffixe "+")
+	confirmParam  = "confirm" // ?confirm=1 franchit l'aperçu imposé d'un lien AlwaysPreview
+	previewSuffix = "+"       // /{shortCode}+ affiche l'aperçu au lieu de rediriger
+)
+
+// previewPageTemplate est la page d'aperçu d'un lien : destination, titre, état et date de création, ainsi que la
+// description, l'image et l'icône de la page de destination lorsqu'elles ont été récupérées.
+// La destination d'un lien à usage limité n'est pas affichée : seule une redirection consommée la révèle.
+// Les images sont chargées sans en-tête Referer, pour ne pas transmettre le lien court au site de destination.
+var previewPageTemplate = template.Must(template.New("preview").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="robots" content="noindex">
+<title>Link preview</title>
+<style>
+body { font-family: sans-serif; display: flex; justify-content: center; margin-top: 15vh; color: #222; }
+main { max-width: 40em; }
+.destination { word-break: break-all; font-family: monospace; background: #f4f4f4; padding: .5em; }
+.down { color: #b00020; }
+.up { color: #1b5e20; }
+dt { font-weight: bold; margin-top: .5em; }
+.favicon { width: 16px; height: 16px; vertical-align: middle; margin-right: .3em; }
+.thumbnail { max-width: 100%; max-height: 12em; margin-top: .5em; }
+</style>
+</head>
+<body>
+<main>
+<h1>Link preview</h1>
+{{if .Limited}}<p>This short link can only be opened {{.Remaining}} more time{{if ne .Remaining 1}}s{{end}}. Its destination is revealed when you continue.</p>
+{{else}}<p>This short link leads to:</p>
+<p class="destination">{{.Destination}}</p>
+{{end}}<dl>
+{{if .Title}}<dt>Title</dt><dd>{{if .Favicon}}<img class="favicon" src="{{.Favicon}}" alt="" referrerpolicy="no-referrer">{{end}}{{.Title}}</dd>{{end}}
+{{if .Description}}<dt>Description</dt><dd>{{.Description}}</dd>{{end}}
+{{if .Image}}<dd><img class="thumbnail" src="{{.Image}}" alt="" referrerpolicy="no-referrer"></dd>{{end}}
+<dt>Destination status</dt>
+<dd>{{if not .Monitored}}Not monitored{{else if not .HealthKnown}}Not checked yet{{else if .Accessible}}<span class="up">Reachable</span> (checked {{.CheckedAt}}){{else}}<span class="down">Unreachable</span> (checked {{.CheckedAt}}){{end}}</dd>
+<dt>Created</dt><dd>{{.CreatedAt}}</dd>
+</dl>
+<p><a href="{{.ContinueURL}}">Continue to the destination</a></p>
+</main>
+</body>
+</html>
+`))
+
+// previewRequested indique si l'aperçu est demandé explicitement, et retourne le code court sans le suffixe "+".
+func previewRequested(c *gin.Context) (string, bool) {
+	shortCode := c.Param("shortCode")
+	if strings.HasSuffix(shortCode, previewSuffix) {
+		return strings.TrimSuffix(shortCode, previewSuffix), true
+	}
+	return shortCode, c.Query(previewParam) == "1"
+}
+
+// linkTitle retourne le titre d'un lien, ou à défaut celui enregistré dans ses métadonnées ("title")
+// par les liens créés avant l'ajout du champ Title, ou celui de sa page de destination, ou une chaîne vide.
+func linkTitle(link *models.Link) string {
+	if link.Title != "" {
+		return link.Title
+	}
+	var metadata map[string]string
+	if link.Metadata != "" && json.Unmarshal([]byte(link.Metadata), &metadata) == nil && metadata["title"] != "" {
+		return metadata["title"]
+	}
+	return link.PageTitle
+}
+
+// renderPreviewPage affiche la page d'aperçu d'un lien, sans compter de clic. La destination affichée est celle
+// retenue pour ce visiteur ; monitored indique qu'il s'agit de l'URL longue, seule surveillée par le moniteur.
+// Le lien "continuer" pointe vers le lien court lui-même (sans demande d'aperçu, avec confirmation pour un lien
+// AlwaysPreview) afin que le clic soit enregistré ; les autres paramètres de la requête (ex: marqueur de source)
+// sont conservés.
+func renderPreviewPage(c *gin.Context, link *models.Link, destination string, monitored bool, urlMonitor *monitor.UrlMonitor) {
+	query := c.Request.URL.Query()
+	query.Del(previewParam)
+	if link.AlwaysPreview {
+		query.Set(confirmParam, "1")
+	}
+	continueURL := url.URL{Path: "/" + link.ShortCode + c.Param("path"), RawQuery: query.Encode()}
+
+	health, healthKnown := urlMonitor.LinkHealth(link.ID)
+	// Les métadonnées de la page ne décrivent que l'URL longue : elles sont omises pour une autre destination
+	// (règle, variante A/B) et pour un lien à usage limité, dont la destination reste masquée.
+	var description, image, favicon string
+	if monitored && !services.ViewLimited(link) {
+		description, image, favicon = link.PageDescription, link.PageImage, link.PageFavicon
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	c.Status(http.StatusOK)
+	err := previewPageTemplate.Execute(c.Writer, struct {
+		Destination string
+		Title       string
+		Monitored   bool
+		HealthKnown bool
+		Accessible  bool
+		CheckedAt   string
+		CreatedAt   string
+		ContinueURL string
+		Limited     bool
+		Remaining   int
+		Description string
+		Image       string
+		Favicon     string
+	}{
+		Destination: destination,
+		Monitored:   monitored,
+		Title:       linkTitle(link),
+		Description: description,
+		Image:       image,
+		Favicon:     favicon,
+		HealthKnown: healthKnown,
+		Accessible:  health.Accessible,
+		CheckedAt:   health.CheckedAt.UTC().Format(time.RFC1123),
+		CreatedAt:   link.CreatedAt.UTC().Format("January 2, 2006"),
+		ContinueURL: continueURL.String(),
+		Limited:     services.ViewLimited(link),
+		Remaining:   services.RemainingViews(link),
+	})
+	if err != nil {
+		log.Printf("Error rendering preview page: %v", err)
+	}
+}
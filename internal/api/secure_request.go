@@ -0,0 +1,59 @@
+package api
+
+import (
+	"net/netip"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// secureRequestKey est la clé du contexte Gin indiquant si la requête est arrivée en HTTPS jusqu'au serveur ou
+// jusqu'à un proxy de confiance.
+const secureRequestKey = "secure_request"
+
+// trustedProxyPrefixes convertit server.trusted_proxies (adresses IP ou plages CIDR) en plages d'adresses.
+// Les entrées invalides, déjà refusées au démarrage du serveur, sont ignorées.
+func trustedProxyPrefixes(proxies []string) []netip.Prefix {
+	prefixes := make([]netip.Prefix, 0, len(proxies))
+	for _, proxy := range proxies {
+		if !strings.Contains(proxy, "/") {
+			if addr, err := netip.ParseAddr(proxy); err == nil {
+				prefixes = append(prefixes, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+			}
+			continue
+		}
+		if prefix, err := netip.ParsePrefix(proxy); err == nil {
+			prefixes = append(prefixes, prefix.Masked())
+		}
+	}
+	return prefixes
+}
+
+// HTTPSMiddleware détermine si chaque requête est arrivée en HTTPS, pour l'attribut Secure des cookies posés
+// par les redirections : connexion TLS directe, ou en-tête X-Forwarded-Proto posé par l'un des proxys de
+// confiance (server.trusted_proxies). Envoyé par un autre client, cet en-tête est ignoré.
+func HTTPSMiddleware(trustedProxies []string) gin.HandlerFunc {
+	prefixes := trustedProxyPrefixes(trustedProxies)
+	return func(c *gin.Context) {
+		secure := c.Request.TLS != nil
+		if !secure && len(prefixes) > 0 {
+			if peer, err := netip.ParseAddr(c.RemoteIP()); err == nil {
+				peer = peer.Unmap()
+				for _, prefix := range prefixes {
+					if prefix.Contains(peer) {
+						secure = strings.EqualFold(c.GetHeader("X-Forwarded-Proto"), "https")
+						break
+					}
+				}
+			}
+		}
+		c.Set(secureRequestKey, secure)
+		c.Next()
+	}
+}
+
+// secureRequest indique si la requête est arrivée en HTTPS, selon HTTPSMiddleware (à défaut, selon la seule
+// connexion directe).
+func secureRequest(c *gin.Context) bool {
+	return c.Request.TLS != nil || c.GetBool(secureRequestKey)
+}
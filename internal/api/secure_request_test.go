@@ -0,0 +1,55 @@
+package api
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestHTTPSMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(HTTPSMiddleware([]string{"10.0.0.0/8", "::1", "invalide"}))
+	router.GET("/", func(c *gin.Context) {
+		if secureRequest(c) {
+			c.String(http.StatusOK, "https")
+			return
+		}
+		c.String(http.StatusOK, "http")
+	})
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		proto      string
+		tls        bool
+		want       string
+	}{
+		{"connexion TLS directe", "203.0.113.5:4000", "", true, "https"},
+		{"client direct en HTTP", "203.0.113.5:4000", "", false, "http"},
+		{"en-tête d'un client non fiable", "203.0.113.5:4000", "https", false, "http"},
+		{"proxy de confiance en HTTPS", "10.1.2.3:4000", "https", false, "https"},
+		{"proxy de confiance en HTTP", "10.1.2.3:4000", "http", false, "http"},
+		{"proxy de confiance IPv6", "[::1]:4000", "HTTPS", false, "https"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			if tt.proto != "" {
+				req.Header.Set("X-Forwarded-Proto", tt.proto)
+			}
+			if tt.tls {
+				req.TLS = &tls.ConnectionState{}
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			if got := w.Body.String(); got != tt.want {
+				t.Errorf("requête vue en %s, attendu %s", got, tt.want)
+			}
+		})
+	}
+}
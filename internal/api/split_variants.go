@@ -0,0 +1,39 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/axellelanca/urlshortener/internal/experiment"
+	"github.com/axellelanca/urlshortener/internal/models"
+	"github.com/axellelanca/urlshortener/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// variantCookieName retourne le nom du cookie mémorisant la variante A/B attribuée au visiteur d'un lien.
+func variantCookieName(link *models.Link) string {
+	return fmt.Sprintf("link_variant_%d", link.ID)
+}
+
+// chooseVariant retourne la variante A/B à servir au visiteur d'un lien, et false si le lien n'a pas de répartition.
+// La variante attribuée est mémorisée dans un cookie pour que le visiteur la retrouve à chaque visite ; sans cookie,
+// le tirage dépend de son adresse IP et de son User-Agent, ce qui le rend stable lui aussi.
+func chooseVariant(c *gin.Context, linkService *services.LinkService, link *models.Link) (experiment.Variant, bool) {
+	assigned, _ := c.Cookie(variantCookieName(link))
+	variant, ok := linkService.ChooseVariant(link, assigned, c.ClientIP()+"|"+c.Request.UserAgent())
+	if !ok {
+		return variant, false
+	}
+	if variant.Name != assigned {
+		http.SetCookie(c.Writer, &http.Cookie{
+			Name:     variantCookieName(link),
+			Value:    variant.Name,
+			Path:     "/" + link.ShortCode,
+			MaxAge:   int(linkService.VariantTTL().Seconds()),
+			HttpOnly: true,
+			Secure:   secureRequest(c),
+			SameSite: http.SameSiteLaxMode,
+		})
+	}
+	return variant, true
+}
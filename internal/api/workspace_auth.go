@@ -0,0 +1,89 @@
+package api
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+	"github.com/axellelanca/urlshortener/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// workspaceScopeKey est la clé du contexte Gin sous laquelle sont rangés les services de l'espace de travail de la requête.
+const workspaceScopeKey = "workspace_scope"
+
+// WorkspaceServices regroupe les services que WorkspaceMiddleware restreint à l'espace de travail de chaque requête.
+type WorkspaceServices struct {
+	Links   *services.LinkService
+	Domains *services.DomainService
+	Export  *services.ExportService
+}
+
+// forWorkspace retourne les services restreints à un espace de travail : ils ne voient que ses liens, ses clics
+// et ses domaines, et les liens qu'ils créent lui sont rattachés.
+func (s WorkspaceServices) forWorkspace(workspace *models.Workspace) WorkspaceServices {
+	return WorkspaceServices{
+		Links:   s.Links.ForWorkspace(workspace),
+		Domains: s.Domains.ForWorkspace(workspace.ID),
+		Export:  s.Export.ForWorkspace(workspace.ID),
+	}
+}
+
+// apiKeyFromRequest retourne la clé d'API d'une requête : en-tête "Authorization: Bearer <clé>" ou "X-API-Key".
+func apiKeyFromRequest(c *gin.Context) string {
+	if authorization := c.GetHeader("Authorization"); authorization != "" {
+		scheme, key, found := strings.Cut(authorization, " ")
+		if found && strings.EqualFold(scheme, "Bearer") {
+			return strings.TrimSpace(key)
+		}
+	}
+	return c.GetHeader("X-API-Key")
+}
+
+// WorkspaceMiddleware rattache chaque requête de l'API à l'espace de travail de sa clé d'API, et met à disposition
+// des handlers les services restreints à cet espace (workspaceServices).
+// Une clé inconnue est refusée (401) ; une requête sans clé relève de l'espace par défaut, sauf si requireKey
+// impose une clé.
+func WorkspaceMiddleware(workspaceService *services.WorkspaceService, scoped WorkspaceServices, requireKey bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		workspace := models.DefaultWorkspace()
+		if key := apiKeyFromRequest(c); key != "" {
+			var err error
+			workspace, err = workspaceService.WorkspaceForAPIKey(key)
+			if errors.Is(err, services.ErrInvalidAPIKey) {
+				c.Header("WWW-Authenticate", "Bearer")
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+					"error":   "Unauthorized",
+					"message": "Invalid API key",
+				})
+				return
+			}
+			if err != nil {
+				log.Printf("Error resolving API key: %v", err)
+				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+					"error":   "Internal server error",
+					"message": "Failed to resolve API key",
+				})
+				return
+			}
+		} else if requireKey {
+			c.Header("WWW-Authenticate", "Bearer")
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"error":   "Unauthorized",
+				"message": "An API key is required (Authorization: Bearer <key> or X-API-Key header)",
+			})
+			return
+		}
+
+		c.Set(workspaceScopeKey, scoped.forWorkspace(workspace))
+		c.Next()
+	}
+}
+
+// workspaceServices retourne les services restreints à l'espace de travail de la requête, préparés par
+// WorkspaceMiddleware. Un handler monté hors de ce middleware est une erreur de programmation.
+func workspaceServices(c *gin.Context) WorkspaceServices {
+	return c.MustGet(workspaceScopeKey).(WorkspaceServices)
+}
@@ -12,17 +12,78 @@ import (
 // (ou des variables d'environnement) aux champs de la structure Go.
 type Config struct {
 	Server struct {
-		Port    int    `mapstructure:"port"`
-		BaseURL string `mapstructure:"base_url"`
+		Port           int      `mapstructure:"port"`
+		BaseURL        string   `mapstructure:"base_url"`
+		MaxBatchSize   int      `mapstructure:"max_batch_size"`  // Nombre maximum de liens par appel à POST /api/v1/links/batch
+		TrustedProxies []string `mapstructure:"trusted_proxies"` // Proxys (IP ou CIDR) dont les en-têtes X-Forwarded-For et X-Forwarded-Proto sont crus (adresse du visiteur, HTTPS)
 	} `mapstructure:"server"`
 	Database struct {
 		Name string `mapstructure:"name"`
 	} `mapstructure:"database"`
+	Workspaces struct {
+		RequireAPIKey bool `mapstructure:"require_api_key"` // Refuse les requêtes /api/v1 sans clé d'API au lieu de les rattacher à l'espace par défaut
+	} `mapstructure:"workspaces"`
 	Analytics struct {
-		BufferSize int `mapstructure:"buffer_size"`
+		BufferSize  int `mapstructure:"buffer_size"`
+		WorkerCount int `mapstructure:"worker_count"`
 	} `mapstructure:"analytics"`
+	Links struct {
+		Deduplicate         bool   `mapstructure:"deduplicate"`           // Retourne le lien existant pour une URL identique du même propriétaire
+		IdempotencyTTLHours int    `mapstructure:"idempotency_ttl_hours"` // Durée de validité des clés Idempotency-Key
+		VariantCookieDays   int    `mapstructure:"variant_cookie_days"`   // Durée pendant laquelle un visiteur garde sa variante A/B
+		InactivePageFile    string `mapstructure:"inactive_page_file"`    // Modèle HTML servi hors de la fenêtre d'activité d'un lien, page intégrée si vide
+	} `mapstructure:"links"`
+	ShortCodes struct {
+		Strategy         string  `mapstructure:"strategy"`           // random, sequential ou pronounceable (mots inventés faits de syllabes)
+		Length           int     `mapstructure:"length"`             // Longueur initiale des codes générés
+		Alphabet         string  `mapstructure:"alphabet"`           // Caractères des stratégies random et sequential
+		MaxLength        int     `mapstructure:"max_length"`         // Longueur maximale atteinte par allongement automatique
+		MaxRetries       int     `mapstructure:"max_retries"`        // Tentatives en cas de collision
+		GrowthThreshold  float64 `mapstructure:"growth_threshold"`   // Taux de collision déclenchant l'allongement des codes
+		WordFilter       bool    `mapstructure:"word_filter"`        // Écarte les codes et alias contenant un mot offensant
+		BlockedWordsFile string  `mapstructure:"blocked_words_file"` // Mots interdits supplémentaires, un par ligne
+	} `mapstructure:"short_codes"`
+	Validation struct {
+		MaxURLLength             int  `mapstructure:"max_url_length"`             // Longueur maximale d'une URL longue
+		BlockPrivateDestinations bool `mapstructure:"block_private_destinations"` // Refuse les destinations privées ou loopback
+		ResolveHosts             bool `mapstructure:"resolve_hosts"`              // Résout les noms d'hôte pour vérifier leurs adresses IP
+	} `mapstructure:"validation"`
+	Screening struct {
+		DomainFiles           []string `mapstructure:"domain_files"`            // Listes de domaines bloqués, un par ligne
+		RegexFiles            []string `mapstructure:"regex_files"`             // Expressions régulières appliquées à l'URL complète
+		HostsFiles            []string `mapstructure:"hosts_files"`             // Flux de blocage au format /etc/hosts
+		RescanIntervalMinutes int      `mapstructure:"rescan_interval_minutes"` // Intervalle de re-vérification des liens existants
+	} `mapstructure:"screening"`
+	QR struct {
+		Size        int    `mapstructure:"size"`         // Taille par défaut des images, en pixels
+		Margin      int    `mapstructure:"margin"`       // Marge par défaut, en modules
+		ECC         string `mapstructure:"ecc"`          // Niveau de correction d'erreur par défaut : L, M, Q ou H
+		Foreground  string `mapstructure:"foreground"`   // Couleur des modules (#rrggbb)
+		Background  string `mapstructure:"background"`   // Couleur du fond (#rrggbb)
+		LogoFile    string `mapstructure:"logo_file"`    // Logo PNG/JPEG centré sur demande (logo=true)
+		SourceParam string `mapstructure:"source_param"` // Paramètre de requête portant le marqueur de source des scans
+		SourceValue string `mapstructure:"source_value"` // Marqueur ajouté par défaut à l'URL encodée
+	} `mapstructure:"qr"`
+	LinkPasswords struct {
+		CookieSecret         string `mapstructure:"cookie_secret"`          // Clé de signature des cookies d'accès ; aléatoire à chaque démarrage si vide
+		CookieTTLMinutes     int    `mapstructure:"cookie_ttl_minutes"`     // Durée de validité d'un cookie d'accès
+		MaxAttempts          int    `mapstructure:"max_attempts"`           // Mots de passe erronés tolérés par adresse IP et par lien
+		AttemptWindowMinutes int    `mapstructure:"attempt_window_minutes"` // Fenêtre de comptage des tentatives erronées
+	} `mapstructure:"link_passwords"`
+	GeoIP struct {
+		DatabaseFile string `mapstructure:"database_file"` // Base MMDB locale (GeoLite2 Country/City...) ; géolocalisation désactivée si vide
+	} `mapstructure:"geoip"`
 	Monitor struct {
 		IntervalMinutes int `mapstructure:"interval_minutes"`
+		PageInfo        struct {
+			Enabled bool `mapstructure:"enabled"` // Récupère en tâche de fond le titre, les balises OpenGraph et l'icône des destinations
+			MaxKB   int  `mapstructure:"max_kb"`  // Taille maximale lue par page, en kilo-octets
+		} `mapstructure:"page_info"`
+		Content struct {
+			Enabled   bool `mapstructure:"enabled"`   // Relève le contenu des destinations des liens surveillés (watch_content)
+			MaxKB     int  `mapstructure:"max_kb"`    // Taille maximale lue par page surveillée, en kilo-octets
+			Threshold int  `mapstructure:"threshold"` // Bits SimHash différents (sur 64) à partir desquels un changement est notifié
+		} `mapstructure:"content"`
 	} `mapstructure:"monitor"`
 	Workers struct {
 		Clicks struct {
@@ -52,9 +113,50 @@ func LoadConfig() (*Config, error) {
 	// server.port, server.base_url etc.
 	viper.SetDefault("server.port", 8080)
 	viper.SetDefault("server.base_url", "http://localhost:8080")
+	viper.SetDefault("server.max_batch_size", 1000)
+	viper.SetDefault("server.trusted_proxies", []string{})
 	viper.SetDefault("database.name", "url_shortener.db")
+	viper.SetDefault("workspaces.require_api_key", false)
 	viper.SetDefault("analytics.buffer_size", 1000)
+	viper.SetDefault("analytics.worker_count", 5)
+	viper.SetDefault("links.deduplicate", false)
+	viper.SetDefault("links.idempotency_ttl_hours", 24)
+	viper.SetDefault("links.variant_cookie_days", 30)
+	viper.SetDefault("links.inactive_page_file", "")
+	viper.SetDefault("short_codes.strategy", "random")
+	viper.SetDefault("short_codes.length", 6)
+	viper.SetDefault("short_codes.alphabet", "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789")
+	viper.SetDefault("short_codes.max_length", 10)
+	viper.SetDefault("short_codes.max_retries", 5)
+	viper.SetDefault("short_codes.growth_threshold", 0.1)
+	viper.SetDefault("short_codes.word_filter", true)
+	viper.SetDefault("short_codes.blocked_words_file", "")
+	viper.SetDefault("validation.max_url_length", 2048)
+	viper.SetDefault("validation.block_private_destinations", false)
+	viper.SetDefault("validation.resolve_hosts", false)
+	viper.SetDefault("screening.domain_files", []string{})
+	viper.SetDefault("screening.regex_files", []string{})
+	viper.SetDefault("screening.hosts_files", []string{})
+	viper.SetDefault("screening.rescan_interval_minutes", 60)
+	viper.SetDefault("qr.size", 256)
+	viper.SetDefault("qr.margin", 4)
+	viper.SetDefault("qr.ecc", "M")
+	viper.SetDefault("qr.foreground", "#000000")
+	viper.SetDefault("qr.background", "#ffffff")
+	viper.SetDefault("qr.logo_file", "")
+	viper.SetDefault("qr.source_param", "src")
+	viper.SetDefault("qr.source_value", "qr")
+	viper.SetDefault("link_passwords.cookie_secret", "")
+	viper.SetDefault("link_passwords.cookie_ttl_minutes", 15)
+	viper.SetDefault("link_passwords.max_attempts", 5)
+	viper.SetDefault("link_passwords.attempt_window_minutes", 15)
+	viper.SetDefault("geoip.database_file", "")
 	viper.SetDefault("monitor.interval_minutes", 5)
+	viper.SetDefault("monitor.page_info.enabled", false)
+	viper.SetDefault("monitor.page_info.max_kb", 512)
+	viper.SetDefault("monitor.content.enabled", false)
+	viper.SetDefault("monitor.content.max_kb", 2048)
+	viper.SetDefault("monitor.content.threshold", 16)
 
 	// TODO : Lire le fichier de configuration.
 	err := viper.ReadInConfig()
@@ -76,6 +178,14 @@ func LoadConfig() (*Config, error) {
 		return nil, fmt.Errorf("unable to decode into config struct: %w", err)
 	}
 
+	// Les workers de clics reprennent les réglages de la section analytics, sauf s'ils sont définis explicitement
+	if cfg.Workers.Clicks.ChannelBufferSize == 0 {
+		cfg.Workers.Clicks.ChannelBufferSize = cfg.Analytics.BufferSize
+	}
+	if cfg.Workers.Clicks.NumberOfWorkers == 0 {
+		cfg.Workers.Clicks.NumberOfWorkers = cfg.Analytics.WorkerCount
+	}
+
 	// Log  pour vérifier la config chargée
 	log.Printf("Configuration loaded: Server Port=%d, DB Name=%s, Analytics Buffer=%d, Monitor Interval=%dmin",
 		cfg.Server.Port, cfg.Database.Name, cfg.Analytics.BufferSize, cfg.Monitor.IntervalMinutes)
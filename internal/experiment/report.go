@@ -0,0 +1,128 @@
+package experiment
+
+import "math"
+
+// Seuils du test de répartition.
+const (
+	SignificanceLevel = 0.05 // Seuil de la p-valeur en dessous duquel l'écart est jugé significatif
+	MinExpectedClicks = 5    // Clics attendus par variante en dessous desquels le test n'est pas fiable
+)
+
+// VariantStats décrit le trafic reçu par une variante.
+type VariantStats struct {
+	Name          string  `json:"name"`
+	Target        string  `json:"target"`
+	Weight        int     `json:"weight"`
+	Clicks        int     `json:"clicks"`
+	ExpectedShare float64 `json:"expected_share"` // Part du trafic attendue d'après les poids (0 à 1)
+	ObservedShare float64 `json:"observed_share"` // Part du trafic effectivement reçue (0 à 1)
+}
+
+// Report résume le trafic d'une répartition A/B. L'indicateur de significativité est un test du khi-deux
+// d'adéquation : il signale une répartition observée qui s'écarte des poids configurés plus que le hasard
+// ne l'expliquerait (variante cassée, visiteurs bloqués par une destination, robots...).
+type Report struct {
+	Variants    []VariantStats `json:"variants"`
+	TotalClicks int            `json:"total_clicks"`
+	ChiSquare   float64        `json:"chi_square"`
+	PValue      float64        `json:"p_value"`
+	EnoughData  bool           `json:"enough_data"` // Chaque variante attend au moins MinExpectedClicks clics
+	Significant bool           `json:"significant"` // Écart significatif (p < SignificanceLevel), si EnoughData
+}
+
+// Analyze construit le rapport d'une répartition à partir des clics comptés par nom de variante.
+// Les clics de variantes qui n'existent plus sont ignorés.
+func Analyze(variants []Variant, clicks map[string]int) Report {
+	report := Report{Variants: make([]VariantStats, len(variants)), PValue: 1}
+	totalWeight := 0
+	for i, variant := range variants {
+		totalWeight += variant.Weight
+		report.TotalClicks += clicks[variant.Name]
+		report.Variants[i] = VariantStats{
+			Name:   variant.Name,
+			Target: variant.Target,
+			Weight: variant.Weight,
+			Clicks: clicks[variant.Name],
+		}
+	}
+	if totalWeight == 0 {
+		return report
+	}
+
+	report.EnoughData = report.TotalClicks > 0
+	for i := range report.Variants {
+		stats := &report.Variants[i]
+		stats.ExpectedShare = float64(stats.Weight) / float64(totalWeight)
+		if report.TotalClicks == 0 {
+			continue
+		}
+		stats.ObservedShare = float64(stats.Clicks) / float64(report.TotalClicks)
+		expected := stats.ExpectedShare * float64(report.TotalClicks)
+		if expected < MinExpectedClicks {
+			report.EnoughData = false
+		}
+		report.ChiSquare += math.Pow(float64(stats.Clicks)-expected, 2) / expected
+	}
+	if report.TotalClicks > 0 {
+		report.PValue = chiSquarePValue(report.ChiSquare, len(variants)-1)
+	}
+	report.Significant = report.EnoughData && report.PValue < SignificanceLevel
+	return report
+}
+
+// chiSquarePValue retourne la probabilité qu'une variable du khi-deux à df degrés de liberté dépasse x.
+func chiSquarePValue(x float64, df int) float64 {
+	if df <= 0 || x <= 0 {
+		return 1
+	}
+	return upperGamma(float64(df)/2, x/2)
+}
+
+// upperGamma calcule la fonction gamma incomplète supérieure régularisée Q(a, x),
+// par développement en série si x < a+1 et par fraction continue sinon.
+func upperGamma(a, x float64) float64 {
+	const (
+		maxIterations = 200
+		epsilon       = 1e-12
+		tiny          = 1e-300
+	)
+	lgamma, _ := math.Lgamma(a)
+	prefix := math.Exp(-x + a*math.Log(x) - lgamma)
+
+	if x < a+1 {
+		sum, term := 1/a, 1/a
+		for n := 1; n < maxIterations; n++ {
+			term *= x / (a + float64(n))
+			sum += term
+			if math.Abs(term) < math.Abs(sum)*epsilon {
+				break
+			}
+		}
+		return math.Max(0, 1-sum*prefix)
+	}
+
+	// Fraction continue de Lentz
+	b := x + 1 - a
+	c := 1 / tiny
+	d := 1 / b
+	h := d
+	for n := 1; n < maxIterations; n++ {
+		an := -float64(n) * (float64(n) - a)
+		b += 2
+		d = an*d + b
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = b + an/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		delta := d * c
+		h *= delta
+		if math.Abs(delta-1) < epsilon {
+			break
+		}
+	}
+	return math.Min(1, prefix*h)
+}
@@ -0,0 +1,64 @@
+package experiment
+
+import (
+	"math"
+	"testing"
+)
+
+func TestChiSquarePValue(t *testing.T) {
+	// Valeurs de référence des tables du khi-deux, et valeurs exactes pour un nombre pair de degrés de liberté
+	// (Q(k, x) = e^-x * somme des x^i/i! pour i < k).
+	tests := []struct {
+		x    float64
+		df   int
+		want float64
+	}{
+		{3.841459, 1, 0.05},
+		{6.634897, 1, 0.01},
+		{5.991465, 2, 0.05},
+		{7.814728, 3, 0.05},
+		{2, 2, math.Exp(-1)},
+		{1, 4, 1.5 * math.Exp(-0.5)},
+		{20, 10, math.Exp(-10) * (1 + 10 + 50 + 1000.0/6 + 10000.0/24)},
+		{0, 3, 1},
+		{5, 0, 1},
+	}
+	for _, tt := range tests {
+		if got := chiSquarePValue(tt.x, tt.df); math.Abs(got-tt.want) > 1e-6 {
+			t.Errorf("chiSquarePValue(%v, %d) = %v, attendu %v", tt.x, tt.df, got, tt.want)
+		}
+	}
+}
+
+func TestAnalyze(t *testing.T) {
+	variants := []Variant{{Name: "a", Target: "https://example.com/a", Weight: 1}, {Name: "b", Target: "https://example.com/b", Weight: 1}}
+
+	tests := []struct {
+		name            string
+		clicks          map[string]int
+		wantEnoughData  bool
+		wantSignificant bool
+	}{
+		{"répartition conforme", map[string]int{"a": 100, "b": 100}, true, false},
+		{"écart significatif", map[string]int{"a": 150, "b": 50}, true, true},
+		{"trop peu de clics", map[string]int{"a": 7, "b": 1}, false, false},
+		{"aucun clic", map[string]int{}, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := Analyze(variants, tt.clicks)
+			if report.EnoughData != tt.wantEnoughData || report.Significant != tt.wantSignificant {
+				t.Errorf("EnoughData = %t, Significant = %t (p = %v), attendu %t et %t",
+					report.EnoughData, report.Significant, report.PValue, tt.wantEnoughData, tt.wantSignificant)
+			}
+		})
+	}
+
+	report := Analyze(variants, map[string]int{"a": 150, "b": 50, "supprimée": 40})
+	if report.TotalClicks != 200 || report.ChiSquare != 50 {
+		t.Errorf("TotalClicks = %d, ChiSquare = %v, attendu 200 et 50", report.TotalClicks, report.ChiSquare)
+	}
+	if share := report.Variants[0].ObservedShare; share != 0.75 {
+		t.Errorf("part observée de a = %v, attendu 0.75", share)
+	}
+}
@@ -0,0 +1,120 @@
+package experiment
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+// ErrInvalidVariant est retournée (enveloppée avec la raison précise) pour une répartition A/B invalide.
+var ErrInvalidVariant = errors.New("invalid split variant")
+
+// Limites d'une répartition A/B.
+const (
+	MaxVariants = 10   // Nombre maximal de variantes par lien
+	MaxWeight   = 1000 // Poids maximal d'une variante
+)
+
+var namePattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,50}$`)
+
+// Variant est l'une des destinations entre lesquelles un lien répartit son trafic.
+// Chaque visiteur reçoit une variante avec une probabilité proportionnelle à son poids.
+type Variant struct {
+	Name   string `json:"name,omitempty"`   // Nom enregistré sur les clics ; "variant-<position>" par défaut
+	Target string `json:"target"`           // URL de destination
+	Weight int    `json:"weight,omitempty"` // Part relative du trafic (1 à MaxWeight), 1 par défaut
+}
+
+// Normalize vérifie une répartition et retourne sa forme normalisée : chaque variante a un nom et un poids.
+// La validité des URLs cibles n'est pas vérifiée ici : elle relève du validateur d'URLs de l'appelant.
+func Normalize(variants []Variant) ([]Variant, error) {
+	if len(variants) == 0 {
+		return nil, nil
+	}
+	if len(variants) < 2 || len(variants) > MaxVariants {
+		return nil, fmt.Errorf("%w: between 2 and %d variants are required", ErrInvalidVariant, MaxVariants)
+	}
+	names := make(map[string]bool)
+	normalized := make([]Variant, len(variants))
+	for i, variant := range variants {
+		position := i + 1
+		if variant.Name == "" {
+			variant.Name = fmt.Sprintf("variant-%d", position)
+		} else if !namePattern.MatchString(variant.Name) {
+			return nil, fmt.Errorf("%w: variant %d: name must be 1 to 50 letters, digits, '.', '-' or '_'", ErrInvalidVariant, position)
+		}
+		if names[variant.Name] {
+			return nil, fmt.Errorf("%w: variant %d: name %q is already used", ErrInvalidVariant, position, variant.Name)
+		}
+		names[variant.Name] = true
+		if variant.Target == "" {
+			return nil, fmt.Errorf("%w: variant %d: target is required", ErrInvalidVariant, position)
+		}
+		if variant.Weight == 0 {
+			variant.Weight = 1
+		}
+		if variant.Weight < 0 || variant.Weight > MaxWeight {
+			return nil, fmt.Errorf("%w: variant %d: weight must be between 1 and %d", ErrInvalidVariant, position, MaxWeight)
+		}
+		normalized[i] = variant
+	}
+	return normalized, nil
+}
+
+// Encode sérialise une répartition pour son stockage avec le lien ; une liste vide donne une chaîne vide.
+func Encode(variants []Variant) (string, error) {
+	if len(variants) == 0 {
+		return "", nil
+	}
+	encoded, err := json.Marshal(variants)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode split variants: %w", err)
+	}
+	return string(encoded), nil
+}
+
+// Decode relit une répartition stockée avec un lien (chaîne vide pour aucune variante).
+func Decode(encoded string) ([]Variant, error) {
+	if encoded == "" {
+		return nil, nil
+	}
+	var variants []Variant
+	if err := json.Unmarshal([]byte(encoded), &variants); err != nil {
+		return nil, fmt.Errorf("failed to decode split variants: %w", err)
+	}
+	return variants, nil
+}
+
+// Find retourne la position de la variante nommée name, et false si elle n'existe pas (ou plus).
+func Find(variants []Variant, name string) (int, bool) {
+	for i, variant := range variants {
+		if variant.Name == name {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// Pick choisit une variante selon les poids, de façon déterministe pour une même clé de visiteur
+// (ex: adresse IP et User-Agent) : un même visiteur retombe toujours sur la même variante.
+func Pick(variants []Variant, key string) int {
+	total := 0
+	for _, variant := range variants {
+		total += variant.Weight
+	}
+	if total <= 0 {
+		return 0
+	}
+	sum := sha256.Sum256([]byte(key))
+	point := int(binary.BigEndian.Uint64(sum[:8]) % uint64(total))
+	for i, variant := range variants {
+		if point < variant.Weight {
+			return i
+		}
+		point -= variant.Weight
+	}
+	return len(variants) - 1
+}
@@ -0,0 +1,94 @@
+package geoip
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"strings"
+
+	"github.com/oschwald/maxminddb-golang"
+)
+
+// Location est la position géographique associée à une adresse IP. Les champs sont vides s'ils sont inconnus.
+type Location struct {
+	Country string // Code pays ISO 3166-1 alpha-2, en majuscules (ex: "FR")
+	Region  string // Première subdivision du pays (région, état...), nom anglais ou code ISO à défaut
+}
+
+// record est la partie d'un enregistrement MMDB lue par le Resolver. Elle correspond au schéma des bases
+// GeoIP2/GeoLite2 Country et City de MaxMind, repris par les bases compatibles (ex: DB-IP Lite).
+type record struct {
+	Country struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"country"`
+	RegisteredCountry struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"registered_country"`
+	Subdivisions []struct {
+		ISOCode string            `maxminddb:"iso_code"`
+		Names   map[string]string `maxminddb:"names"`
+	} `maxminddb:"subdivisions"`
+}
+
+// Resolver résout des adresses IP en positions géographiques à partir d'une base MMDB locale,
+// sans aucun accès réseau. Un Resolver nil ne résout rien, ce qui permet de désactiver la géolocalisation.
+// Il peut être utilisé par plusieurs goroutines à la fois.
+type Resolver struct {
+	reader *maxminddb.Reader
+}
+
+// Open ouvre la base MMDB située à path. Un chemin vide désactive la géolocalisation : le Resolver retourné est nil.
+func Open(path string) (*Resolver, error) {
+	if path == "" {
+		return nil, nil
+	}
+	reader, err := maxminddb.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open GeoIP database %s: %w", path, err)
+	}
+	log.Printf("GeoIP database loaded: %s (%s, built %d)", path, reader.Metadata.DatabaseType, reader.Metadata.BuildEpoch)
+	return &Resolver{reader: reader}, nil
+}
+
+// Enabled indique si une base est chargée.
+func (r *Resolver) Enabled() bool {
+	return r != nil
+}
+
+// Lookup retourne la position de l'adresse IP fournie (forme textuelle). Une adresse invalide, privée ou absente
+// de la base donne une position vide.
+func (r *Resolver) Lookup(ip string) Location {
+	if r == nil {
+		return Location{}
+	}
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return Location{}
+	}
+	var rec record
+	if err := r.reader.Lookup(parsed, &rec); err != nil {
+		log.Printf("GeoIP lookup failed for %s: %v", ip, err)
+		return Location{}
+	}
+
+	location := Location{Country: strings.ToUpper(rec.Country.ISOCode)}
+	if location.Country == "" {
+		location.Country = strings.ToUpper(rec.RegisteredCountry.ISOCode)
+	}
+	if len(rec.Subdivisions) > 0 {
+		subdivision := rec.Subdivisions[0]
+		location.Region = subdivision.Names["en"]
+		if location.Region == "" {
+			location.Region = subdivision.ISOCode
+		}
+	}
+	return location
+}
+
+// Close libère la base chargée.
+func (r *Resolver) Close() error {
+	if r == nil {
+		return nil
+	}
+	return r.reader.Close()
+}
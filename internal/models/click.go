@@ -5,22 +5,33 @@ import "time"
 // Click représente un événement de clic sur un lien raccourci.
 // GORM utilisera ces tags pour créer la table 'clicks'.
 type Click struct {
-	ID        uint      `gorm:"primaryKey"`        // Clé primaire
-	LinkID    uint      `gorm:"index"`             // Clé étrangère vers la table 'links', indexée pour des requêtes efficaces
-	Link      Link      `gorm:"foreignKey:LinkID"` // Relation GORM: indique que LinkID est une FK vers le champ ID de Link
-	Timestamp time.Time // Horodatage précis du clic
-	UserAgent string    `gorm:"size:255"` // User-Agent de l'utilisateur qui a cliqué (informations sur le navigateur/OS)
-	IPAddress string    `gorm:"size:50"`  // Adresse IP de l'utilisateur
+	ID          uint      `gorm:"primaryKey"`                                                         // Clé primaire
+	LinkID      uint      `gorm:"index"`                                                              // Clé étrangère vers la table 'links', indexée pour des requêtes efficaces
+	Link        Link      `gorm:"foreignKey:LinkID"`                                                  // Relation GORM: indique que LinkID est une FK vers le champ ID de Link
+	WorkspaceID uint      `gorm:"not null;default:0;index:idx_clicks_workspace_timestamp,priority:1"` // Espace de travail du lien, pour les quotas de clics
+	Timestamp   time.Time `gorm:"index:idx_clicks_workspace_timestamp,priority:2"`                    // Horodatage précis du clic
+	UserAgent   string    `gorm:"size:255"`                                                           // User-Agent de l'utilisateur qui a cliqué (informations sur le navigateur/OS)
+	IPAddress   string    `gorm:"size:50"`                                                            // Adresse IP de l'utilisateur
+	Source      string    `gorm:"size:50"`                                                            // Marqueur de source du clic (ex: "qr" pour un scan de QR code), vide si absent
+	Rule        string    `gorm:"size:50"`                                                            // Règle de redirection appliquée, vide si le visiteur a été redirigé vers l'URL longue
+	Country     string    `gorm:"size:2"`                                                             // Code pays ISO de l'adresse IP (base GeoIP), vide si inconnu
+	Variant     string    `gorm:"size:50"`                                                            // Variante A/B servie, vide si le lien n'a pas de répartition
+	Region      string    `gorm:"size:100"`                                                           // Région de l'adresse IP (base GeoIP), vide si inconnue
 }
 
 // TODO créer la struct pour ClickEvent
 
 type ClickEvent struct {
-	LinkID    uint
-	Timestamp time.Time
-	UserAgent string
-	IPAddress string
+	LinkID      uint
+	WorkspaceID uint
+	Timestamp   time.Time
+	UserAgent   string
+	IPAddress   string
+	Source      string
+	Rule        string
+	Variant     string
 }
+
 // ClickEvent représente un événement de clic brut, destiné à être passé via un channel
 // Ce n'est pas un modèle GORM direct.
 // Un Click event a un LinkID(uint), un Timestamp (Time.Time), un UserAgent (string) et un IP (stringà
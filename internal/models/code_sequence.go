@@ -0,0 +1,8 @@
+package models
+
+// CodeSequence est un compteur persistant utilisé par la génération séquentielle des codes courts.
+// Il est partagé par le serveur et la CLI : chaque valeur n'est distribuée qu'une seule fois.
+type CodeSequence struct {
+	Name  string `gorm:"primaryKey;size:50"`
+	Value uint64 `gorm:"not null"`
+}
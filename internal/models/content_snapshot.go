@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// ContentSnapshot est l'empreinte du contenu de la destination d'un lien surveillé (WatchContent). Une empreinte
+// n'est enregistrée qu'à la première vérification puis à chaque changement de contenu : les lignes d'un lien
+// forment l'historique de ses changements.
+type ContentSnapshot struct {
+	ID          uint   `gorm:"primaryKey"`
+	LinkID      uint   `gorm:"not null;index"`
+	Hash        string `gorm:"size:64;not null"`       // SHA-256 du texte normalisé
+	SimHash     string `gorm:"size:16;not null"`       // Empreinte de similarité sur 64 bits, en hexadécimal
+	Words       int    `gorm:"not null;default:0"`     // Nombre de mots du texte normalisé
+	Distance    int    `gorm:"not null;default:0"`     // Bits SimHash différents de l'empreinte précédente, 0 pour la première
+	Significant bool   `gorm:"not null;default:false"` // Changement significatif, notifié
+	CreatedAt   time.Time
+}
@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// DefaultDomainID désigne le domaine par défaut (server.base_url), qui n'a pas d'entrée dans la table domains.
+// Les liens créés sans domaine, ainsi que ceux antérieurs à l'introduction des domaines, lui sont rattachés.
+const DefaultDomainID uint = 0
+
+// Domain est un domaine court personnalisé (ex: une marque) servant ses propres liens.
+// Chaque domaine dispose de son propre espace de codes courts.
+type Domain struct {
+	ID          uint   `gorm:"primaryKey"`
+	WorkspaceID uint   `gorm:"not null;default:0;index"`      // Espace de travail dont les liens peuvent utiliser ce domaine
+	Host        string `gorm:"uniqueIndex;size:255;not null"` // Hôte servi, en minuscules (ex: "sho.rt" ou "sho.rt:8080"), comparé à l'en-tête Host
+	BaseURL     string `gorm:"size:300;not null"`             // URL de base (ex: "https://sho.rt"), utilisée pour construire les URLs courtes complètes
+	CreatedAt   time.Time
+}
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// IdempotencyKey associe une clé fournie par un client (en-tête Idempotency-Key) au lien qu'elle a créé.
+// Un client qui rejoue sa requête avec la même clé obtient le même lien au lieu d'un doublon.
+type IdempotencyKey struct {
+	ID          uint   `gorm:"primaryKey"`
+	WorkspaceID uint   `gorm:"not null;default:0;uniqueIndex:idx_idempotency_keys_workspace_key,priority:1"` // Chaque espace de travail a ses propres clés
+	Key         string `gorm:"uniqueIndex:idx_idempotency_keys_workspace_key,priority:2;size:255;not null"`
+	RequestHash string `gorm:"size:64;not null"` // Empreinte de la requête, pour détecter la réutilisation d'une clé pour une autre requête
+	LinkID      uint   `gorm:"index"`
+	CreatedAt   time.Time
+}
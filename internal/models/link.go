@@ -12,11 +12,60 @@ import (
 	"time"
 )
 
+// Types de liens.
+const (
+	LinkKindStandard = "standard" // Le code court seul redirige vers la destination
+	LinkKindPrefix   = "prefix"   // Le code court est un préfixe : /{code}/reste/du/chemin ajoute le reste à la destination
+)
+
+// États d'un lien vis-à-vis de sa fenêtre d'activité (ActiveFrom, ActiveUntil).
+const (
+	LinkStatusScheduled = "scheduled" // La fenêtre n'est pas encore ouverte
+	LinkStatusActive    = "active"    // Le lien redirige (fenêtre ouverte, ou pas de fenêtre)
+	LinkStatusEnded     = "ended"     // La fenêtre est refermée
+)
+
 type Link struct {
-	ID        uint   `gorm:"primaryKey"`
-	LongURL   string `gorm:"not null"`
-	ShortCode string `gorm:"uniqueIndex;size:6"`
-	CreatedAt time.Time
-	UpdatedAt time.Time
-	Clicks    []Click `gorm:"foreignKey:LinkID"`
+	ID                     uint   `gorm:"primaryKey"`
+	LongURL                string `gorm:"not null"`
+	WorkspaceID            uint   `gorm:"not null;default:0;index"`                                              // Espace de travail du lien, DefaultWorkspaceID par défaut
+	DomainID               uint   `gorm:"not null;default:0;uniqueIndex:idx_links_domain_short_code,priority:1"` // Domaine du lien, DefaultDomainID pour server.base_url
+	ShortCode              string `gorm:"uniqueIndex:idx_links_domain_short_code,priority:2;size:10"`            // Unique au sein de son domaine
+	Owner                  string `gorm:"index:idx_links_owner_url_hash,priority:1;size:100"`                    // Propriétaire du lien (équipe, client...), optionnel
+	URLHash                string `gorm:"index:idx_links_owner_url_hash,priority:2;size:64"`                     // SHA-256 de l'URL longue normalisée, pour la déduplication
+	Metadata               string `gorm:"type:text"`                                                             // Données libres associées au lien, encodées en JSON
+	Title                  string `gorm:"size:200"`                                                              // Titre du lien, pour le retrouver (recherche, aperçu)
+	Description            string `gorm:"type:text"`                                                             // Notes libres sur le lien
+	CreatedAt              time.Time
+	UpdatedAt              time.Time
+	ActiveFrom             *time.Time
+	ActiveUntil            *time.Time
+	PageFetchedAt          *time.Time
+	ImportedClicks         int     `gorm:"not null;default:0"`     // Clics historiques repris d'un autre raccourcisseur lors d'un import
+	Disabled               bool    `gorm:"not null;default:false"` // Lien désactivé (ex: destination ajoutée à une liste de blocage), ne redirige plus
+	DisabledReason         string  `gorm:"size:255"`               // Raison de la désactivation
+	PasswordHash           string  `gorm:"size:100"`               // Empreinte bcrypt du mot de passe d'accès, vide si le lien n'est pas protégé
+	FailedPasswordAttempts int     `gorm:"not null;default:0"`     // Nombre de mots de passe erronés saisis pour ce lien
+	RedirectRules          string  `gorm:"type:text"`              // Règles de redirection ordonnées (targeting.Rule), encodées en JSON
+	AlwaysPreview          bool    `gorm:"not null;default:false"` // Affiche toujours la page d'aperçu avant de rediriger (destinations peu fiables)
+	SplitVariants          string  `gorm:"type:text"`              // Variantes A/B pondérées (experiment.Variant), encodées en JSON ; remplacent l'URL longue
+	QueryForwarding        string  `gorm:"size:10"`                // Transmission des paramètres du lien court à la destination : "", "merge" ou "override"
+	UTMSource              string  `gorm:"size:100"`               // Modèle utm_source ajouté à la destination, vide pour aucun
+	UTMMedium              string  `gorm:"size:100"`               // Modèle utm_medium ajouté à la destination, vide pour aucun
+	UTMCampaign            string  `gorm:"size:100"`               // Modèle utm_campaign ajouté à la destination, vide pour aucun
+	RedirectStatus         int     `gorm:"not null;default:302"`   // Code HTTP de la redirection : 301, 302, 307 ou 308
+	CacheControl           string  `gorm:"size:100"`               // En-tête Cache-Control de la redirection, aucun si vide
+	Kind                   string  `gorm:"default:standard"`       // Type de lien : LinkKindStandard ou LinkKindPrefix
+	MaxViews               int     `gorm:"not null;default:0"`     // Nombre de redirections autorisées (lien à usage limité), illimité si 0
+	Views                  int     `gorm:"not null;default:0"`     // Redirections déjà consommées sur MaxViews
+	SelfDestruct           bool    `gorm:"not null;default:false"` // Efface la destination une fois la dernière redirection consommée
+	InactiveURL            string  `gorm:"type:text"`              // Servie hors de la fenêtre d'activité [ActiveFrom, ActiveUntil[ (UTC, sans borne si nil), sinon page d'indisponibilité
+	PageTitle              string  `gorm:"size:300"`               // Titre de la page de destination (og:title, sinon <title>), récupéré en tâche de fond
+	PageDescription        string  `gorm:"type:text"`              // Description de la page de destination (og:description, sinon meta description)
+	PageImage              string  `gorm:"type:text"`              // Image de la page de destination (og:image), URL absolue
+	PageFavicon            string  `gorm:"type:text"`              // Icône de la page de destination, URL absolue
+	WatchContent           bool    `gorm:"not null;default:false"` // Surveille les changements du contenu de la destination (table content_snapshots)
+	WatchSelector          string  `gorm:"size:200"`               // Sélecteur CSS du contenu surveillé (snapshot.Selector), toute la page si vide
+	Tags                   []Tag   `gorm:"-"`                      // Étiquettes (table link_tags) : enregistrées avec le lien, chargées par LoadLinkTags
+	Clicks                 []Click `gorm:"foreignKey:LinkID"`
 }
@@ -0,0 +1,17 @@
+package models
+
+// All liste tous les modèles persistés par l'application, dans l'ordre de création des tables.
+// Les tables référencées par une clé étrangère doivent apparaître avant celles qui les référencent :
+// cet ordre est utilisé par les migrations ainsi que par la sauvegarde et la restauration.
+var All = []interface{}{
+	&Workspace{},
+	&APIKey{},
+	&Domain{},
+	&Link{},
+	&Tag{},
+	&LinkTag{},
+	&Click{},
+	&ContentSnapshot{},
+	&IdempotencyKey{},
+	&CodeSequence{},
+}
@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// Tag est une étiquette libre (ex: "campagne-2024", "support") pouvant être associée à plusieurs liens.
+type Tag struct {
+	ID        uint   `gorm:"primaryKey"`
+	Name      string `gorm:"uniqueIndex;size:50;not null"` // En minuscules
+	CreatedAt time.Time
+}
+
+// LinkTag associe un lien à une étiquette (table de jointure entre links et tags).
+type LinkTag struct {
+	ID     uint `gorm:"primaryKey"`
+	LinkID uint `gorm:"not null;uniqueIndex:idx_link_tags_link_tag,priority:1"`
+	TagID  uint `gorm:"not null;uniqueIndex:idx_link_tags_link_tag,priority:2;index"`
+}
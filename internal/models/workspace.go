@@ -0,0 +1,36 @@
+package models
+
+import "time"
+
+// DefaultWorkspaceID désigne l'espace de travail par défaut, qui n'a pas d'entrée dans la table workspaces.
+// Les liens, clics et domaines antérieurs à l'introduction des espaces de travail lui sont rattachés, ainsi que
+// les requêtes de l'API faites sans clé d'API. Il n'a pas de quota.
+const DefaultWorkspaceID uint = 0
+
+// DefaultWorkspaceName est le nom réservé de l'espace de travail par défaut.
+const DefaultWorkspaceName = "default"
+
+// Workspace est un espace de travail (ex: une équipe) : ses liens, leurs clics, ses domaines et ses clés d'API
+// sont isolés de ceux des autres espaces.
+type Workspace struct {
+	ID                uint   `gorm:"primaryKey"`
+	Name              string `gorm:"uniqueIndex;size:50;not null"`
+	MaxLinks          int    `gorm:"not null;default:0"` // Nombre maximal de liens, illimité si 0
+	MaxClicksPerMonth int    `gorm:"not null;default:0"` // Clics par mois calendaire (UTC) au-delà desquels les liens ne redirigent plus, illimité si 0
+	CreatedAt         time.Time
+}
+
+// DefaultWorkspace retourne l'espace de travail par défaut.
+func DefaultWorkspace() *Workspace {
+	return &Workspace{ID: DefaultWorkspaceID, Name: DefaultWorkspaceName}
+}
+
+// APIKey est une clé d'accès à l'API, rattachée à un espace de travail.
+// Seule l'empreinte de la clé est enregistrée : la clé elle-même n'est affichée qu'à sa création.
+type APIKey struct {
+	ID          uint   `gorm:"primaryKey"`
+	WorkspaceID uint   `gorm:"not null;index"`
+	Name        string `gorm:"size:100"`                     // Libellé libre (ex: "intégration CRM")
+	KeyHash     string `gorm:"uniqueIndex;size:64;not null"` // SHA-256 (hexadécimal) de la clé
+	CreatedAt   time.Time
+}
@@ -0,0 +1,120 @@
+package monitor
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/experiment"
+	"github.com/axellelanca/urlshortener/internal/models"
+	"github.com/axellelanca/urlshortener/internal/repository"
+	"github.com/axellelanca/urlshortener/internal/screening"
+	"github.com/axellelanca/urlshortener/internal/targeting"
+)
+
+// BlocklistScanner re-vérifie périodiquement les liens existants contre les listes de blocage :
+// un lien dont la destination a été ajoutée à une liste après sa création est désactivé.
+type BlocklistScanner struct {
+	linkRepo  repository.LinkRepository
+	blocklist *screening.Blocklist
+	interval  time.Duration
+	notifier  Notifier
+}
+
+// NewBlocklistScanner crée et retourne une nouvelle instance de BlocklistScanner.
+func NewBlocklistScanner(linkRepo repository.LinkRepository, blocklist *screening.Blocklist, interval time.Duration, notifier Notifier) *BlocklistScanner {
+	return &BlocklistScanner{
+		linkRepo:  linkRepo,
+		blocklist: blocklist,
+		interval:  interval,
+		notifier:  notifier,
+	}
+}
+
+// Start lance la boucle de re-vérification périodique.
+// Cette fonction est conçue pour être lancée dans une goroutine séparée.
+func (s *BlocklistScanner) Start() {
+	log.Printf("[BLOCKLIST] Démarrage de la re-vérification des liens avec un intervalle de %v...", s.interval)
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	s.scan()
+	for range ticker.C {
+		s.scan()
+	}
+}
+
+// blockedLink associe un lien à la règle de blocage qui le concerne.
+type blockedLink struct {
+	link   models.Link
+	reason string
+}
+
+// scan recharge les listes modifiées sur le disque puis désactive les liens actifs dont la destination est bloquée.
+func (s *BlocklistScanner) scan() {
+	if _, err := s.blocklist.Reload(); err != nil {
+		log.Printf("[BLOCKLIST] Échec du rechargement, la liste précédente est conservée : %v", err)
+	}
+
+	// Les liens à désactiver sont collectés pendant le parcours puis mis à jour ensuite,
+	// pour ne pas écrire dans la table pendant sa lecture par lots.
+	var blocked []blockedLink
+	err := s.linkRepo.StreamLinks(repository.LinkFilter{}, func(link *models.Link) error {
+		// Un lien à usage limité épuisé ne redirige plus (sa destination a pu être effacée)
+		if link.Disabled || (link.MaxViews > 0 && link.Views >= link.MaxViews) {
+			return nil
+		}
+		if err := s.checkLink(link); err != nil {
+			blocked = append(blocked, blockedLink{link: *link, reason: err.Error()})
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("[BLOCKLIST] Erreur lors du parcours des liens : %v", err)
+		return
+	}
+
+	for _, b := range blocked {
+		if err := s.linkRepo.DisableLink(b.link.ID, b.reason); err != nil {
+			log.Printf("[BLOCKLIST] Erreur lors de la désactivation du lien %s : %v", b.link.ShortCode, err)
+			continue
+		}
+		s.notifier.Notify(fmt.Sprintf("Le lien %s (%s) a été désactivé : %s",
+			b.link.ShortCode, b.link.LongURL, b.reason))
+	}
+	log.Printf("[BLOCKLIST] Re-vérification terminée : %d lien(s) désactivé(s).", len(blocked))
+}
+
+// checkLink vérifie l'URL longue d'un lien, son URL servie hors de sa fenêtre d'activité, ainsi que les cibles de
+// ses règles de redirection et de ses variantes A/B.
+func (s *BlocklistScanner) checkLink(link *models.Link) error {
+	if err := s.blocklist.Check(link.LongURL); err != nil {
+		return err
+	}
+	if link.InactiveURL != "" {
+		if err := s.blocklist.Check(link.InactiveURL); err != nil {
+			return fmt.Errorf("inactive URL: %w", err)
+		}
+	}
+	rules, err := targeting.Decode(link.RedirectRules)
+	if err != nil {
+		log.Printf("[BLOCKLIST] Règles de redirection illisibles pour le lien %s : %v", link.ShortCode, err)
+		return nil
+	}
+	for i, rule := range rules {
+		if err := s.blocklist.Check(rule.Target); err != nil {
+			return fmt.Errorf("rule %s target: %w", targeting.Label(rules, i), err)
+		}
+	}
+	variants, err := experiment.Decode(link.SplitVariants)
+	if err != nil {
+		log.Printf("[BLOCKLIST] Variantes A/B illisibles pour le lien %s : %v", link.ShortCode, err)
+		return nil
+	}
+	for _, variant := range variants {
+		if err := s.blocklist.Check(variant.Target); err != nil {
+			return fmt.Errorf("variant %s target: %w", variant.Name, err)
+		}
+	}
+	return nil
+}
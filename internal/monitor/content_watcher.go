@@ -0,0 +1,143 @@
+package monitor
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+	"github.com/axellelanca/urlshortener/internal/pageinfo"
+	"github.com/axellelanca/urlshortener/internal/repository"
+	"github.com/axellelanca/urlshortener/internal/snapshot"
+)
+
+// ContentWatcher relève périodiquement l'empreinte du contenu de la destination des liens surveillés (WatchContent)
+// et notifie les changements significatifs, par exemple une fiche produit remplacée par la page d'accueil du site.
+// Les empreintes successives sont enregistrées dans la table content_snapshots.
+type ContentWatcher struct {
+	linkRepo  repository.LinkRepository
+	client    *http.Client
+	maxBytes  int64
+	threshold int
+	interval  time.Duration
+	notifier  Notifier
+}
+
+// NewContentWatcher crée et retourne une nouvelle instance de ContentWatcher. client porte les réglages des requêtes
+// (NewHTTPClient, ou le client d'un serveur de test) ; maxBytes limite la taille lue par page ; threshold est le nombre
+// de bits SimHash différents à partir duquel un changement est notifié (snapshot.DefaultThreshold si <= 0).
+func NewContentWatcher(linkRepo repository.LinkRepository, client *http.Client, maxBytes int64, threshold int, interval time.Duration, notifier Notifier) *ContentWatcher {
+	return &ContentWatcher{
+		linkRepo:  linkRepo,
+		client:    client,
+		maxBytes:  maxBytes,
+		threshold: threshold,
+		interval:  interval,
+		notifier:  notifier,
+	}
+}
+
+// Start lance la boucle de vérification périodique.
+// Cette fonction est conçue pour être lancée dans une goroutine séparée.
+func (w *ContentWatcher) Start() {
+	log.Printf("[CONTENT] Démarrage de la surveillance du contenu des destinations avec un intervalle de %v...", w.interval)
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	w.checkAll()
+	for range ticker.C {
+		w.checkAll()
+	}
+}
+
+// checkAll relève le contenu de la destination de chaque lien surveillé.
+func (w *ContentWatcher) checkAll() {
+	// Les liens sont collectés pendant le parcours puis vérifiés ensuite,
+	// pour ne pas garder la lecture par lots ouverte pendant les requêtes HTTP.
+	var watched []models.Link
+	err := w.linkRepo.StreamLinks(repository.LinkFilter{}, func(link *models.Link) error {
+		// Un lien à usage limité épuisé ne redirige plus (sa destination a pu être effacée)
+		if link.WatchContent && !link.Disabled && !(link.MaxViews > 0 && link.Views >= link.MaxViews) {
+			watched = append(watched, *link)
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("[CONTENT] Erreur lors du parcours des liens : %v", err)
+		return
+	}
+
+	changed := 0
+	for i := range watched {
+		if w.check(&watched[i]) {
+			changed++
+		}
+	}
+	log.Printf("[CONTENT] Vérification terminée : %d lien(s) surveillé(s), %d changement(s) de contenu.", len(watched), changed)
+}
+
+// check relève l'empreinte du contenu de la destination d'un lien et l'enregistre si elle a changé depuis la
+// précédente. Il retourne true si un changement a été enregistré. Une destination injoignable est ignorée :
+// son accessibilité relève de l'UrlMonitor.
+func (w *ContentWatcher) check(link *models.Link) bool {
+	sel, err := snapshot.ParseSelector(link.WatchSelector)
+	if err != nil {
+		log.Printf("[CONTENT] Sélecteur invalide pour le lien %s : %v", link.ShortCode, err)
+		return false
+	}
+
+	page, err := pageinfo.Open(w.client, link.LongURL, w.maxBytes)
+	if err != nil {
+		log.Printf("[CONTENT] Contenu indisponible pour le lien %s : %v", link.ShortCode, err)
+		return false
+	}
+	current, err := snapshot.Take(page.Body, sel)
+	page.Close()
+	if err != nil {
+		log.Printf("[CONTENT] Contenu illisible pour le lien %s : %v", link.ShortCode, err)
+		return false
+	}
+
+	record := &models.ContentSnapshot{
+		LinkID:    link.ID,
+		Hash:      current.Hash,
+		SimHash:   snapshot.FormatSimHash(current.SimHash),
+		Words:     current.Words,
+		CreatedAt: time.Now(),
+	}
+
+	latest, err := w.linkRepo.LatestContentSnapshot(link.ID)
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		// Première vérification : l'empreinte sert de référence, sans notification
+	case err != nil:
+		log.Printf("[CONTENT] Erreur lors de la récupération de la dernière empreinte du lien %s : %v", link.ShortCode, err)
+		return false
+	case latest.Hash == current.Hash:
+		return false
+	default:
+		previous := snapshot.Fingerprint{Hash: latest.Hash, Words: latest.Words}
+		if previous.SimHash, err = snapshot.ParseSimHash(latest.SimHash); err != nil {
+			log.Printf("[CONTENT] Empreinte précédente illisible pour le lien %s : %v", link.ShortCode, err)
+		}
+		record.Distance, record.Significant = snapshot.Compare(previous, current, w.threshold)
+	}
+
+	if err := w.linkRepo.CreateContentSnapshot(record); err != nil {
+		log.Printf("[CONTENT] Erreur lors de l'enregistrement de l'empreinte du lien %s : %v", link.ShortCode, err)
+		return false
+	}
+	if latest == nil {
+		log.Printf("[CONTENT] Empreinte initiale pour le lien %s (%s) : %d mot(s).", link.ShortCode, link.LongURL, current.Words)
+		return false
+	}
+	if record.Significant {
+		w.notifier.Notify(fmt.Sprintf("Le contenu de la destination du lien %s (%s) a changé de façon significative (%d/64 bits, %d mot(s) contre %d) !",
+			link.ShortCode, link.LongURL, record.Distance, current.Words, latest.Words))
+	}
+	return true
+}
@@ -0,0 +1,17 @@
+package monitor
+
+import "log"
+
+// Notifier transmet les notifications émises par les processus de surveillance
+// (changement d'accessibilité d'une URL, lien désactivé...).
+type Notifier interface {
+	Notify(message string)
+}
+
+// LogNotifier est le Notifier par défaut : il écrit les notifications dans les logs.
+type LogNotifier struct{}
+
+// Notify écrit la notification dans les logs, préfixée par [NOTIFICATION].
+func (LogNotifier) Notify(message string) {
+	log.Printf("[NOTIFICATION] %s", message)
+}
@@ -0,0 +1,124 @@
+package monitor
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+	"github.com/axellelanca/urlshortener/internal/pageinfo"
+	"github.com/axellelanca/urlshortener/internal/repository"
+)
+
+// pageInfoQueueSize est le nombre de liens en attente de récupération au-delà duquel Enqueue les ignore :
+// ils sont alors repris par le balayage périodique suivant.
+const pageInfoQueueSize = 1000
+
+// PageInfoFetcher récupère en tâche de fond les métadonnées de la page de destination des liens (titre,
+// balises OpenGraph, icône) et les enregistre sur le lien. Les liens créés lui sont confiés par Enqueue ;
+// un balayage périodique reprend ceux qui n'ont pas encore été traités (créés en ligne de commande, importés,
+// ou ignorés par une file pleine). Chaque lien n'est récupéré qu'une fois, que la récupération aboutisse ou non.
+type PageInfoFetcher struct {
+	linkRepo repository.LinkRepository
+	client   *http.Client
+	maxBytes int64
+	interval time.Duration
+	queue    chan uint
+}
+
+// NewPageInfoFetcher crée et retourne une nouvelle instance de PageInfoFetcher. client porte les réglages des
+// requêtes (NewHTTPClient, ou le client d'un serveur de test) ; maxBytes limite la taille lue par page
+// (pageinfo.DefaultMaxBytes si <= 0).
+func NewPageInfoFetcher(linkRepo repository.LinkRepository, client *http.Client, maxBytes int64, interval time.Duration) *PageInfoFetcher {
+	return &PageInfoFetcher{
+		linkRepo: linkRepo,
+		client:   client,
+		maxBytes: maxBytes,
+		interval: interval,
+		queue:    make(chan uint, pageInfoQueueSize),
+	}
+}
+
+// Enqueue demande la récupération des métadonnées d'un lien, sans bloquer l'appelant.
+func (f *PageInfoFetcher) Enqueue(linkID uint) {
+	select {
+	case f.queue <- linkID:
+	default:
+		log.Printf("[PAGEINFO] File pleine, le lien %d sera repris au prochain balayage.", linkID)
+	}
+}
+
+// Start lance le balayage périodique puis traite les liens en attente un par un.
+// Cette fonction est conçue pour être lancée dans une goroutine séparée.
+func (f *PageInfoFetcher) Start() {
+	log.Printf("[PAGEINFO] Démarrage de la récupération des métadonnées avec un intervalle de balayage de %v...", f.interval)
+	go func() {
+		ticker := time.NewTicker(f.interval)
+		defer ticker.Stop()
+
+		f.sweep()
+		for range ticker.C {
+			f.sweep()
+		}
+	}()
+
+	for linkID := range f.queue {
+		f.fetch(linkID)
+	}
+}
+
+// sweep met en attente les liens dont les métadonnées n'ont pas encore été récupérées.
+func (f *PageInfoFetcher) sweep() {
+	// Les identifiants sont collectés pendant le parcours puis mis en attente ensuite,
+	// pour ne pas bloquer la lecture par lots sur une file pleine.
+	var pending []uint
+	err := f.linkRepo.StreamLinks(repository.LinkFilter{}, func(link *models.Link) error {
+		if link.PageFetchedAt == nil && pageInfoWanted(link) {
+			pending = append(pending, link.ID)
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("[PAGEINFO] Erreur lors du parcours des liens : %v", err)
+		return
+	}
+	if len(pending) > 0 {
+		log.Printf("[PAGEINFO] %d lien(s) en attente de métadonnées.", len(pending))
+	}
+	for _, linkID := range pending {
+		f.queue <- linkID
+	}
+}
+
+// fetch récupère et enregistre les métadonnées de la page de destination d'un lien. Un lien déjà traité
+// (mis en attente deux fois) ou devenu inéligible entre-temps est ignoré.
+func (f *PageInfoFetcher) fetch(linkID uint) {
+	link, err := f.linkRepo.GetLinkByID(linkID)
+	if err != nil {
+		log.Printf("[PAGEINFO] Erreur lors de la récupération du lien %d : %v", linkID, err)
+		return
+	}
+	if link.PageFetchedAt != nil || !pageInfoWanted(link) {
+		return
+	}
+
+	info, err := pageinfo.Fetch(f.client, link.LongURL, f.maxBytes)
+	if err != nil {
+		log.Printf("[PAGEINFO] Métadonnées indisponibles pour le lien %s : %v", link.ShortCode, err)
+	}
+	now := time.Now()
+	link.PageTitle = info.Title
+	link.PageDescription = info.Description
+	link.PageImage = info.Image
+	link.PageFavicon = info.Favicon
+	link.PageFetchedAt = &now
+	if err := f.linkRepo.SavePageInfo(link); err != nil {
+		log.Printf("[PAGEINFO] Erreur lors de l'enregistrement des métadonnées du lien %s : %v", link.ShortCode, err)
+	}
+}
+
+// pageInfoWanted indique si les métadonnées de la destination d'un lien doivent être récupérées : un lien désactivé
+// n'est plus servi, et la destination d'un lien à usage limité ne doit être révélée que par une redirection consommée.
+func pageInfoWanted(link *models.Link) bool {
+	return !link.Disabled && link.MaxViews == 0 && link.LongURL != ""
+}
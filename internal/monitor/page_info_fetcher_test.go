@@ -0,0 +1,100 @@
+package monitor
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+	"github.com/axellelanca/urlshortener/internal/repository"
+	"github.com/axellelanca/urlshortener/internal/testutil"
+)
+
+// newTestLinkRepository retourne le dépôt de liens d'une base de test.
+func newTestLinkRepository(t *testing.T) *repository.GormLinkRepository {
+	t.Helper()
+	return repository.NewLinkRepository(testutil.NewDB(t))
+}
+
+func TestPageInfoFetcherSavesMetadata(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, `<head><title>Soldes d'hiver</title><meta property="og:image" content="/cover.png"></head>`)
+	}))
+	defer srv.Close()
+
+	linkRepo := newTestLinkRepository(t)
+	link := &models.Link{ShortCode: "soldes", LongURL: srv.URL + "/soldes"}
+	if err := linkRepo.CreateLink(link); err != nil {
+		t.Fatalf("création du lien : %v", err)
+	}
+
+	fetcher := NewPageInfoFetcher(linkRepo, srv.Client(), 0, time.Hour)
+	fetcher.fetch(link.ID)
+
+	saved, err := linkRepo.GetLinkByID(link.ID)
+	if err != nil {
+		t.Fatalf("lecture du lien : %v", err)
+	}
+	if saved.PageFetchedAt == nil {
+		t.Fatal("PageFetchedAt doit être renseigné après la récupération")
+	}
+	if saved.PageTitle != "Soldes d'hiver" || saved.PageImage != srv.URL+"/cover.png" {
+		t.Errorf("métadonnées enregistrées : titre %q, image %q", saved.PageTitle, saved.PageImage)
+	}
+}
+
+func TestPageInfoFetcherRefusesPrivateAddresses(t *testing.T) {
+	requested := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = true
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, "<title>Page interne</title>")
+	}))
+	defer srv.Close()
+
+	linkRepo := newTestLinkRepository(t)
+	link := &models.Link{ShortCode: "interne", LongURL: srv.URL}
+	if err := linkRepo.CreateLink(link); err != nil {
+		t.Fatalf("création du lien : %v", err)
+	}
+
+	fetcher := NewPageInfoFetcher(linkRepo, NewPublicHTTPClient(), 0, time.Hour)
+	fetcher.fetch(link.ID)
+
+	saved, err := linkRepo.GetLinkByID(link.ID)
+	if err != nil {
+		t.Fatalf("lecture du lien : %v", err)
+	}
+	if requested {
+		t.Error("le serveur local ne doit pas être contacté")
+	}
+	if saved.PageTitle != "" {
+		t.Errorf("PageTitle = %q : aucune métadonnée ne doit être lue sur une adresse privée", saved.PageTitle)
+	}
+	if saved.PageFetchedAt == nil {
+		t.Error("le lien doit être marqué comme traité pour ne pas être repris à chaque balayage")
+	}
+}
+
+func TestPageInfoFetcherSkipsViewLimitedLinks(t *testing.T) {
+	requested := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = true
+	}))
+	defer srv.Close()
+
+	linkRepo := newTestLinkRepository(t)
+	link := &models.Link{ShortCode: "secret", LongURL: srv.URL, MaxViews: 1}
+	if err := linkRepo.CreateLink(link); err != nil {
+		t.Fatalf("création du lien : %v", err)
+	}
+
+	NewPageInfoFetcher(linkRepo, srv.Client(), 0, time.Hour).fetch(link.ID)
+
+	if requested {
+		t.Error("la destination d'un lien à usage limité ne doit pas être récupérée")
+	}
+}
@@ -1,21 +1,71 @@
 package monitor
 
 import (
+	"errors"
+	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"sync" // Pour protéger l'accès concurrentiel à knownStates
+	"syscall"
 	"time"
 
 	_ "github.com/axellelanca/urlshortener/internal/models"   // Importe les modèles de liens
 	"github.com/axellelanca/urlshortener/internal/repository" // Importe le repository de liens
+	"github.com/axellelanca/urlshortener/internal/validation"
 )
 
 // UrlMonitor gère la surveillance périodique des URLs longues.
 type UrlMonitor struct {
 	linkRepo    repository.LinkRepository // Pour récupérer les URLs à surveiller
 	interval    time.Duration             // Intervalle entre chaque vérification (ex: 5 minutes)
-	knownStates map[uint]bool             // État connu de chaque URL: map[LinkID]estAccessible (true/false)
+	knownStates map[uint]LinkHealth       // État connu de chaque URL: map[LinkID]LinkHealth
 	mu          sync.Mutex                // Mutex pour protéger l'accès concurrentiel à knownStates
+	notifier    Notifier                  // Destinataire des notifications de changement d'état
+	client      *http.Client              // Client HTTP des vérifications, voir NewHTTPClient
+}
+
+// RequestTimeout est le délai accordé à chaque requête vers la destination d'un lien.
+const RequestTimeout = 5 * time.Second
+
+// NewHTTPClient retourne un client HTTP configuré pour interroger les destinations des liens
+// (vérification d'accessibilité, récupération des métadonnées de la page).
+func NewHTTPClient() *http.Client {
+	return &http.Client{
+		Timeout: RequestTimeout,
+	}
+}
+
+// ErrPrivateAddress signale une connexion refusée vers une adresse privée, loopback ou link-local.
+var ErrPrivateAddress = errors.New("connection to a private address refused")
+
+// NewPublicHTTPClient retourne un client HTTP comme NewHTTPClient, qui refuse de se connecter à une adresse privée,
+// loopback ou link-local (ErrPrivateAddress). Il sert à lire le contenu des destinations, fournies par les
+// utilisateurs : sans lui, une destination pointant vers le réseau interne en exposerait les pages.
+// L'adresse est vérifiée au moment de la connexion, une fois le nom d'hôte résolu : la vérification s'applique à
+// chaque redirection suivie et ne peut pas être contournée par un nom d'hôte qui pointe vers le réseau interne.
+// Le proxy éventuel de l'environnement est ignoré, la connexion devant être établie directement avec la destination.
+func NewPublicHTTPClient() *http.Client {
+	dialer := &net.Dialer{
+		Timeout: RequestTimeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || validation.IsPrivateIP(ip) {
+				return fmt.Errorf("%w: %s", ErrPrivateAddress, host)
+			}
+			return nil
+		},
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{
+		Timeout:   RequestTimeout,
+		Transport: transport,
+	}
 }
 
 // TODO finir cette fonction
@@ -25,10 +75,30 @@ func NewUrlMonitor(linkRepo repository.LinkRepository, interval time.Duration) *
 	return &UrlMonitor{
 		linkRepo:    linkRepo,
 		interval:    interval,
-		knownStates: make(map[uint]bool),
+		knownStates: make(map[uint]LinkHealth),
+		notifier:    LogNotifier{},
+		client:      NewHTTPClient(),
 	}
 }
 
+// LinkHealth est le dernier état connu de la destination d'un lien.
+type LinkHealth struct {
+	Accessible bool
+	CheckedAt  time.Time
+}
+
+// LinkHealth retourne le dernier état connu de la destination d'un lien, et false si elle n'a pas encore été vérifiée
+// (ou si le moniteur est nil).
+func (m *UrlMonitor) LinkHealth(linkID uint) (LinkHealth, bool) {
+	if m == nil {
+		return LinkHealth{}, false
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	health, ok := m.knownStates[linkID]
+	return health, ok
+}
+
 // Start lance la boucle de surveillance périodique des URLs.
 // Cette fonction est conçue pour être lancée dans une goroutine séparée.
 func (m *UrlMonitor) Start() {
@@ -59,14 +129,20 @@ func (m *UrlMonitor) checkUrls() {
 	}
 
 	for _, link := range links {
+		// Un lien à usage limité épuisé ne redirige plus (sa destination a pu être effacée)
+		if link.MaxViews > 0 && link.Views >= link.MaxViews {
+			continue
+		}
 		// TODO : Pour chaque lien, vérifier son accessibilité (isUrlAccessible).
 		currentState := m.isUrlAccessible(link.LongURL)
 
 		// Protéger l'accès à la map 'knownStates' car 'checkUrls' peut être exécuté concurremment
 		m.mu.Lock()
-		previousState, exists := m.knownStates[link.ID] // Récupère l'état précédent
-		m.knownStates[link.ID] = currentState           // Met à jour l'état actuel
+		previous, exists := m.knownStates[link.ID] // Récupère l'état précédent
+		// Met à jour l'état actuel
+		m.knownStates[link.ID] = LinkHealth{Accessible: currentState, CheckedAt: time.Now()}
 		m.mu.Unlock()
+		previousState := previous.Accessible
 
 		// Si c'est la première vérification pour ce lien, on initialise l'état sans notifier.
 		if !exists {
@@ -79,8 +155,8 @@ func (m *UrlMonitor) checkUrls() {
 		// Si l'état a changé, générer une fausse notification dans les logs.
 		// log.Printf("[NOTIFICATION] Le lien %s (%s) est passé de %s à %s !"
 		if currentState != previousState {
-			log.Printf("[NOTIFICATION] Le lien %s (%s) est passé de %s à %s !",
-				link.ShortCode, link.LongURL, formatState(previousState), formatState(currentState))
+			m.notifier.Notify(fmt.Sprintf("Le lien %s (%s) est passé de %s à %s !",
+				link.ShortCode, link.LongURL, formatState(previousState), formatState(currentState)))
 		}
 
 	}
@@ -89,15 +165,10 @@ func (m *UrlMonitor) checkUrls() {
 
 // isUrlAccessible effectue une requête HTTP HEAD pour vérifier l'accessibilité d'une URL.
 func (m *UrlMonitor) isUrlAccessible(url string) bool {
-	// TODO Définir un timeout pour éviter de bloquer trop longtemps (5 secondes c'est bien)
-	client := http.Client{
-		Timeout: 5 * time.Second,
-	}
-
 	// TODO: Effectuer une requête HEAD (plus légère que GET) sur l'URL.
 	// Un code de statut 2xx ou 3xx indique que l'URL est accessible.
 	// Si err : log.Printf("[MONITOR] Erreur d'accès à l'URL '%s': %v", url, err)
-	resp, err := client.Head(url)
+	resp, err := m.client.Head(url)
 	if err != nil {
 		log.Printf("[MONITOR] Erreur d'accès à l'URL '%s': %v", url, err)
 		return false
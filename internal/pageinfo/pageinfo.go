@@ -0,0 +1,231 @@
+package pageinfo
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"golang.org/x/net/html/charset"
+)
+
+// Limites appliquées à la récupération et aux valeurs extraites.
+const (
+	DefaultMaxBytes      = 512 << 10 // Octets lus au plus dans le corps de la page : les métadonnées sont dans <head>
+	UserAgent            = "urlshortener-pageinfo/1.0"
+	maxTitleLength       = 300 // En caractères, comme la colonne page_title
+	maxDescriptionLength = 1000
+	maxURLLength         = 2048
+)
+
+// ErrNotHTML signale une destination qui n'est pas une page HTML (PDF, image...) : elle n'a pas de métadonnées à extraire.
+var ErrNotHTML = errors.New("destination is not an HTML page")
+
+// Info regroupe les métadonnées extraites d'une page. Les champs sont vides s'ils sont absents.
+type Info struct {
+	Title       string // og:title, sinon twitter:title, sinon <title>
+	Description string // og:description, sinon <meta name="description">
+	Image       string // og:image, URL absolue
+	Favicon     string // <link rel="icon">, sinon /favicon.ico sur l'origine de la page
+}
+
+// Page est une page HTML en cours de téléchargement. Elle doit être refermée par Close.
+type Page struct {
+	Body io.Reader // Corps de la page décodé en UTF-8, limité aux maxBytes premiers octets
+	URL  *url.URL  // URL finale de la page, après redirections
+	resp *http.Response
+}
+
+// Close libère la connexion de la page.
+func (p *Page) Close() error {
+	return p.resp.Body.Close()
+}
+
+// Fetch télécharge la page située à rawURL avec client, qui porte le délai d'expiration, et en extrait les métadonnées.
+// Seuls les maxBytes premiers octets du corps sont lus (DefaultMaxBytes si maxBytes <= 0). Les URLs relatives
+// sont résolues par rapport à l'URL finale de la page, après redirections.
+func Fetch(client *http.Client, rawURL string, maxBytes int64) (Info, error) {
+	page, err := Open(client, rawURL, maxBytes)
+	if err != nil {
+		return Info{}, err
+	}
+	defer page.Close()
+	return Parse(page.Body, page.URL), nil
+}
+
+// Open lance le téléchargement de la page HTML située à rawURL avec client. Une réponse autre que 2xx ou qui
+// n'est pas une page HTML (ErrNotHTML) est une erreur. Seuls les maxBytes premiers octets du corps sont lus
+// (DefaultMaxBytes si maxBytes <= 0), décodés en UTF-8 d'après l'en-tête Content-Type ou la balise <meta charset>.
+func Open(client *http.Client, rawURL string, maxBytes int64) (*Page, error) {
+	if maxBytes <= 0 {
+		maxBytes = DefaultMaxBytes
+	}
+	req, err := http.NewRequest(http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build request for %s: %w", rawURL, err)
+	}
+	req.Header.Set("User-Agent", UserAgent)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml;q=0.9,*/*;q=0.1")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", rawURL, err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		resp.Body.Close()
+		return nil, fmt.Errorf("failed to fetch %s: unexpected status %d", rawURL, resp.StatusCode)
+	}
+	contentType := resp.Header.Get("Content-Type")
+	if contentType != "" {
+		mediaType, _, _ := mime.ParseMediaType(contentType)
+		if mediaType != "text/html" && mediaType != "application/xhtml+xml" {
+			resp.Body.Close()
+			return nil, fmt.Errorf("%w: %s", ErrNotHTML, mediaType)
+		}
+	}
+
+	body, err := charset.NewReader(io.LimitReader(resp.Body, maxBytes), contentType)
+	if err != nil {
+		resp.Body.Close()
+		return nil, fmt.Errorf("failed to decode %s: %w", rawURL, err)
+	}
+	return &Page{Body: body, URL: resp.Request.URL, resp: resp}, nil
+}
+
+// Parse extrait les métadonnées de l'en-tête d'un document HTML. La lecture s'arrête à la fin de <head>
+// (ou au début de <body>) ; un document tronqué ou mal formé donne les métadonnées lues jusque-là.
+// base sert à résoudre les URLs relatives ; nil pour ne garder que les URLs absolues.
+func Parse(r io.Reader, base *url.URL) Info {
+	var title, ogTitle, twitterTitle, description, ogDescription, ogImage, icon, touchIcon string
+	inTitle := false
+
+	z := html.NewTokenizer(r)
+tokens:
+	for {
+		tt := z.Next()
+		switch tt {
+		case html.ErrorToken:
+			break tokens
+		case html.TextToken:
+			if inTitle {
+				title += string(z.Text())
+			}
+		case html.EndTagToken:
+			name, _ := z.TagName()
+			switch atom.Lookup(name) {
+			case atom.Title:
+				inTitle = false
+			case atom.Head:
+				break tokens
+			}
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, hasAttr := z.TagName()
+			switch atom.Lookup(name) {
+			case atom.Body:
+				break tokens
+			case atom.Title:
+				inTitle = tt == html.StartTagToken && title == ""
+			case atom.Meta:
+				attrs := attributes(z, hasAttr)
+				key := attrs["property"]
+				if key == "" {
+					key = attrs["name"]
+				}
+				content := attrs["content"]
+				switch strings.ToLower(key) {
+				case "og:title":
+					ogTitle = firstNonEmpty(ogTitle, content)
+				case "twitter:title":
+					twitterTitle = firstNonEmpty(twitterTitle, content)
+				case "og:description":
+					ogDescription = firstNonEmpty(ogDescription, content)
+				case "description":
+					description = firstNonEmpty(description, content)
+				case "og:image", "og:image:url", "og:image:secure_url":
+					ogImage = firstNonEmpty(ogImage, content)
+				}
+			case atom.Link:
+				attrs := attributes(z, hasAttr)
+				for _, rel := range strings.Fields(strings.ToLower(attrs["rel"])) {
+					switch rel {
+					case "icon":
+						icon = firstNonEmpty(icon, attrs["href"])
+					case "apple-touch-icon":
+						touchIcon = firstNonEmpty(touchIcon, attrs["href"])
+					}
+				}
+			}
+		}
+	}
+
+	info := Info{
+		Title:       cleanText(firstNonEmpty(ogTitle, twitterTitle, title), maxTitleLength),
+		Description: cleanText(firstNonEmpty(ogDescription, description), maxDescriptionLength),
+		Image:       resolveURL(base, ogImage),
+		Favicon:     resolveURL(base, firstNonEmpty(icon, touchIcon)),
+	}
+	if info.Favicon == "" {
+		info.Favicon = resolveURL(base, "/favicon.ico")
+	}
+	return info
+}
+
+// attributes retourne les attributs de la balise courante du tokenizer, noms en minuscules.
+func attributes(z *html.Tokenizer, hasAttr bool) map[string]string {
+	attrs := make(map[string]string)
+	for hasAttr {
+		var key, value []byte
+		key, value, hasAttr = z.TagAttr()
+		attrs[strings.ToLower(string(key))] = string(value)
+	}
+	return attrs
+}
+
+// firstNonEmpty retourne la première valeur non vide (espaces exclus), ou une chaîne vide.
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if strings.TrimSpace(value) != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// cleanText regroupe les espaces d'un texte et le tronque à maxLength caractères.
+func cleanText(text string, maxLength int) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if runes := []rune(text); len(runes) > maxLength {
+		text = strings.TrimSpace(string(runes[:maxLength-1])) + "…"
+	}
+	return text
+}
+
+// resolveURL résout ref par rapport à base et ne retourne que des URLs http(s) de longueur raisonnable
+// (les URLs data:, javascript:... sont écartées), ou une chaîne vide.
+func resolveURL(base *url.URL, ref string) string {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return ""
+	}
+	u, err := url.Parse(ref)
+	if err != nil {
+		return ""
+	}
+	if base != nil {
+		u = base.ResolveReference(u)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return ""
+	}
+	resolved := u.String()
+	if len(resolved) > maxURLLength {
+		return ""
+	}
+	return resolved
+}
@@ -0,0 +1,144 @@
+package pageinfo_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/axellelanca/urlshortener/internal/monitor"
+	"github.com/axellelanca/urlshortener/internal/pageinfo"
+)
+
+// newPageServer démarre un serveur de test qui sert body avec le Content-Type fourni sur toutes les URLs.
+func newPageServer(t *testing.T, contentType, body string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", contentType)
+		fmt.Fprint(w, body)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestFetchExtractsOpenGraph(t *testing.T) {
+	srv := newPageServer(t, "text/html; charset=utf-8", `<!doctype html>
+<html><head>
+  <title>Titre   de la page</title>
+  <meta property="og:title" content="Titre OpenGraph">
+  <meta name="description" content="Description simple">
+  <meta property="og:description" content="Description OpenGraph">
+  <meta property="og:image" content="/images/cover.png">
+  <link rel="shortcut icon" href="/static/icon.png">
+</head><body><meta property="og:title" content="Ignoré : dans le corps"></body></html>`)
+
+	info, err := pageinfo.Fetch(srv.Client(), srv.URL+"/article", 0)
+	if err != nil {
+		t.Fatalf("Fetch : erreur inattendue : %v", err)
+	}
+	want := pageinfo.Info{
+		Title:       "Titre OpenGraph",
+		Description: "Description OpenGraph",
+		Image:       srv.URL + "/images/cover.png",
+		Favicon:     srv.URL + "/static/icon.png",
+	}
+	if info != want {
+		t.Errorf("Fetch = %+v, attendu %+v", info, want)
+	}
+}
+
+func TestFetchFallsBackToTitle(t *testing.T) {
+	srv := newPageServer(t, "text/html", `<html><head><title>
+	Titre   sur
+	plusieurs lignes </title><meta name="description" content="Résumé"></head></html>`)
+
+	info, err := pageinfo.Fetch(srv.Client(), srv.URL, 0)
+	if err != nil {
+		t.Fatalf("Fetch : erreur inattendue : %v", err)
+	}
+	if info.Title != "Titre sur plusieurs lignes" || info.Description != "Résumé" {
+		t.Errorf("Fetch = %+v, attendu le <title> et la meta description", info)
+	}
+	if info.Favicon != srv.URL+"/favicon.ico" {
+		t.Errorf("Favicon = %q, attendu /favicon.ico sur l'origine de la page", info.Favicon)
+	}
+	if info.Image != "" {
+		t.Errorf("Image = %q, attendu vide", info.Image)
+	}
+}
+
+func TestFetchDecodesCharset(t *testing.T) {
+	// "Café" en ISO-8859-1
+	srv := newPageServer(t, "text/html; charset=iso-8859-1", "<html><head><title>Caf\xe9</title></head></html>")
+
+	info, err := pageinfo.Fetch(srv.Client(), srv.URL, 0)
+	if err != nil {
+		t.Fatalf("Fetch : erreur inattendue : %v", err)
+	}
+	if info.Title != "Café" {
+		t.Errorf("Title = %q, attendu %q", info.Title, "Café")
+	}
+}
+
+func TestFetchStopsAtMaxBytes(t *testing.T) {
+	padding := "<!--" + strings.Repeat("x", 4096) + "-->"
+	srv := newPageServer(t, "text/html", "<html><head><title>Début</title>"+padding+
+		`<meta property="og:title" content="Au-delà de la limite"></head></html>`)
+
+	info, err := pageinfo.Fetch(srv.Client(), srv.URL, 1024)
+	if err != nil {
+		t.Fatalf("Fetch : erreur inattendue : %v", err)
+	}
+	if info.Title != "Début" {
+		t.Errorf("Title = %q : les octets au-delà de maxBytes ne doivent pas être lus", info.Title)
+	}
+
+	info, err = pageinfo.Fetch(srv.Client(), srv.URL, 8192)
+	if err != nil {
+		t.Fatalf("Fetch : erreur inattendue : %v", err)
+	}
+	if info.Title != "Au-delà de la limite" {
+		t.Errorf("Title = %q, attendu og:title avec une limite suffisante", info.Title)
+	}
+}
+
+func TestFetchRejectsNonHTML(t *testing.T) {
+	srv := newPageServer(t, "application/pdf", "%PDF-1.7")
+
+	if _, err := pageinfo.Fetch(srv.Client(), srv.URL, 0); !errors.Is(err, pageinfo.ErrNotHTML) {
+		t.Errorf("Fetch : erreur %v, attendu ErrNotHTML", err)
+	}
+}
+
+func TestFetchRejectsErrorStatus(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	defer srv.Close()
+
+	if _, err := pageinfo.Fetch(srv.Client(), srv.URL, 0); err == nil {
+		t.Error("Fetch : une réponse 404 doit être une erreur")
+	}
+}
+
+func TestFetchRefusesPrivateAddresses(t *testing.T) {
+	srv := newPageServer(t, "text/html", "<title>Page interne</title>")
+
+	info, err := pageinfo.Fetch(monitor.NewPublicHTTPClient(), srv.URL, 0)
+	if !errors.Is(err, monitor.ErrPrivateAddress) {
+		t.Fatalf("Fetch : erreur %v, attendu ErrPrivateAddress pour %s", err, srv.URL)
+	}
+	if info.Title != "" {
+		t.Errorf("Title = %q : le contenu d'une adresse privée ne doit pas être lu", info.Title)
+	}
+}
+
+func TestParseIgnoresUnsafeURLs(t *testing.T) {
+	info := pageinfo.Parse(strings.NewReader(`<head>
+		<meta property="og:image" content="javascript:alert(1)">
+		<link rel="icon" href="data:image/png;base64,AAAA">
+	</head>`), nil)
+	if info.Image != "" || info.Favicon != "" {
+		t.Errorf("Parse = %+v, les URLs non http(s) doivent être écartées", info)
+	}
+}
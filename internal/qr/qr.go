@@ -0,0 +1,272 @@
+package qr
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/jpeg" // Logos JPEG
+	"image/png"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+// Formats d'image supportés.
+const (
+	FormatPNG = "png"
+	FormatSVG = "svg"
+)
+
+// Valeurs par défaut et limites des options de rendu.
+const (
+	DefaultSize   = 256
+	DefaultMargin = 4 // Zone de silence recommandée par la norme, en modules
+	MaxSize       = 4096
+	MaxMargin     = 16
+	// logoRatio est la part maximale de la largeur du code occupée par le logo : avec le niveau de
+	// correction H (30 % de redondance), le code reste lisible malgré les modules masqués.
+	logoRatio = 0.2
+)
+
+// ErrInvalidOptions est retournée (enveloppée avec la raison précise) pour des options de rendu invalides.
+var ErrInvalidOptions = errors.New("invalid qr code options")
+
+// recoveryLevels associe les niveaux de correction d'erreur de la norme à ceux de l'encodeur.
+var recoveryLevels = map[string]qrcode.RecoveryLevel{
+	"L": qrcode.Low,
+	"M": qrcode.Medium,
+	"Q": qrcode.High,
+	"H": qrcode.Highest,
+}
+
+// Options configure le rendu d'un QR code. Les valeurs nulles sont remplacées par les valeurs par défaut.
+type Options struct {
+	Format     string      // png ou svg
+	Size       int         // Largeur et hauteur de l'image, en pixels
+	ECC        string      // Niveau de correction d'erreur : L, M, Q ou H (forcé à H avec un logo)
+	Margin     int         // Marge autour du code, en modules ; négatif pour aucune marge
+	Foreground color.Color // Couleur des modules, noir par défaut
+	Background color.Color // Couleur du fond, blanc par défaut
+	Logo       image.Image // Logo optionnel, centré sur le code
+}
+
+// withDefaults complète les options et vérifie leurs valeurs.
+func (o Options) withDefaults() (Options, error) {
+	if o.Format == "" {
+		o.Format = FormatPNG
+	}
+	if o.Format != FormatPNG && o.Format != FormatSVG {
+		return o, fmt.Errorf("%w: format must be %s or %s", ErrInvalidOptions, FormatPNG, FormatSVG)
+	}
+	if o.Size == 0 {
+		o.Size = DefaultSize
+	}
+	if o.Size < 21 || o.Size > MaxSize {
+		return o, fmt.Errorf("%w: size must be between 21 and %d pixels", ErrInvalidOptions, MaxSize)
+	}
+	if o.ECC == "" {
+		o.ECC = "M"
+	}
+	o.ECC = strings.ToUpper(o.ECC)
+	if _, ok := recoveryLevels[o.ECC]; !ok {
+		return o, fmt.Errorf("%w: ecc must be one of L, M, Q, H", ErrInvalidOptions)
+	}
+	if o.Logo != nil {
+		o.ECC = "H"
+	}
+	switch {
+	case o.Margin == 0:
+		o.Margin = DefaultMargin
+	case o.Margin < 0:
+		o.Margin = 0
+	case o.Margin > MaxMargin:
+		return o, fmt.Errorf("%w: margin must be at most %d modules", ErrInvalidOptions, MaxMargin)
+	}
+	if o.Foreground == nil {
+		o.Foreground = color.Black
+	}
+	if o.Background == nil {
+		o.Background = color.White
+	}
+	return o, nil
+}
+
+// ParseColor interprète une couleur hexadécimale "#rrggbb" ou "rrggbb" (le # est facultatif car il doit
+// être encodé dans une URL). Une chaîne vide retourne nil, c'est-à-dire la couleur par défaut.
+func ParseColor(value string) (color.Color, error) {
+	if value == "" {
+		return nil, nil
+	}
+	hex := strings.TrimPrefix(value, "#")
+	if len(hex) != 6 {
+		return nil, fmt.Errorf("%w: color %q must be formatted as rrggbb", ErrInvalidOptions, value)
+	}
+	rgb, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return nil, fmt.Errorf("%w: color %q must be formatted as rrggbb", ErrInvalidOptions, value)
+	}
+	return color.RGBA{R: uint8(rgb >> 16), G: uint8(rgb >> 8), B: uint8(rgb), A: 0xff}, nil
+}
+
+// LoadLogo lit un logo PNG ou JPEG depuis le disque.
+func LoadLogo(path string) (image.Image, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open logo: %w", err)
+	}
+	defer file.Close()
+	logo, _, err := image.Decode(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode logo %s: %w", path, err)
+	}
+	return logo, nil
+}
+
+// ContentType retourne le type MIME d'un format d'image.
+func ContentType(format string) string {
+	if format == FormatSVG {
+		return "image/svg+xml"
+	}
+	return "image/png"
+}
+
+// Encode écrit dans w le QR code de content, rendu selon les options.
+// Tout est calculé localement, sans service externe.
+func Encode(w io.Writer, content string, opts Options) error {
+	opts, err := opts.withDefaults()
+	if err != nil {
+		return err
+	}
+
+	code, err := qrcode.New(content, recoveryLevels[opts.ECC])
+	if err != nil {
+		return fmt.Errorf("failed to encode qr code: %w", err)
+	}
+	code.DisableBorder = true
+	modules := code.Bitmap()
+
+	// Taille d'un module en pixels : la plus grande taille entière qui tient dans l'image, le reste
+	// étant réparti en marge supplémentaire pour que les modules restent nets.
+	total := len(modules) + 2*opts.Margin
+	moduleSize := opts.Size / total
+	if moduleSize == 0 {
+		return fmt.Errorf("%w: size %d is too small for this content, at least %d pixels are needed", ErrInvalidOptions, opts.Size, total)
+	}
+	offset := (opts.Size - moduleSize*len(modules)) / 2
+
+	if opts.Format == FormatSVG {
+		return writeSVG(w, modules, moduleSize, offset, opts)
+	}
+	return writePNG(w, modules, moduleSize, offset, opts)
+}
+
+// logoBounds retourne le carré central (en pixels) réservé au logo.
+func logoBounds(opts Options) image.Rectangle {
+	side := int(float64(opts.Size) * logoRatio)
+	origin := (opts.Size - side) / 2
+	return image.Rect(origin, origin, origin+side, origin+side)
+}
+
+// writePNG dessine le code dans une image PNG.
+func writePNG(w io.Writer, modules [][]bool, moduleSize, offset int, opts Options) error {
+	img := image.NewRGBA(image.Rect(0, 0, opts.Size, opts.Size))
+	draw.Draw(img, img.Bounds(), image.NewUniform(opts.Background), image.Point{}, draw.Src)
+
+	foreground := image.NewUniform(opts.Foreground)
+	for y, row := range modules {
+		for x, dark := range row {
+			if dark {
+				rect := image.Rect(offset+x*moduleSize, offset+y*moduleSize, offset+(x+1)*moduleSize, offset+(y+1)*moduleSize)
+				draw.Draw(img, rect, foreground, image.Point{}, draw.Src)
+			}
+		}
+	}
+
+	if opts.Logo != nil {
+		bounds := logoBounds(opts)
+		draw.Draw(img, bounds, image.NewUniform(opts.Background), image.Point{}, draw.Src)
+		inner := bounds.Inset(bounds.Dx() / 10)
+		logo := scaleImage(opts.Logo, inner.Dx())
+		origin := inner.Min.Add(image.Pt((inner.Dx()-logo.Bounds().Dx())/2, (inner.Dy()-logo.Bounds().Dy())/2))
+		draw.Draw(img, logo.Bounds().Add(origin), logo, image.Point{}, draw.Over)
+	}
+
+	return png.Encode(w, img)
+}
+
+// writeSVG décrit le code en SVG, chaque suite horizontale de modules formant un seul rectangle.
+func writeSVG(w io.Writer, modules [][]bool, moduleSize, offset int, opts Options) error {
+	var path strings.Builder
+	for y, row := range modules {
+		for x := 0; x < len(row); {
+			if !row[x] {
+				x++
+				continue
+			}
+			start := x
+			for x < len(row) && row[x] {
+				x++
+			}
+			fmt.Fprintf(&path, "M%d %dh%dv%dh-%dz", offset+start*moduleSize, offset+y*moduleSize,
+				(x-start)*moduleSize, moduleSize, (x-start)*moduleSize)
+		}
+	}
+
+	var svg strings.Builder
+	fmt.Fprintf(&svg, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`,
+		opts.Size, opts.Size, opts.Size, opts.Size)
+	fmt.Fprintf(&svg, `<rect width="100%%" height="100%%" fill="%s"/>`, hexColor(opts.Background))
+	fmt.Fprintf(&svg, `<path fill="%s" d="%s"/>`, hexColor(opts.Foreground), path.String())
+
+	if opts.Logo != nil {
+		bounds := logoBounds(opts)
+		inner := bounds.Inset(bounds.Dx() / 10)
+		var encoded bytes.Buffer
+		if err := png.Encode(&encoded, scaleImage(opts.Logo, inner.Dx())); err != nil {
+			return fmt.Errorf("failed to encode logo: %w", err)
+		}
+		fmt.Fprintf(&svg, `<rect x="%d" y="%d" width="%d" height="%d" fill="%s"/>`,
+			bounds.Min.X, bounds.Min.Y, bounds.Dx(), bounds.Dy(), hexColor(opts.Background))
+		fmt.Fprintf(&svg, `<image x="%d" y="%d" width="%d" height="%d" href="data:image/png;base64,%s"/>`,
+			inner.Min.X, inner.Min.Y, inner.Dx(), inner.Dy(), base64.StdEncoding.EncodeToString(encoded.Bytes()))
+	}
+	svg.WriteString("</svg>\n")
+
+	_, err := io.WriteString(w, svg.String())
+	return err
+}
+
+// hexColor retourne la notation hexadécimale "#rrggbb" d'une couleur.
+func hexColor(c color.Color) string {
+	r, g, b, _ := c.RGBA()
+	return fmt.Sprintf("#%02x%02x%02x", r>>8, g>>8, b>>8)
+}
+
+// scaleImage redimensionne une image pour qu'elle tienne dans un carré de side pixels,
+// en conservant ses proportions (plus proche voisin).
+func scaleImage(src image.Image, side int) image.Image {
+	bounds := src.Bounds()
+	if bounds.Empty() || side <= 0 {
+		return image.NewRGBA(image.Rectangle{})
+	}
+	width, height := side, side
+	if bounds.Dx() > bounds.Dy() {
+		height = side * bounds.Dy() / bounds.Dx()
+	} else {
+		width = side * bounds.Dx() / bounds.Dy()
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			dst.Set(x, y, src.At(bounds.Min.X+x*bounds.Dx()/width, bounds.Min.Y+y*bounds.Dy()/height))
+		}
+	}
+	return dst
+}
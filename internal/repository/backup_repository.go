@@ -0,0 +1,363 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TableRows représente le contenu brut d'une table : une map colonne -> valeur par ligne.
+// Ce format générique permet de sauvegarder et restaurer toutes les tables sans code spécifique à chaque modèle.
+type TableRows = []map[string]interface{}
+
+// BackupRepository définit les opérations bas niveau nécessaires à la sauvegarde et à la restauration de la base.
+type BackupRepository interface {
+	Driver() string
+	Tables() ([]string, error)
+	VacuumInto(path string) error
+	ReadTables() (map[string]TableRows, error)
+	CountRows(table string) (int, error)
+	CountSkippedRows(data map[string]TableRows) (map[string]int, error)
+	ReplaceTables(data map[string]TableRows) error
+	MergeTables(data map[string]TableRows) (map[string]int, error)
+}
+
+// GormBackupRepository est l'implémentation de BackupRepository utilisant GORM.
+type GormBackupRepository struct {
+	db *gorm.DB
+}
+
+// NewBackupRepository crée et retourne une nouvelle instance de GormBackupRepository.
+func NewBackupRepository(db *gorm.DB) *GormBackupRepository {
+	return &GormBackupRepository{db: db}
+}
+
+// restoreBatchSize limite le nombre de lignes insérées par requête lors d'une restauration.
+const restoreBatchSize = 100
+
+// Driver retourne le nom du driver de base de données utilisé (ex: "sqlite").
+func (r *GormBackupRepository) Driver() string {
+	return r.db.Dialector.Name()
+}
+
+// Tables retourne le nom des tables de l'application, dans l'ordre défini par models.All.
+func (r *GormBackupRepository) Tables() ([]string, error) {
+	tables := make([]string, 0, len(models.All))
+	for _, model := range models.All {
+		stmt := &gorm.Statement{DB: r.db}
+		if err := stmt.Parse(model); err != nil {
+			return nil, fmt.Errorf("failed to resolve table name for %T: %w", model, err)
+		}
+		tables = append(tables, stmt.Schema.Table)
+	}
+	return tables, nil
+}
+
+// tableInfo décrit une table de l'application pour la restauration par fusion.
+type tableInfo struct {
+	name       string
+	primaryKey string
+	uniqueKeys [][]string        // Colonnes de chaque contrainte d'unicité, clé primaire comprise
+	parents    map[string]string // Colonne -> table de l'application qu'elle référence (ex: "link_id" -> "links")
+}
+
+// tableInfos décrit les tables de l'application, dans l'ordre défini par models.All. Les références entre tables
+// sont déduites des noms de champs : LinkID référence la table du modèle Link, TagID celle du modèle Tag...
+func (r *GormBackupRepository) tableInfos() ([]tableInfo, error) {
+	infos := make([]tableInfo, 0, len(models.All))
+	known := make(map[string]bool, len(models.All))
+	for _, model := range models.All {
+		stmt := &gorm.Statement{DB: r.db}
+		if err := stmt.Parse(model); err != nil {
+			return nil, fmt.Errorf("failed to resolve schema for %T: %w", model, err)
+		}
+		schema := stmt.Schema
+		info := tableInfo{
+			name:       schema.Table,
+			primaryKey: schema.PrioritizedPrimaryField.DBName,
+			uniqueKeys: [][]string{{schema.PrioritizedPrimaryField.DBName}},
+			parents:    make(map[string]string),
+		}
+		for _, index := range schema.ParseIndexes() {
+			if index.Class != "UNIQUE" {
+				continue
+			}
+			columns := make([]string, 0, len(index.Fields))
+			for _, field := range index.Fields {
+				columns = append(columns, field.DBName)
+			}
+			info.uniqueKeys = append(info.uniqueKeys, columns)
+		}
+		for _, field := range schema.Fields {
+			if field.DBName == "" || field.PrimaryKey {
+				continue
+			}
+			if field.Unique {
+				info.uniqueKeys = append(info.uniqueKeys, []string{field.DBName})
+			}
+			if strings.HasSuffix(field.Name, "ID") {
+				info.parents[field.DBName] = r.db.NamingStrategy.TableName(strings.TrimSuffix(field.Name, "ID"))
+			}
+		}
+		infos = append(infos, info)
+		known[info.name] = true
+	}
+
+	for _, info := range infos {
+		for column, parent := range info.parents {
+			if !known[parent] {
+				delete(info.parents, column)
+			}
+		}
+	}
+	return infos, nil
+}
+
+// VacuumInto écrit une copie cohérente de la base SQLite dans le fichier indiqué.
+// L'opération est sûre pendant que le serveur tourne : SQLite la réalise dans une transaction de lecture.
+func (r *GormBackupRepository) VacuumInto(path string) error {
+	if r.Driver() != "sqlite" {
+		return fmt.Errorf("VACUUM INTO is not supported by driver %q", r.Driver())
+	}
+	return r.db.Exec("VACUUM INTO ?", path).Error
+}
+
+// ReadTables lit l'intégralité des tables de l'application.
+// Les tables absentes de la base (ex: sauvegarde ancienne) sont ignorées.
+func (r *GormBackupRepository) ReadTables() (map[string]TableRows, error) {
+	infos, err := r.tableInfos()
+	if err != nil {
+		return nil, err
+	}
+
+	data := make(map[string]TableRows, len(infos))
+	for _, info := range infos {
+		if !r.db.Migrator().HasTable(info.name) {
+			continue
+		}
+		var rows TableRows
+		if err := r.db.Table(info.name).Order(clause.OrderByColumn{Column: clause.Column{Name: info.primaryKey}}).Find(&rows).Error; err != nil {
+			return nil, fmt.Errorf("failed to read table %s: %w", info.name, err)
+		}
+		data[info.name] = rows
+	}
+	return data, nil
+}
+
+// CountRows compte le nombre de lignes actuellement présentes dans une table.
+func (r *GormBackupRepository) CountRows(table string) (int, error) {
+	var count int64
+	if err := r.db.Table(table).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return int(count), nil
+}
+
+// CountSkippedRows compte, par table, les lignes fournies qu'une fusion (MergeTables) ignorerait.
+func (r *GormBackupRepository) CountSkippedRows(data map[string]TableRows) (map[string]int, error) {
+	_, skipped, err := r.mergeRows(r.db, data)
+	return skipped, err
+}
+
+// mergeRows sépare les lignes fournies entre celles qu'une fusion insère et celles qu'elle ignore, et retourne
+// les premières ainsi que le nombre des secondes, par table. Une ligne est ignorée si elle entre en conflit avec
+// une ligne existante (ou déjà retenue) sur sa clé primaire ou une contrainte d'unicité, ou si elle référence une
+// ligne ignorée : les clics d'un lien ignoré seraient sinon rattachés au lien existant de même identifiant.
+func (r *GormBackupRepository) mergeRows(db *gorm.DB, data map[string]TableRows) (map[string]TableRows, map[string]int, error) {
+	infos, err := r.tableInfos()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	kept := make(map[string]TableRows, len(infos))
+	skipped := make(map[string]int, len(infos))
+	skippedIDs := make(map[string]map[string]bool, len(infos)) // Table -> clés primaires des lignes ignorées
+	for _, info := range infos {
+		rows := data[info.name]
+		skippedIDs[info.name] = make(map[string]bool)
+		if len(rows) == 0 {
+			continue
+		}
+
+		taken := make([]map[string]bool, len(info.uniqueKeys))
+		for i, columns := range info.uniqueKeys {
+			if taken[i], err = existingKeys(db, info.name, columns, rows); err != nil {
+				return nil, nil, fmt.Errorf("failed to compare rows in %s: %w", info.name, err)
+			}
+		}
+
+		for _, row := range rows {
+			if conflicts(row, info, taken, skippedIDs) {
+				skipped[info.name]++
+				if id, ok := rowKey(row, []string{info.primaryKey}); ok {
+					skippedIDs[info.name][id] = true
+				}
+				continue
+			}
+			for i, columns := range info.uniqueKeys {
+				if key, ok := rowKey(row, columns); ok {
+					taken[i][key] = true
+				}
+			}
+			kept[info.name] = append(kept[info.name], row)
+		}
+	}
+	return kept, skipped, nil
+}
+
+// conflicts indique si une ligne doit être ignorée lors d'une fusion : l'une de ses clés uniques est déjà prise,
+// ou elle référence une ligne ignorée.
+func conflicts(row map[string]interface{}, info tableInfo, taken []map[string]bool, skippedIDs map[string]map[string]bool) bool {
+	for i, columns := range info.uniqueKeys {
+		if key, ok := rowKey(row, columns); ok && taken[i][key] {
+			return true
+		}
+	}
+	for column, parent := range info.parents {
+		if key, ok := rowKey(row, []string{column}); ok && skippedIDs[parent][key] {
+			return true
+		}
+	}
+	return false
+}
+
+// existingKeys retourne les valeurs (formatées par rowKey) que prennent déjà dans la table les colonnes d'une
+// contrainte d'unicité, parmi celles des lignes fournies.
+func existingKeys(db *gorm.DB, table string, columns []string, rows TableRows) (map[string]bool, error) {
+	keys := make(map[string]bool)
+	for start := 0; start < len(rows); start += restoreBatchSize {
+		end := min(start+restoreBatchSize, len(rows))
+		conditions := make([]clause.Expression, 0, end-start)
+		for _, row := range rows[start:end] {
+			if _, ok := rowKey(row, columns); !ok {
+				continue
+			}
+			equalities := make([]clause.Expression, 0, len(columns))
+			for _, column := range columns {
+				equalities = append(equalities, clause.Eq{Column: clause.Column{Name: column}, Value: row[column]})
+			}
+			conditions = append(conditions, clause.And(equalities...))
+		}
+		if len(conditions) == 0 {
+			continue
+		}
+
+		var existing TableRows
+		if err := db.Table(table).Select(columns).Where(clause.Or(conditions...)).Find(&existing).Error; err != nil {
+			return nil, err
+		}
+		for _, row := range existing {
+			if key, ok := rowKey(row, columns); ok {
+				keys[key] = true
+			}
+		}
+	}
+	return keys, nil
+}
+
+// rowKey formate les valeurs d'une ligne pour les colonnes indiquées. Il renvoie false si l'une est NULL :
+// une contrainte d'unicité ne s'applique pas à une telle ligne.
+func rowKey(row map[string]interface{}, columns []string) (string, bool) {
+	values := make([]string, 0, len(columns))
+	for _, column := range columns {
+		value, ok := row[column]
+		if !ok || value == nil {
+			return "", false
+		}
+		values = append(values, fmt.Sprint(value))
+	}
+	return strings.Join(values, "\x00"), true
+}
+
+// ReplaceTables remplace le contenu des tables fournies, dans une seule transaction.
+// Les tables sont vidées dans l'ordre inverse de models.All puis remplies dans l'ordre direct. Une table absente
+// des données (ex: sauvegarde antérieure à sa création) n'est pas vidée : son contenu actuel est conservé.
+func (r *GormBackupRepository) ReplaceTables(data map[string]TableRows) error {
+	tables, err := r.Tables()
+	if err != nil {
+		return err
+	}
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for i := len(tables) - 1; i >= 0; i-- {
+			if _, ok := data[tables[i]]; !ok {
+				continue
+			}
+			if err := tx.Exec("DELETE FROM ?", clause.Table{Name: tables[i]}).Error; err != nil {
+				return fmt.Errorf("failed to clear table %s: %w", tables[i], err)
+			}
+		}
+		for _, table := range tables {
+			rows := data[table]
+			if len(rows) == 0 {
+				continue
+			}
+			if err := tx.Table(table).CreateInBatches(rows, restoreBatchSize).Error; err != nil {
+				return fmt.Errorf("failed to restore table %s: %w", table, err)
+			}
+		}
+		return nil
+	})
+}
+
+// MergeTables insère les lignes fournies en conservant les lignes existantes, dans une seule transaction :
+// toute ligne en conflit (même identifiant, même code court...) est ignorée, ainsi que les lignes qui en dépendent
+// (voir mergeRows). Elle retourne le nombre de lignes réellement insérées par table.
+func (r *GormBackupRepository) MergeTables(data map[string]TableRows) (map[string]int, error) {
+	inserted := make(map[string]int, len(data))
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		kept, _, err := r.mergeRows(tx, data)
+		if err != nil {
+			return err
+		}
+		tables, err := r.Tables()
+		if err != nil {
+			return err
+		}
+		for _, table := range tables {
+			rows := kept[table]
+			for start := 0; start < len(rows); start += restoreBatchSize {
+				end := min(start+restoreBatchSize, len(rows))
+				batch := rows[start:end]
+				result := tx.Table(table).Clauses(clause.OnConflict{DoNothing: true}).Create(&batch)
+				if result.Error != nil {
+					return fmt.Errorf("failed to merge table %s: %w", table, result.Error)
+				}
+				inserted[table] += int(result.RowsAffected)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return inserted, nil
+}
+
+// ReadSQLiteBackup ouvre un fichier de sauvegarde SQLite en lecture seule, vérifie son intégrité
+// (PRAGMA integrity_check) et retourne le contenu de ses tables.
+func ReadSQLiteBackup(path string) (map[string]TableRows, error) {
+	db, err := gorm.Open(sqlite.Open("file:"+path+"?mode=ro"), &gorm.Config{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open backup file: %w", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	defer sqlDB.Close()
+
+	var results []string
+	if err := db.Raw("PRAGMA integrity_check").Scan(&results).Error; err != nil {
+		return nil, fmt.Errorf("failed to check backup integrity: %w", err)
+	}
+	if len(results) != 1 || results[0] != "ok" {
+		return nil, fmt.Errorf("backup integrity check failed: %v", results)
+	}
+
+	return NewBackupRepository(db).ReadTables()
+}
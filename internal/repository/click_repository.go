@@ -10,14 +10,17 @@ import (
 // de rester indépendante de l'implémentation spécifique de la base de données.
 // Implémenter l'interface avec les méthodes nécessaires.
 type ClickRepository interface {
+	ForWorkspace(workspaceID uint) ClickRepository
 	// Utilisé par LinkService pour les stats
 	CreateClick(click *models.Click) error
 	CountClicksByLinkID(linkID uint) (int, error)
+	StreamClicksByLinkID(linkID uint, fn func(click *models.Click) error) error
 }
 
 // GormClickRepository est l'implémentation de l'interface ClickRepository utilisant GORM.
 type GormClickRepository struct {
-	db *gorm.DB // Référence à l'instance de la base de données GORM
+	db        *gorm.DB // Référence à l'instance de la base de données GORM
+	workspace *uint    // Espace de travail auquel les requêtes sont restreintes ; nil pour tous
 }
 
 // NewClickRepository crée et retourne une nouvelle instance de GormClickRepository.
@@ -26,10 +29,28 @@ func NewClickRepository(db *gorm.DB) *GormClickRepository {
 	return &GormClickRepository{db: db}
 }
 
+// ForWorkspace retourne un dépôt dont toutes les requêtes sont restreintes aux clics d'un espace de travail.
+func (r *GormClickRepository) ForWorkspace(workspaceID uint) ClickRepository {
+	return &GormClickRepository{db: r.db, workspace: &workspaceID}
+}
+
+// clicks retourne une requête sur la table des clics, restreinte à l'espace de travail du dépôt.
+func (r *GormClickRepository) clicks() *gorm.DB {
+	query := r.db.Model(&models.Click{})
+	if r.workspace != nil {
+		query = query.Where("clicks.workspace_id = ?", *r.workspace)
+	}
+	return query
+}
+
 // CreateClick insère un nouvel enregistrement de clic dans la base de données.
 // Elle reçoit un pointeur vers une structure models.Click et la persiste en utilisant GORM.
+// Le clic est rattaché à l'espace de travail du dépôt, s'il est restreint à l'un d'eux.
 func (r *GormClickRepository) CreateClick(click *models.Click) error {
 	// TODO : Utiliser GORM pour créer une nouvelle entrée dans la table "clicks"
+	if r.workspace != nil {
+		click.WorkspaceID = *r.workspace
+	}
 	return r.db.Create(click).Error
 }
 
@@ -39,9 +60,22 @@ func (r *GormClickRepository) CountClicksByLinkID(linkID uint) (int, error) {
 	var count int64 // GORM retourne un int64 pour les décomptes
 	// TODO : Utiliser GORM pour compter les enregistrements dans la table 'clicks'
 	// où 'LinkID' correspond à l'ID de lien fourni.
-	err := r.db.Model(&models.Click{}).Where("link_id = ?", linkID).Count(&count).Error
+	err := r.clicks().Where("link_id = ?", linkID).Count(&count).Error
 	if err != nil {
 		return 0, err
 	}
 	return int(count), nil // Convert the int64 count to an int
 }
+
+// StreamClicksByLinkID parcourt les clics d'un lien par ordre chronologique d'enregistrement, en les chargeant par lots.
+func (r *GormClickRepository) StreamClicksByLinkID(linkID uint, fn func(click *models.Click) error) error {
+	var batch []models.Click
+	return r.clicks().Where("link_id = ?", linkID).FindInBatches(&batch, 1000, func(tx *gorm.DB, _ int) error {
+		for i := range batch {
+			if err := fn(&batch[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}).Error
+}
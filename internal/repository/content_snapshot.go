@@ -0,0 +1,25 @@
+package repository
+
+import "github.com/axellelanca/urlshortener/internal/models"
+
+// CreateContentSnapshot enregistre une empreinte du contenu de la destination d'un lien.
+func (r *GormLinkRepository) CreateContentSnapshot(snapshot *models.ContentSnapshot) error {
+	return r.db.Create(snapshot).Error
+}
+
+// LatestContentSnapshot récupère la dernière empreinte enregistrée pour un lien.
+// Il renvoie gorm.ErrRecordNotFound si le contenu du lien n'a encore jamais été relevé.
+func (r *GormLinkRepository) LatestContentSnapshot(linkID uint) (*models.ContentSnapshot, error) {
+	var snapshot models.ContentSnapshot
+	if err := r.db.Where("link_id = ?", linkID).Order("id DESC").First(&snapshot).Error; err != nil {
+		return nil, err
+	}
+	return &snapshot, nil
+}
+
+// ListContentSnapshots récupère les limit dernières empreintes d'un lien, de la plus récente à la plus ancienne.
+func (r *GormLinkRepository) ListContentSnapshots(linkID uint, limit int) ([]models.ContentSnapshot, error) {
+	var snapshots []models.ContentSnapshot
+	err := r.db.Where("link_id = ?", linkID).Order("id DESC").Limit(limit).Find(&snapshots).Error
+	return snapshots, err
+}
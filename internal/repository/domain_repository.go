@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"github.com/axellelanca/urlshortener/internal/models"
+	"gorm.io/gorm"
+)
+
+// DomainRepository est une interface qui définit les méthodes d'accès aux données
+// pour les domaines courts personnalisés.
+type DomainRepository interface {
+	ForWorkspace(workspaceID uint) DomainRepository
+	CreateDomain(domain *models.Domain) error
+	HostTaken(host string) (bool, error)
+	GetDomainByHost(host string) (*models.Domain, error)
+	GetDomainByID(id uint) (*models.Domain, error)
+	GetAllDomains() ([]models.Domain, error)
+}
+
+// GormDomainRepository est l'implémentation de DomainRepository utilisant GORM.
+type GormDomainRepository struct {
+	db        *gorm.DB
+	workspace *uint // Espace de travail auquel les requêtes sont restreintes ; nil pour tous
+}
+
+// NewDomainRepository crée et retourne une nouvelle instance de GormDomainRepository.
+func NewDomainRepository(db *gorm.DB) *GormDomainRepository {
+	return &GormDomainRepository{db: db}
+}
+
+// ForWorkspace retourne un dépôt dont toutes les requêtes sont restreintes aux domaines d'un espace de travail ;
+// les domaines qu'il crée sont rattachés à cet espace.
+func (r *GormDomainRepository) ForWorkspace(workspaceID uint) DomainRepository {
+	return &GormDomainRepository{db: r.db, workspace: &workspaceID}
+}
+
+// domains retourne une requête sur la table des domaines, restreinte à l'espace de travail du dépôt.
+func (r *GormDomainRepository) domains() *gorm.DB {
+	query := r.db.Model(&models.Domain{})
+	if r.workspace != nil {
+		query = query.Where("workspace_id = ?", *r.workspace)
+	}
+	return query
+}
+
+// CreateDomain insère un nouveau domaine dans la base de données.
+func (r *GormDomainRepository) CreateDomain(domain *models.Domain) error {
+	if r.workspace != nil {
+		domain.WorkspaceID = *r.workspace
+	}
+	return r.db.Create(domain).Error
+}
+
+// HostTaken indique si un hôte est déjà enregistré. Un hôte ne peut servir qu'un seul espace de travail :
+// cette vérification n'est donc pas restreinte à l'espace de travail du dépôt.
+func (r *GormDomainRepository) HostTaken(host string) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.Domain{}).Where("host = ?", host).Count(&count).Error
+	return count > 0, err
+}
+
+// GetDomainByHost récupère un domaine par son hôte.
+// Il renvoie gorm.ErrRecordNotFound si aucun domaine ne correspond.
+func (r *GormDomainRepository) GetDomainByHost(host string) (*models.Domain, error) {
+	var domain models.Domain
+	if err := r.domains().Where("host = ?", host).First(&domain).Error; err != nil {
+		return nil, err
+	}
+	return &domain, nil
+}
+
+// GetDomainByID récupère un domaine par son identifiant.
+// Il renvoie gorm.ErrRecordNotFound si aucun domaine ne correspond.
+func (r *GormDomainRepository) GetDomainByID(id uint) (*models.Domain, error) {
+	var domain models.Domain
+	if err := r.domains().First(&domain, id).Error; err != nil {
+		return nil, err
+	}
+	return &domain, nil
+}
+
+// GetAllDomains récupère tous les domaines, par ordre de création.
+func (r *GormDomainRepository) GetAllDomains() ([]models.Domain, error) {
+	var domains []models.Domain
+	if err := r.domains().Order("id").Find(&domains).Error; err != nil {
+		return nil, err
+	}
+	return domains, nil
+}
@@ -1,23 +1,71 @@
 package repository
 
 import (
+	"errors"
+	"fmt"
+	"time"
+
 	"github.com/axellelanca/urlshortener/internal/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // TODO LinkRepository est une interface qui définit les méthodes d'accès aux données
 // pour les opérations CRUD sur les liens.
 // L'implémenter avec les méthodes nécessaires
 type LinkRepository interface {
+	ForWorkspace(workspaceID uint) LinkRepository
+	WithLinkLimit(maxLinks int) LinkRepository
 	CreateLink(link *models.Link) error
-	GetLinkByShortCode(shortCode string) (*models.Link, error)
+	CreateLinks(links []*models.Link) error
+	ExistingShortCodes(domainID uint, shortCodes []string) (map[string]bool, error)
+	GetLinkByShortCode(domainID uint, shortCode string) (*models.Link, error)
+	GetLinkByID(id uint) (*models.Link, error)
+	FindLinksByURLHash(domainID uint, owner, urlHash string, limit int) ([]models.Link, error)
+	BackfillURLHashes(hash func(longURL string) string) (int, error)
+	GetIdempotencyKey(key string, since time.Time) (*models.IdempotencyKey, error)
+	CreateLinkWithIdempotencyKey(link *models.Link, key *models.IdempotencyKey, expiredBefore time.Time) error
+	NextCodeSequence() (uint64, error)
 	GetAllLinks() ([]models.Link, error)
+	CountLinks() (int64, error)
+	StreamLinks(filter LinkFilter, fn func(link *models.Link) error) error
+	ListLinks(filter LinkFilter, offset, limit int) ([]models.Link, int64, error)
+	LoadLinkTags(links ...*models.Link) error
+	EnsureSearchIndex() (bool, error)
+	DisableLink(id uint, reason string) error
+	SavePageInfo(link *models.Link) error
+	CreateContentSnapshot(snapshot *models.ContentSnapshot) error
+	LatestContentSnapshot(linkID uint) (*models.ContentSnapshot, error)
+	ListContentSnapshots(linkID uint, limit int) ([]models.ContentSnapshot, error)
+	IncrementFailedPasswordAttempts(id uint) error
+	ConsumeView(id uint, eraseDestination bool) (bool, error)
 	CountClicksByLinkID(linkID uint) (int, error)
+	CountClicksGroupedBy(linkID uint, column string) (map[string]int, error)
+	CountClicksSince(since time.Time) (int64, error)
+}
+
+// LinkFilter restreint les liens parcourus par StreamLinks. Les champs vides sont ignorés.
+type LinkFilter struct {
+	Owner       string
+	CreatedFrom time.Time // Inclus
+	CreatedTo   time.Time // Exclus
+	Status      string    // État de la fenêtre d'activité : models.LinkStatusScheduled, LinkStatusActive ou LinkStatusEnded
+	Tag         string    // Nom d'une étiquette portée par le lien
+	Search      string    // Mots recherchés dans le titre, la description, l'URL, le code court et les étiquettes
 }
 
+// ErrLinkLimitReached est retournée lorsqu'une création de liens ferait dépasser la limite de liens du dépôt
+// (voir WithLinkLimit) : aucun lien n'est alors créé.
+var ErrLinkLimitReached = errors.New("link limit reached")
+
+// streamBatchSize est le nombre de liens chargés en mémoire à la fois lors d'un parcours.
+const streamBatchSize = 500
+
 // TODO :  GormLinkRepository est l'implémentation de LinkRepository utilisant GORM.
 type GormLinkRepository struct {
-	db *gorm.DB
+	db        *gorm.DB
+	workspace *uint // Espace de travail auquel les requêtes sont restreintes ; nil pour tous (tâches de fond, administration)
+	maxLinks  int   // Nombre maximal de liens de l'espace de travail, vérifié à chaque création ; 0 pour aucune limite
 }
 
 // NewLinkRepository crée et retourne une nouvelle instance de GormLinkRepository.
@@ -27,19 +75,127 @@ func NewLinkRepository(db *gorm.DB) *GormLinkRepository {
 	return &GormLinkRepository{db: db}
 }
 
+// ForWorkspace retourne un dépôt dont toutes les requêtes sont restreintes aux liens (et aux clics) d'un espace
+// de travail ; les liens qu'il crée sont rattachés à cet espace.
+func (r *GormLinkRepository) ForWorkspace(workspaceID uint) LinkRepository {
+	return &GormLinkRepository{db: r.db, workspace: &workspaceID}
+}
+
+// WithLinkLimit retourne un dépôt qui refuse, avec ErrLinkLimitReached, toute création de liens qui porterait
+// l'espace de travail du dépôt au-delà de maxLinks liens (0 pour aucune limite). La limite ne s'applique qu'à un
+// dépôt restreint à un espace de travail (voir ForWorkspace).
+func (r *GormLinkRepository) WithLinkLimit(maxLinks int) LinkRepository {
+	limited := *r
+	limited.maxLinks = maxLinks
+	return &limited
+}
+
+// checkLinkLimit vérifie, dans la transaction qui vient d'insérer des liens, que l'espace de travail du dépôt
+// ne dépasse pas sa limite ; sinon ErrLinkLimitReached annule la transaction. Le comptage suit l'insertion :
+// SQLite n'admettant qu'une transaction d'écriture à la fois, il inclut toutes les créations concurrentes déjà
+// validées, et deux créations simultanées ne peuvent pas dépasser ensemble la limite.
+func (r *GormLinkRepository) checkLinkLimit(tx *gorm.DB) error {
+	if r.workspace == nil || r.maxLinks <= 0 {
+		return nil
+	}
+	var count int64
+	if err := tx.Model(&models.Link{}).Where("workspace_id = ?", *r.workspace).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > int64(r.maxLinks) {
+		return ErrLinkLimitReached
+	}
+	return nil
+}
+
+// links retourne une requête sur la table des liens, restreinte à l'espace de travail du dépôt.
+func (r *GormLinkRepository) links() *gorm.DB {
+	query := r.db.Model(&models.Link{})
+	if r.workspace != nil {
+		query = query.Where("links.workspace_id = ?", *r.workspace)
+	}
+	return query
+}
+
+// clicks retourne une requête sur la table des clics, restreinte à l'espace de travail du dépôt.
+func (r *GormLinkRepository) clicks() *gorm.DB {
+	query := r.db.Model(&models.Click{})
+	if r.workspace != nil {
+		query = query.Where("clicks.workspace_id = ?", *r.workspace)
+	}
+	return query
+}
+
+// assignWorkspace rattache des liens sur le point d'être créés à l'espace de travail du dépôt.
+func (r *GormLinkRepository) assignWorkspace(links ...*models.Link) {
+	if r.workspace == nil {
+		return
+	}
+	for _, link := range links {
+		link.WorkspaceID = *r.workspace
+	}
+}
+
 // CreateLink insère un nouveau lien dans la base de données.
+// Ses étiquettes sont enregistrées, et la limite de liens vérifiée, dans la même transaction.
 func (r *GormLinkRepository) CreateLink(link *models.Link) error {
 	// TODO 1: Utiliser GORM pour créer un nouvel enregistrement (link) dans la table des liens.
-	return r.db.Create(link).Error
+	r.assignWorkspace(link)
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(link).Error; err != nil {
+			return err
+		}
+		if err := r.checkLinkLimit(tx); err != nil {
+			return err
+		}
+		return saveLinkTags(tx, link)
+	})
+}
+
+// CreateLinks insère plusieurs liens dans une seule transaction : soit tous sont créés, soit aucun.
+// Ils sont tous refusés si leur création dépasse la limite de liens (ErrLinkLimitReached).
+func (r *GormLinkRepository) CreateLinks(links []*models.Link) error {
+	if len(links) == 0 {
+		return nil
+	}
+	r.assignWorkspace(links...)
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.CreateInBatches(links, 100).Error; err != nil {
+			return err
+		}
+		if err := r.checkLinkLimit(tx); err != nil {
+			return err
+		}
+		return saveLinkTags(tx, links...)
+	})
+}
+
+// ExistingShortCodes retourne, parmi les codes fournis, ceux qui sont déjà utilisés dans un domaine.
+// Le domaine par défaut est partagé par tous les espaces de travail : cette vérification n'est donc pas
+// restreinte à l'espace de travail du dépôt.
+func (r *GormLinkRepository) ExistingShortCodes(domainID uint, shortCodes []string) (map[string]bool, error) {
+	existing := make(map[string]bool)
+	for start := 0; start < len(shortCodes); start += 500 {
+		end := min(start+500, len(shortCodes))
+		var found []string
+		err := r.db.Model(&models.Link{}).Where("domain_id = ? AND short_code IN ?", domainID, shortCodes[start:end]).Pluck("short_code", &found).Error
+		if err != nil {
+			return nil, err
+		}
+		for _, code := range found {
+			existing[code] = true
+		}
+	}
+	return existing, nil
 }
 
-// GetLinkByShortCode récupère un lien de la base de données en utilisant son shortCode.
-// Il renvoie gorm.ErrRecordNotFound si aucun lien n'est trouvé avec ce shortCode.
-func (r *GormLinkRepository) GetLinkByShortCode(shortCode string) (*models.Link, error) {
+// GetLinkByShortCode récupère un lien de la base de données en utilisant son domaine et son shortCode.
+// Il renvoie gorm.ErrRecordNotFound si aucun lien n'est trouvé avec ce shortCode dans ce domaine.
+func (r *GormLinkRepository) GetLinkByShortCode(domainID uint, shortCode string) (*models.Link, error) {
 	var link models.Link
 	// TODO 2: Utiliser GORM pour trouver un lien par son ShortCode.
 	// La méthode First de GORM recherche le premier enregistrement correspondant et le mappe à 'link'.
-	err := r.db.Where("short_code = ?", shortCode).First(&link).Error
+	err := r.links().Where("domain_id = ? AND short_code = ?", domainID, shortCode).First(&link).Error
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, gorm.ErrRecordNotFound
@@ -49,26 +205,282 @@ func (r *GormLinkRepository) GetLinkByShortCode(shortCode string) (*models.Link,
 	return &link, nil
 }
 
+// GetLinkByID récupère un lien par son identifiant.
+// Il renvoie gorm.ErrRecordNotFound si aucun lien n'existe avec cet identifiant.
+func (r *GormLinkRepository) GetLinkByID(id uint) (*models.Link, error) {
+	var link models.Link
+	if err := r.links().First(&link, id).Error; err != nil {
+		return nil, err
+	}
+	return &link, nil
+}
+
+// FindLinksByURLHash récupère au plus limit liens d'un propriétaire pointant vers la même URL normalisée dans un domaine,
+// du plus ancien au plus récent.
+func (r *GormLinkRepository) FindLinksByURLHash(domainID uint, owner, urlHash string, limit int) ([]models.Link, error) {
+	var links []models.Link
+	err := r.links().Where("owner = ? AND url_hash = ? AND domain_id = ?", owner, urlHash, domainID).Order("id").Limit(limit).Find(&links).Error
+	return links, err
+}
+
+// BackfillURLHashes calcule l'empreinte des liens créés avant l'introduction de la déduplication.
+// Elle retourne le nombre de liens mis à jour.
+func (r *GormLinkRepository) BackfillURLHashes(hash func(longURL string) string) (int, error) {
+	updated := 0
+	var batch []models.Link
+	err := r.links().Where("url_hash IS NULL OR url_hash = ''").FindInBatches(&batch, streamBatchSize, func(tx *gorm.DB, _ int) error {
+		for _, link := range batch {
+			if err := r.db.Model(&models.Link{}).Where("id = ?", link.ID).Update("url_hash", hash(link.LongURL)).Error; err != nil {
+				return err
+			}
+			updated++
+		}
+		return nil
+	}).Error
+	return updated, err
+}
+
+// GetIdempotencyKey récupère une clé d'idempotence enregistrée après since.
+// Les clés plus anciennes sont considérées comme expirées : gorm.ErrRecordNotFound est alors renvoyée.
+func (r *GormLinkRepository) GetIdempotencyKey(key string, since time.Time) (*models.IdempotencyKey, error) {
+	var idempotencyKey models.IdempotencyKey
+	query := r.db.Where("key = ? AND created_at >= ?", key, since)
+	if r.workspace != nil {
+		query = query.Where("workspace_id = ?", *r.workspace)
+	}
+	err := query.First(&idempotencyKey).Error
+	if err != nil {
+		return nil, err
+	}
+	return &idempotencyKey, nil
+}
+
+// CreateLinkWithIdempotencyKey crée le lien et enregistre la clé d'idempotence associée dans une même transaction,
+// qui vérifie aussi la limite de liens.
+// Une éventuelle clé portant le même nom et enregistrée avant expiredBefore est remplacée. Si une requête concurrente a déjà
+// enregistré la clé, la contrainte d'unicité fait échouer la transaction et aucun lien n'est créé.
+func (r *GormLinkRepository) CreateLinkWithIdempotencyKey(link *models.Link, key *models.IdempotencyKey, expiredBefore time.Time) error {
+	r.assignWorkspace(link)
+	key.WorkspaceID = link.WorkspaceID
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("workspace_id = ? AND key = ? AND created_at < ?", key.WorkspaceID, key.Key, expiredBefore).Delete(&models.IdempotencyKey{}).Error
+		if err != nil {
+			return err
+		}
+		if err := tx.Create(link).Error; err != nil {
+			return err
+		}
+		if err := r.checkLinkLimit(tx); err != nil {
+			return err
+		}
+		if err := saveLinkTags(tx, link); err != nil {
+			return err
+		}
+		key.LinkID = link.ID
+		return tx.Create(key).Error
+	})
+}
+
+// codeSequenceName est le nom du compteur utilisé par la génération séquentielle des codes courts.
+const codeSequenceName = "short_codes"
+
+// NextCodeSequence incrémente atomiquement le compteur des codes courts et retourne sa nouvelle valeur.
+// Le compteur est créé à 1 lors du premier appel.
+func (r *GormLinkRepository) NextCodeSequence() (uint64, error) {
+	sequence := models.CodeSequence{Name: codeSequenceName, Value: 1}
+	err := r.db.Clauses(
+		clause.OnConflict{
+			Columns:   []clause.Column{{Name: "name"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{"value": gorm.Expr("value + 1")}),
+		},
+		clause.Returning{Columns: []clause.Column{{Name: "value"}}},
+	).Create(&sequence).Error
+	if err != nil {
+		return 0, err
+	}
+	return sequence.Value, nil
+}
+
 // GetAllLinks récupère tous les liens de la base de données.
 // Cette méthode est utilisée par le moniteur d'URLs.
 func (r *GormLinkRepository) GetAllLinks() ([]models.Link, error) {
 	var links []models.Link
 	// TODO 3: Utiliser GORM pour récupérer tous les liens.
-	err := r.db.Find(&links).Error
+	err := r.links().Find(&links).Error
 	if err != nil {
 		return nil, err
 	}
 	return links, nil
 }
 
+// CountLinks compte les liens de l'espace de travail du dépôt.
+func (r *GormLinkRepository) CountLinks() (int64, error) {
+	var count int64
+	err := r.links().Count(&count).Error
+	return count, err
+}
+
+// StreamLinks parcourt les liens correspondant au filtre, par ordre d'ID, en les chargeant par lots.
+// Contrairement à GetAllLinks, la mémoire utilisée ne dépend pas du nombre total de liens.
+// Le parcours s'arrête à la première erreur retournée par fn.
+func (r *GormLinkRepository) StreamLinks(filter LinkFilter, fn func(link *models.Link) error) error {
+	var batch []models.Link
+	return r.filteredLinks(filter).FindInBatches(&batch, streamBatchSize, func(tx *gorm.DB, _ int) error {
+		for i := range batch {
+			if err := fn(&batch[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}).Error
+}
+
+// ListLinks retourne une page des liens correspondant au filtre, du plus récent au plus ancien
+// (par pertinence d'abord pour une recherche plein texte), ainsi que le nombre total de liens correspondants.
+func (r *GormLinkRepository) ListLinks(filter LinkFilter, offset, limit int) ([]models.Link, int64, error) {
+	var total int64
+	if err := r.filteredLinks(filter).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	query := r.filteredLinks(filter)
+	if filter.Search != "" && r.searchIndexed() {
+		query = query.Order("bm25(links_fts)")
+	}
+	var links []models.Link
+	err := query.Order("links.id DESC").Offset(offset).Limit(limit).Find(&links).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return links, total, nil
+}
+
+// filteredLinks construit la requête des liens correspondant au filtre.
+// L'état de la fenêtre d'activité est évalué à l'instant présent ; les bornes sont enregistrées en UTC.
+func (r *GormLinkRepository) filteredLinks(filter LinkFilter) *gorm.DB {
+	query := r.links()
+	if filter.Owner != "" {
+		query = query.Where("owner = ?", filter.Owner)
+	}
+	if !filter.CreatedFrom.IsZero() {
+		query = query.Where("created_at >= ?", filter.CreatedFrom)
+	}
+	if !filter.CreatedTo.IsZero() {
+		query = query.Where("created_at < ?", filter.CreatedTo)
+	}
+	if filter.Tag != "" {
+		query = query.Where("links.id IN (SELECT link_tags.link_id FROM link_tags JOIN tags ON tags.id = link_tags.tag_id WHERE tags.name = ?)", filter.Tag)
+	}
+	if filter.Search != "" {
+		query = r.applySearch(query, filter.Search)
+	}
+	now := time.Now().UTC()
+	switch filter.Status {
+	case models.LinkStatusScheduled:
+		query = query.Where("active_from IS NOT NULL AND active_from > ?", now)
+	case models.LinkStatusActive:
+		query = query.Where("(active_from IS NULL OR active_from <= ?) AND (active_until IS NULL OR active_until > ?)", now, now)
+	case models.LinkStatusEnded:
+		query = query.Where("active_until IS NOT NULL AND active_until <= ?", now)
+	}
+	return query
+}
+
+// DisableLink désactive un lien : il est conservé (avec ses statistiques) mais ne redirige plus.
+func (r *GormLinkRepository) DisableLink(id uint, reason string) error {
+	return r.links().Where("id = ?", id).Updates(map[string]interface{}{
+		"disabled":        true,
+		"disabled_reason": reason,
+	}).Error
+}
+
+// SavePageInfo enregistre les métadonnées de la page de destination d'un lien (champs Page*) et la date de leur
+// récupération, sans modifier UpdatedAt : elles ne sont pas une modification du lien par son propriétaire.
+func (r *GormLinkRepository) SavePageInfo(link *models.Link) error {
+	return r.links().Where("id = ?", link.ID).UpdateColumns(map[string]interface{}{
+		"page_title":       link.PageTitle,
+		"page_description": link.PageDescription,
+		"page_image":       link.PageImage,
+		"page_favicon":     link.PageFavicon,
+		"page_fetched_at":  link.PageFetchedAt,
+	}).Error
+}
+
+// IncrementFailedPasswordAttempts incrémente atomiquement le compteur de mots de passe erronés d'un lien.
+func (r *GormLinkRepository) IncrementFailedPasswordAttempts(id uint) error {
+	return r.links().Where("id = ?", id).
+		UpdateColumn("failed_password_attempts", gorm.Expr("failed_password_attempts + 1")).Error
+}
+
+// ConsumeView consomme atomiquement l'une des redirections d'un lien à usage limité, et retourne false si elles ont
+// déjà toutes été consommées. La condition et l'incrément tiennent en une seule requête UPDATE : deux requêtes
+// concurrentes, même traitées par deux instances du serveur, ne peuvent pas consommer la même redirection.
+// Avec eraseDestination, la même requête efface la destination (URL longue, règles, variantes) lorsqu'elle consomme
+// la dernière redirection.
+func (r *GormLinkRepository) ConsumeView(id uint, eraseDestination bool) (bool, error) {
+	updates := map[string]interface{}{"views": gorm.Expr("views + 1")}
+	if eraseDestination {
+		// Les expressions voient les valeurs de la ligne avant la mise à jour
+		updates["long_url"] = gorm.Expr("CASE WHEN views + 1 >= max_views THEN '' ELSE long_url END")
+		updates["redirect_rules"] = gorm.Expr("CASE WHEN views + 1 >= max_views THEN '' ELSE redirect_rules END")
+		updates["split_variants"] = gorm.Expr("CASE WHEN views + 1 >= max_views THEN '' ELSE split_variants END")
+	}
+	result := r.links().
+		Where("id = ? AND max_views > 0 AND views < max_views", id).
+		UpdateColumns(updates)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
 // CountClicksByLinkID compte le nombre total de clics pour un ID de lien donné.
 func (r *GormLinkRepository) CountClicksByLinkID(linkID uint) (int, error) {
 	var count int64 // GORM retourne un int64 pour les comptes
 	// TODO 4: Utiliser GORM pour compter les enregistrements dans la table 'clicks'
 	// où 'LinkID' correspond à l'ID du lien donné.
-	err := r.db.Model(&models.Click{}).Where("link_id = ?", linkID).Count(&count).Error
+	err := r.clicks().Where("link_id = ?", linkID).Count(&count).Error
 	if err != nil {
 		return 0, err
 	}
 	return int(count), nil
 }
+
+// ClickGroupColumns liste les colonnes de la table des clics selon lesquelles les clics peuvent être comptés.
+var ClickGroupColumns = map[string]bool{
+	"source":  true,
+	"rule":    true,
+	"country": true,
+	"variant": true,
+}
+
+// CountClicksGroupedBy compte les clics d'un lien par valeur d'une colonne (ex: "source"), en ignorant les valeurs vides.
+// La colonne doit figurer dans ClickGroupColumns.
+func (r *GormLinkRepository) CountClicksGroupedBy(linkID uint, column string) (map[string]int, error) {
+	if !ClickGroupColumns[column] {
+		return nil, fmt.Errorf("clicks cannot be grouped by %q", column)
+	}
+	var rows []struct {
+		Value string
+		Count int
+	}
+	err := r.clicks().
+		Select(column+" AS value, COUNT(*) AS count").
+		Where("link_id = ? AND "+column+" <> ''", linkID).
+		Group(column).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	counts := make(map[string]int, len(rows))
+	for _, row := range rows {
+		counts[row.Value] = row.Count
+	}
+	return counts, nil
+}
+
+// CountClicksSince compte les clics enregistrés depuis since sur les liens de l'espace de travail du dépôt.
+func (r *GormLinkRepository) CountClicksSince(since time.Time) (int64, error) {
+	var count int64
+	err := r.clicks().Where("timestamp >= ?", since).Count(&count).Error
+	return count, err
+}
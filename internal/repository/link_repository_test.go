@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+	"github.com/axellelanca/urlshortener/internal/testutil"
+)
+
+// consumeConcurrently lance attempts appels concurrents à ConsumeView sur un lien et retourne le nombre de
+// redirections accordées.
+func consumeConcurrently(t *testing.T, repo *GormLinkRepository, linkID uint, attempts int, eraseDestination bool) int {
+	t.Helper()
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		granted int
+		start   = make(chan struct{})
+	)
+	for i := 0; i < attempts; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			ok, err := repo.ConsumeView(linkID, eraseDestination)
+			if err != nil {
+				t.Errorf("ConsumeView : %v", err)
+				return
+			}
+			if ok {
+				mu.Lock()
+				granted++
+				mu.Unlock()
+			}
+		}()
+	}
+	close(start)
+	wg.Wait()
+	return granted
+}
+
+func TestConsumeViewIsAtomic(t *testing.T) {
+	repo := NewLinkRepository(testutil.NewDB(t))
+	link := &models.Link{ShortCode: "secret", LongURL: "https://example.com/identifiants", MaxViews: 1, SelfDestruct: true}
+	if err := repo.CreateLink(link); err != nil {
+		t.Fatalf("création du lien : %v", err)
+	}
+
+	if granted := consumeConcurrently(t, repo, link.ID, 25, true); granted != 1 {
+		t.Fatalf("%d redirections accordées pour max_views=1, attendu exactement 1", granted)
+	}
+
+	saved, err := repo.GetLinkByID(link.ID)
+	if err != nil {
+		t.Fatalf("lecture du lien : %v", err)
+	}
+	if saved.Views != 1 {
+		t.Errorf("Views = %d, attendu 1", saved.Views)
+	}
+	if saved.LongURL != "" {
+		t.Errorf("LongURL = %q : la destination doit être effacée à la dernière redirection", saved.LongURL)
+	}
+}
+
+func TestConsumeViewHonoursLimit(t *testing.T) {
+	repo := NewLinkRepository(testutil.NewDB(t))
+	link := &models.Link{ShortCode: "trois", LongURL: "https://example.com/", MaxViews: 3}
+	if err := repo.CreateLink(link); err != nil {
+		t.Fatalf("création du lien : %v", err)
+	}
+
+	if granted := consumeConcurrently(t, repo, link.ID, 20, false); granted != 3 {
+		t.Fatalf("%d redirections accordées pour max_views=3, attendu exactement 3", granted)
+	}
+
+	saved, err := repo.GetLinkByID(link.ID)
+	if err != nil {
+		t.Fatalf("lecture du lien : %v", err)
+	}
+	if saved.Views != 3 || saved.LongURL == "" {
+		t.Errorf("Views = %d, LongURL = %q : attendu 3 vues et la destination conservée", saved.Views, saved.LongURL)
+	}
+}
+
+func TestConsumeViewIgnoresUnlimitedLinks(t *testing.T) {
+	repo := NewLinkRepository(testutil.NewDB(t))
+	link := &models.Link{ShortCode: "libre", LongURL: "https://example.com/"}
+	if err := repo.CreateLink(link); err != nil {
+		t.Fatalf("création du lien : %v", err)
+	}
+
+	ok, err := repo.ConsumeView(link.ID, true)
+	if err != nil {
+		t.Fatalf("ConsumeView : %v", err)
+	}
+	if ok {
+		t.Error("ConsumeView ne s'applique qu'aux liens à usage limité")
+	}
+}
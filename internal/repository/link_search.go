@@ -0,0 +1,150 @@
+package repository
+
+import (
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+)
+
+// searchTable est la table virtuelle FTS5 indexant le texte des liens (SQLite uniquement).
+// Son rowid est l'ID du lien ; elle est tenue à jour par des triggers sur links et link_tags.
+const searchTable = "links_fts"
+
+// searchIndexStatements crée l'index plein texte des liens et les triggers qui le synchronisent,
+// puis l'alimente avec les liens existants.
+var searchIndexStatements = []string{
+	`CREATE VIRTUAL TABLE links_fts USING fts5(short_code, long_url, title, description, tags, tokenize = 'unicode61 remove_diacritics 2')`,
+	`CREATE TRIGGER links_fts_insert AFTER INSERT ON links BEGIN
+		INSERT INTO links_fts (rowid, short_code, long_url, title, description, tags)
+		VALUES (new.id, new.short_code, new.long_url, new.title, new.description, '');
+	END`,
+	`CREATE TRIGGER links_fts_update AFTER UPDATE OF short_code, long_url, title, description ON links BEGIN
+		UPDATE links_fts SET short_code = new.short_code, long_url = new.long_url, title = new.title, description = new.description
+		WHERE rowid = new.id;
+	END`,
+	`CREATE TRIGGER links_fts_delete AFTER DELETE ON links BEGIN
+		DELETE FROM links_fts WHERE rowid = old.id;
+	END`,
+	`CREATE TRIGGER link_tags_fts_insert AFTER INSERT ON link_tags BEGIN
+		UPDATE links_fts SET tags = (SELECT group_concat(tags.name, ' ') FROM link_tags JOIN tags ON tags.id = link_tags.tag_id WHERE link_tags.link_id = new.link_id)
+		WHERE rowid = new.link_id;
+	END`,
+	`CREATE TRIGGER link_tags_fts_delete AFTER DELETE ON link_tags BEGIN
+		UPDATE links_fts SET tags = coalesce((SELECT group_concat(tags.name, ' ') FROM link_tags JOIN tags ON tags.id = link_tags.tag_id WHERE link_tags.link_id = old.link_id), '')
+		WHERE rowid = old.link_id;
+	END`,
+	`INSERT INTO links_fts (rowid, short_code, long_url, title, description, tags)
+	SELECT links.id, links.short_code, links.long_url, links.title, links.description,
+		coalesce((SELECT group_concat(tags.name, ' ') FROM link_tags JOIN tags ON tags.id = link_tags.tag_id WHERE link_tags.link_id = links.id), '')
+	FROM links`,
+}
+
+// EnsureSearchIndex crée l'index plein texte FTS5 des liens s'il n'existe pas encore, et indique s'il vient d'être créé.
+// Il n'est disponible que sur SQLite : sur les autres bases, la recherche se rabat sur des comparaisons LIKE.
+func (r *GormLinkRepository) EnsureSearchIndex() (bool, error) {
+	if r.db.Dialector.Name() != "sqlite" || r.db.Migrator().HasTable(searchTable) {
+		return false, nil
+	}
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		for _, statement := range searchIndexStatements {
+			if err := tx.Exec(statement).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return err == nil, err
+}
+
+// searchIndexed indique si la recherche peut utiliser l'index plein texte.
+func (r *GormLinkRepository) searchIndexed() bool {
+	return r.db.Dialector.Name() == "sqlite" && r.db.Migrator().HasTable(searchTable)
+}
+
+// searchTerms découpe une recherche en mots.
+func searchTerms(search string) []string {
+	return strings.Fields(search)
+}
+
+// ftsQuery traduit une recherche en requête FTS5 : chaque mot est cherché comme préfixe, et tous doivent
+// apparaître. Les mots sont placés entre guillemets pour que la syntaxe FTS5 (opérateurs, "-", ":"...) soit ignorée.
+func ftsQuery(search string) string {
+	terms := searchTerms(search)
+	for i, term := range terms {
+		terms[i] = `"` + strings.ReplaceAll(term, `"`, `""`) + `"*`
+	}
+	return strings.Join(terms, " ")
+}
+
+// applySearch restreint une requête de liens à ceux qui correspondent à la recherche : via l'index plein texte
+// s'il existe (la table links_fts est alors jointe, pour le tri par pertinence), sinon en cherchant chaque mot
+// dans le titre, la description, l'URL, le code court et les étiquettes.
+func (r *GormLinkRepository) applySearch(query *gorm.DB, search string) *gorm.DB {
+	if r.searchIndexed() {
+		return query.Joins("JOIN links_fts ON links_fts.rowid = links.id").Where("links_fts MATCH ?", ftsQuery(search))
+	}
+	for _, term := range searchTerms(search) {
+		pattern := "%" + strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(strings.ToLower(term)) + "%"
+		query = query.Where(`LOWER(links.title) LIKE ? ESCAPE '\' OR LOWER(links.description) LIKE ? ESCAPE '\'
+			OR LOWER(links.long_url) LIKE ? ESCAPE '\' OR LOWER(links.short_code) LIKE ? ESCAPE '\'
+			OR links.id IN (SELECT link_tags.link_id FROM link_tags JOIN tags ON tags.id = link_tags.tag_id WHERE tags.name LIKE ? ESCAPE '\')`,
+			pattern, pattern, pattern, pattern, pattern)
+	}
+	return query
+}
+
+// saveLinkTags associe aux liens qui viennent d'être créés leurs étiquettes (champ Tags, par nom),
+// en créant celles qui n'existent pas encore. Les IDs des étiquettes sont renseignés.
+func saveLinkTags(tx *gorm.DB, links ...*models.Link) error {
+	for _, link := range links {
+		for i := range link.Tags {
+			tag := &link.Tags[i]
+			if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.Tag{Name: tag.Name}).Error; err != nil {
+				return err
+			}
+			if err := tx.Where("name = ?", tag.Name).First(tag).Error; err != nil {
+				return err
+			}
+			if err := tx.Create(&models.LinkTag{LinkID: link.ID, TagID: tag.ID}).Error; err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// LoadLinkTags renseigne les étiquettes (champ Tags) des liens fournis, triées par nom.
+func (r *GormLinkRepository) LoadLinkTags(links ...*models.Link) error {
+	if len(links) == 0 {
+		return nil
+	}
+	byID := make(map[uint]*models.Link, len(links))
+	ids := make([]uint, 0, len(links))
+	for _, link := range links {
+		link.Tags = nil
+		byID[link.ID] = link
+		ids = append(ids, link.ID)
+	}
+
+	var rows []struct {
+		LinkID uint
+		models.Tag
+	}
+	err := r.db.Table("link_tags").
+		Select("link_tags.link_id, tags.id, tags.name, tags.created_at").
+		Joins("JOIN tags ON tags.id = link_tags.tag_id").
+		Where("link_tags.link_id IN ?", ids).
+		Order("tags.name").
+		Scan(&rows).Error
+	if err != nil {
+		return err
+	}
+	for _, row := range rows {
+		link := byID[row.LinkID]
+		link.Tags = append(link.Tags, row.Tag)
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"github.com/axellelanca/urlshortener/internal/models"
+	"gorm.io/gorm"
+)
+
+// WorkspaceRepository est une interface qui définit les méthodes d'accès aux données
+// pour les espaces de travail et leurs clés d'API.
+type WorkspaceRepository interface {
+	CreateWorkspace(workspace *models.Workspace) error
+	GetWorkspaceByName(name string) (*models.Workspace, error)
+	GetWorkspaceByID(id uint) (*models.Workspace, error)
+	GetAllWorkspaces() ([]models.Workspace, error)
+	UpdateWorkspaceQuotas(workspace *models.Workspace) error
+	CreateAPIKey(key *models.APIKey) error
+	GetAPIKeyByHash(keyHash string) (*models.APIKey, error)
+	MoveLinks(linkIDs []uint, workspaceID uint, maxLinks int) error
+}
+
+// GormWorkspaceRepository est l'implémentation de WorkspaceRepository utilisant GORM.
+type GormWorkspaceRepository struct {
+	db *gorm.DB
+}
+
+// NewWorkspaceRepository crée et retourne une nouvelle instance de GormWorkspaceRepository.
+func NewWorkspaceRepository(db *gorm.DB) *GormWorkspaceRepository {
+	return &GormWorkspaceRepository{db: db}
+}
+
+// CreateWorkspace insère un nouvel espace de travail dans la base de données.
+func (r *GormWorkspaceRepository) CreateWorkspace(workspace *models.Workspace) error {
+	return r.db.Create(workspace).Error
+}
+
+// GetWorkspaceByName récupère un espace de travail par son nom.
+// Il renvoie gorm.ErrRecordNotFound si aucun espace de travail ne correspond.
+func (r *GormWorkspaceRepository) GetWorkspaceByName(name string) (*models.Workspace, error) {
+	var workspace models.Workspace
+	if err := r.db.Where("name = ?", name).First(&workspace).Error; err != nil {
+		return nil, err
+	}
+	return &workspace, nil
+}
+
+// GetWorkspaceByID récupère un espace de travail par son identifiant.
+// Il renvoie gorm.ErrRecordNotFound si aucun espace de travail ne correspond.
+func (r *GormWorkspaceRepository) GetWorkspaceByID(id uint) (*models.Workspace, error) {
+	var workspace models.Workspace
+	if err := r.db.First(&workspace, id).Error; err != nil {
+		return nil, err
+	}
+	return &workspace, nil
+}
+
+// GetAllWorkspaces récupère tous les espaces de travail, par ordre de création.
+func (r *GormWorkspaceRepository) GetAllWorkspaces() ([]models.Workspace, error) {
+	var workspaces []models.Workspace
+	if err := r.db.Order("id").Find(&workspaces).Error; err != nil {
+		return nil, err
+	}
+	return workspaces, nil
+}
+
+// UpdateWorkspaceQuotas enregistre les quotas (MaxLinks, MaxClicksPerMonth) d'un espace de travail.
+func (r *GormWorkspaceRepository) UpdateWorkspaceQuotas(workspace *models.Workspace) error {
+	return r.db.Model(&models.Workspace{}).Where("id = ?", workspace.ID).Updates(map[string]interface{}{
+		"max_links":            workspace.MaxLinks,
+		"max_clicks_per_month": workspace.MaxClicksPerMonth,
+	}).Error
+}
+
+// CreateAPIKey insère une nouvelle clé d'API dans la base de données.
+func (r *GormWorkspaceRepository) CreateAPIKey(key *models.APIKey) error {
+	return r.db.Create(key).Error
+}
+
+// GetAPIKeyByHash récupère une clé d'API par l'empreinte de la clé.
+// Il renvoie gorm.ErrRecordNotFound si aucune clé ne correspond.
+func (r *GormWorkspaceRepository) GetAPIKeyByHash(keyHash string) (*models.APIKey, error) {
+	var key models.APIKey
+	if err := r.db.Where("key_hash = ?", keyHash).First(&key).Error; err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// MoveLinks rattache des liens, ainsi que leurs clics, à un autre espace de travail, dans une seule transaction.
+// Si l'espace de travail compte alors plus de maxLinks liens (0 pour aucune limite), la transaction est annulée
+// avec ErrLinkLimitReached ; comme pour les créations (voir checkLinkLimit), le comptage suit les mises à jour
+// pour tenir compte des écritures concurrentes.
+func (r *GormWorkspaceRepository) MoveLinks(linkIDs []uint, workspaceID uint, maxLinks int) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for start := 0; start < len(linkIDs); start += streamBatchSize {
+			ids := linkIDs[start:min(start+streamBatchSize, len(linkIDs))]
+			err := tx.Model(&models.Link{}).Where("id IN ?", ids).Update("workspace_id", workspaceID).Error
+			if err != nil {
+				return err
+			}
+			err = tx.Model(&models.Click{}).Where("link_id IN ?", ids).Update("workspace_id", workspaceID).Error
+			if err != nil {
+				return err
+			}
+		}
+		if maxLinks <= 0 {
+			return nil
+		}
+		var count int64
+		if err := tx.Model(&models.Link{}).Where("workspace_id = ?", workspaceID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > int64(maxLinks) {
+			return ErrLinkLimitReached
+		}
+		return nil
+	})
+}
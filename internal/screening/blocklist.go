@@ -0,0 +1,208 @@
+package screening
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrBlocked est retournée (enveloppée avec la règle en cause) lorsqu'une destination figure dans la liste de blocage.
+var ErrBlocked = errors.New("destination is blocklisted")
+
+// refreshInterval est le délai minimal entre deux vérifications des dates de modification des fichiers.
+const refreshInterval = 30 * time.Second
+
+// Types de fichiers de blocage supportés.
+const (
+	SourceDomains = "domains" // Un domaine par ligne ; bloque aussi ses sous-domaines
+	SourceRegexps = "regexps" // Une expression régulière par ligne, appliquée à l'URL complète
+	SourceHosts   = "hosts"   // Format /etc/hosts : "0.0.0.0 domaine.com" (flux publics de blocage)
+)
+
+// source est un fichier de blocage suivi, rechargé lorsque sa date de modification change.
+type source struct {
+	path    string
+	kind    string
+	modTime time.Time
+}
+
+// Blocklist vérifie les URLs de destination contre des listes locales de domaines et d'expressions régulières.
+// Elle peut être utilisée par plusieurs goroutines à la fois.
+type Blocklist struct {
+	mu          sync.RWMutex
+	sources     []*source
+	domains     map[string]string // domaine -> fichier d'origine
+	patterns    []*regexp.Regexp
+	lastRefresh time.Time
+}
+
+// Files liste les fichiers de blocage à charger, par type.
+type Files struct {
+	Domains []string
+	Regexps []string
+	Hosts   []string
+}
+
+// NewBlocklist crée une liste de blocage et charge les fichiers fournis.
+// Une erreur est retournée si un fichier est illisible ou contient une expression régulière invalide.
+func NewBlocklist(files Files) (*Blocklist, error) {
+	b := &Blocklist{domains: make(map[string]string)}
+	for _, path := range files.Domains {
+		b.sources = append(b.sources, &source{path: path, kind: SourceDomains})
+	}
+	for _, path := range files.Regexps {
+		b.sources = append(b.sources, &source{path: path, kind: SourceRegexps})
+	}
+	for _, path := range files.Hosts {
+		b.sources = append(b.sources, &source{path: path, kind: SourceHosts})
+	}
+	if _, err := b.Reload(); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// Empty indique qu'aucun fichier de blocage n'est configuré.
+func (b *Blocklist) Empty() bool {
+	return len(b.sources) == 0
+}
+
+// Reload relit les fichiers dont la date de modification a changé depuis le dernier chargement.
+// Elle retourne true si la liste a été rechargée. En cas d'erreur, la liste précédente est conservée.
+func (b *Blocklist) Reload() (bool, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.lastRefresh = time.Now()
+
+	changed := false
+	modTimes := make([]time.Time, len(b.sources))
+	for i, src := range b.sources {
+		info, err := os.Stat(src.path)
+		if err != nil {
+			return false, fmt.Errorf("failed to stat blocklist %s: %w", src.path, err)
+		}
+		modTimes[i] = info.ModTime()
+		if !info.ModTime().Equal(src.modTime) {
+			changed = true
+		}
+	}
+	if !changed {
+		return false, nil
+	}
+
+	domains := make(map[string]string)
+	var patterns []*regexp.Regexp
+	for _, src := range b.sources {
+		lines, err := readLines(src.path)
+		if err != nil {
+			return false, err
+		}
+		for _, line := range lines {
+			switch src.kind {
+			case SourceDomains:
+				domains[strings.ToLower(strings.TrimSuffix(line, "."))] = src.path
+			case SourceHosts:
+				fields := strings.Fields(line)
+				for _, host := range fields[1:] {
+					host = strings.ToLower(strings.TrimSuffix(host, "."))
+					if host != "localhost" && host != "localhost.localdomain" && host != "broadcasthost" {
+						domains[host] = src.path
+					}
+				}
+			case SourceRegexps:
+				pattern, err := regexp.Compile(line)
+				if err != nil {
+					return false, fmt.Errorf("invalid pattern %q in %s: %w", line, src.path, err)
+				}
+				patterns = append(patterns, pattern)
+			}
+		}
+	}
+
+	b.domains = domains
+	b.patterns = patterns
+	for i, src := range b.sources {
+		src.modTime = modTimes[i]
+	}
+	return true, nil
+}
+
+// readLines lit un fichier de blocage en ignorant les lignes vides et les commentaires (#).
+func readLines(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open blocklist %s: %w", path, err)
+	}
+	defer file.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read blocklist %s: %w", path, err)
+	}
+	return lines, nil
+}
+
+// refreshIfStale recharge les fichiers modifiés si la dernière vérification date de plus de refreshInterval.
+func (b *Blocklist) refreshIfStale() {
+	b.mu.RLock()
+	stale := time.Since(b.lastRefresh) > refreshInterval
+	b.mu.RUnlock()
+	if !stale {
+		return
+	}
+	if _, err := b.Reload(); err != nil {
+		log.Printf("[BLOCKLIST] Échec du rechargement, la liste précédente est conservée : %v", err)
+	}
+}
+
+// Check vérifie une URL de destination. Elle retourne une erreur enveloppant ErrBlocked si l'hôte
+// (ou l'un de ses domaines parents) est bloqué, ou si l'URL correspond à une expression régulière.
+func (b *Blocklist) Check(rawURL string) error {
+	if b == nil || b.Empty() {
+		return nil
+	}
+	b.refreshIfStale()
+
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return nil
+	}
+	host := strings.ToLower(strings.TrimSuffix(parsed.Hostname(), "."))
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for domain := host; domain != ""; {
+		if origin, ok := b.domains[domain]; ok {
+			return fmt.Errorf("%w: domain %q is listed in %s", ErrBlocked, domain, origin)
+		}
+		i := strings.Index(domain, ".")
+		if i < 0 {
+			break
+		}
+		domain = domain[i+1:]
+	}
+	for _, pattern := range b.patterns {
+		if pattern.MatchString(rawURL) {
+			return fmt.Errorf("%w: url matches pattern %q", ErrBlocked, pattern.String())
+		}
+	}
+	return nil
+}
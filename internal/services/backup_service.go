@@ -0,0 +1,277 @@
+package services
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/repository"
+)
+
+// Formats de sauvegarde supportés.
+const (
+	BackupFormatSQLite = "sqlite" // Copie binaire de la base via VACUUM INTO (SQLite uniquement)
+	BackupFormatJSON   = "json"   // Dump logique indépendant du driver
+)
+
+// Modes de restauration supportés.
+const (
+	RestoreModeMerge   = "merge"   // Ajoute les lignes absentes et conserve les lignes existantes
+	RestoreModeReplace = "replace" // Vide les tables sauvegardées puis les recharge intégralement
+)
+
+// jsonBackupVersion est la version du format de dump JSON produit par Backup.
+const jsonBackupVersion = 1
+
+// sqliteHeader est la signature présente au début de tout fichier de base SQLite.
+var sqliteHeader = []byte("SQLite format 3\x00")
+
+// sqliteTimeLayout est le format utilisé par le driver SQLite pour stocker les dates.
+// Les dates du dump JSON sont écrites dans ce format pour être relues à l'identique.
+const sqliteTimeLayout = "2006-01-02 15:04:05.999999999-07:00"
+
+// ErrInvalidBackup est retournée lorsqu'un fichier de sauvegarde est illisible ou corrompu.
+var ErrInvalidBackup = errors.New("invalid backup file")
+
+// jsonBackup est l'enveloppe d'un dump JSON. Le checksum porte sur le contenu brut du champ Tables.
+type jsonBackup struct {
+	FormatVersion int             `json:"format_version"`
+	Driver        string          `json:"driver"`
+	CreatedAt     time.Time       `json:"created_at"`
+	Checksum      string          `json:"checksum"`
+	Tables        json.RawMessage `json:"tables"`
+}
+
+// TableRestorePlan résume l'effet d'une restauration sur une table, utilisé pour le mode dry-run.
+type TableRestorePlan struct {
+	Table       string
+	BackupRows  int  // Lignes présentes dans la sauvegarde
+	CurrentRows int  // Lignes présentes actuellement en base
+	ToInsert    int  // Lignes qui seront insérées
+	ToSkip      int  // Lignes de la sauvegarde ignorées car déjà présentes, ou dépendant d'une ligne ignorée (mode merge)
+	ToDelete    int  // Lignes actuelles supprimées (mode replace)
+	Missing     bool // Table absente de la sauvegarde : son contenu actuel est conservé
+}
+
+// BackupService fournit la logique de sauvegarde et de restauration de la base de liens.
+type BackupService struct {
+	backupRepo repository.BackupRepository
+}
+
+// NewBackupService crée et retourne une nouvelle instance de BackupService.
+func NewBackupService(backupRepo repository.BackupRepository) *BackupService {
+	return &BackupService{
+		backupRepo: backupRepo,
+	}
+}
+
+// DefaultFormat retourne le format de sauvegarde le plus adapté au driver configuré.
+func (s *BackupService) DefaultFormat() string {
+	if s.backupRepo.Driver() == "sqlite" {
+		return BackupFormatSQLite
+	}
+	return BackupFormatJSON
+}
+
+// Backup écrit une sauvegarde de la base dans le fichier indiqué, qui ne doit pas déjà exister.
+func (s *BackupService) Backup(path, format string) error {
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("backup file %s already exists", path)
+	}
+
+	switch format {
+	case BackupFormatSQLite:
+		if err := s.backupRepo.VacuumInto(path); err != nil {
+			return fmt.Errorf("failed to write sqlite backup: %w", err)
+		}
+		return nil
+	case BackupFormatJSON:
+		return s.writeJSONBackup(path)
+	default:
+		return fmt.Errorf("unsupported backup format %q", format)
+	}
+}
+
+// writeJSONBackup produit un dump logique de toutes les tables accompagné de son checksum SHA-256.
+func (s *BackupService) writeJSONBackup(path string) error {
+	data, err := s.backupRepo.ReadTables()
+	if err != nil {
+		return err
+	}
+	for _, rows := range data {
+		for _, row := range rows {
+			for column, value := range row {
+				if t, ok := value.(time.Time); ok {
+					row[column] = t.Format(sqliteTimeLayout)
+				}
+			}
+		}
+	}
+
+	tables, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to encode tables: %w", err)
+	}
+	sum := sha256.Sum256(tables)
+	payload, err := json.Marshal(jsonBackup{
+		FormatVersion: jsonBackupVersion,
+		Driver:        s.backupRepo.Driver(),
+		CreatedAt:     time.Now(),
+		Checksum:      hex.EncodeToString(sum[:]),
+		Tables:        tables,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode backup: %w", err)
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to create backup file: %w", err)
+	}
+	if _, err := file.Write(payload); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write backup file: %w", err)
+	}
+	return file.Close()
+}
+
+// LoadBackup lit un fichier de sauvegarde (format détecté automatiquement), vérifie son intégrité
+// et retourne son contenu ainsi que le format détecté.
+func LoadBackup(path string) (map[string]repository.TableRows, string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to open backup file: %w", err)
+	}
+	header := make([]byte, len(sqliteHeader))
+	n, _ := io.ReadFull(file, header)
+	file.Close()
+
+	if n == len(sqliteHeader) && bytes.Equal(header, sqliteHeader) {
+		data, err := repository.ReadSQLiteBackup(path)
+		if err != nil {
+			return nil, "", fmt.Errorf("%w: %v", ErrInvalidBackup, err)
+		}
+		return data, BackupFormatSQLite, nil
+	}
+
+	data, err := readJSONBackup(path)
+	if err != nil {
+		return nil, "", err
+	}
+	return data, BackupFormatJSON, nil
+}
+
+// readJSONBackup décode un dump JSON et vérifie que son checksum correspond à son contenu.
+func readJSONBackup(path string) (map[string]repository.TableRows, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backup file: %w", err)
+	}
+
+	var backup jsonBackup
+	if err := json.Unmarshal(content, &backup); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidBackup, err)
+	}
+	if backup.FormatVersion != jsonBackupVersion {
+		return nil, fmt.Errorf("%w: unsupported format version %d", ErrInvalidBackup, backup.FormatVersion)
+	}
+	sum := sha256.Sum256(backup.Tables)
+	if hex.EncodeToString(sum[:]) != backup.Checksum {
+		return nil, fmt.Errorf("%w: checksum mismatch", ErrInvalidBackup)
+	}
+
+	// UseNumber évite de perdre en précision les identifiants et compteurs lors du décodage.
+	decoder := json.NewDecoder(bytes.NewReader(backup.Tables))
+	decoder.UseNumber()
+	var data map[string]repository.TableRows
+	if err := decoder.Decode(&data); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidBackup, err)
+	}
+	for _, rows := range data {
+		for _, row := range rows {
+			for column, value := range row {
+				number, ok := value.(json.Number)
+				if !ok {
+					continue
+				}
+				if i, err := number.Int64(); err == nil {
+					row[column] = i
+				} else if f, err := number.Float64(); err == nil {
+					row[column] = f
+				}
+			}
+		}
+	}
+	return data, nil
+}
+
+// PlanRestore calcule, sans rien modifier, l'effet qu'aurait la restauration des données fournies.
+func (s *BackupService) PlanRestore(data map[string]repository.TableRows, mode string) ([]TableRestorePlan, error) {
+	if mode != RestoreModeMerge && mode != RestoreModeReplace {
+		return nil, fmt.Errorf("unsupported restore mode %q", mode)
+	}
+
+	tables, err := s.backupRepo.Tables()
+	if err != nil {
+		return nil, err
+	}
+
+	var skipped map[string]int
+	if mode == RestoreModeMerge {
+		if skipped, err = s.backupRepo.CountSkippedRows(data); err != nil {
+			return nil, fmt.Errorf("failed to compare rows: %w", err)
+		}
+	}
+
+	plans := make([]TableRestorePlan, 0, len(tables))
+	for _, table := range tables {
+		rows, found := data[table]
+		current, err := s.backupRepo.CountRows(table)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count rows in %s: %w", table, err)
+		}
+		plan := TableRestorePlan{Table: table, BackupRows: len(rows), CurrentRows: current, Missing: !found}
+
+		if mode == RestoreModeReplace {
+			if found {
+				plan.ToDelete = current
+			}
+			plan.ToInsert = len(rows)
+		} else {
+			plan.ToSkip = skipped[table]
+			plan.ToInsert = len(rows) - skipped[table]
+		}
+		plans = append(plans, plan)
+	}
+	return plans, nil
+}
+
+// Restore applique les données d'une sauvegarde selon le mode choisi.
+// Elle retourne le nombre de lignes insérées par table.
+func (s *BackupService) Restore(data map[string]repository.TableRows, mode string) (map[string]int, error) {
+	switch mode {
+	case RestoreModeReplace:
+		if err := s.backupRepo.ReplaceTables(data); err != nil {
+			return nil, fmt.Errorf("failed to replace tables: %w", err)
+		}
+		inserted := make(map[string]int, len(data))
+		for table, rows := range data {
+			inserted[table] = len(rows)
+		}
+		return inserted, nil
+	case RestoreModeMerge:
+		inserted, err := s.backupRepo.MergeTables(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to merge tables: %w", err)
+		}
+		return inserted, nil
+	default:
+		return nil, fmt.Errorf("unsupported restore mode %q", mode)
+	}
+}
@@ -0,0 +1,157 @@
+package services
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+	"github.com/axellelanca/urlshortener/internal/repository"
+	"github.com/axellelanca/urlshortener/internal/testutil"
+	"gorm.io/gorm"
+)
+
+// backupOf sauvegarde une base au format JSON puis relit la sauvegarde, comme le fait la commande restore.
+func backupOf(t *testing.T, db *gorm.DB) map[string]repository.TableRows {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "backup.json")
+	if err := NewBackupService(repository.NewBackupRepository(db)).Backup(path, BackupFormatJSON); err != nil {
+		t.Fatalf("Backup : %v", err)
+	}
+	data, format, err := LoadBackup(path)
+	if err != nil {
+		t.Fatalf("LoadBackup : %v", err)
+	}
+	if format != BackupFormatJSON {
+		t.Fatalf("format détecté %q, attendu %q", format, BackupFormatJSON)
+	}
+	return data
+}
+
+// planFor retourne le plan de restauration d'une table.
+func planFor(t *testing.T, plans []TableRestorePlan, table string) TableRestorePlan {
+	t.Helper()
+	for _, plan := range plans {
+		if plan.Table == table {
+			return plan
+		}
+	}
+	t.Fatalf("aucun plan pour la table %s", table)
+	return TableRestorePlan{}
+}
+
+func countRows(t *testing.T, db *gorm.DB, model interface{}) int64 {
+	t.Helper()
+	var count int64
+	if err := db.Model(model).Count(&count).Error; err != nil {
+		t.Fatalf("comptage : %v", err)
+	}
+	return count
+}
+
+func TestRestoreMergeSkipsConflictingRowsAndTheirClicks(t *testing.T) {
+	source := testutil.NewDB(t)
+	source.Create(&models.Link{ID: 1, LongURL: "https://example.com/a", ShortCode: "aaaaaa"})
+	source.Create(&models.Link{ID: 2, LongURL: "https://example.com/b", ShortCode: "bbbbbb"})
+	source.Create(&models.Click{LinkID: 1})
+	source.Create(&models.Click{LinkID: 2})
+	data := backupOf(t, source)
+
+	// Le lien 2 existe déjà avec un autre code : il est conservé, et le clic du lien 2 sauvegardé est ignoré
+	// pour ne pas être rattaché à ce lien.
+	target := testutil.NewDB(t)
+	target.Create(&models.Link{ID: 2, LongURL: "https://example.com/autre", ShortCode: "cccccc"})
+	backupService := NewBackupService(repository.NewBackupRepository(target))
+
+	plans, err := backupService.PlanRestore(data, RestoreModeMerge)
+	if err != nil {
+		t.Fatalf("PlanRestore : %v", err)
+	}
+	if plan := planFor(t, plans, "links"); plan.ToInsert != 1 || plan.ToSkip != 1 || plan.ToDelete != 0 {
+		t.Errorf("plan des liens = %+v, attendu 1 insertion et 1 ligne ignorée", plan)
+	}
+	if plan := planFor(t, plans, "clicks"); plan.ToInsert != 1 || plan.ToSkip != 1 {
+		t.Errorf("plan des clics = %+v, attendu 1 insertion et 1 ligne ignorée", plan)
+	}
+
+	inserted, err := backupService.Restore(data, RestoreModeMerge)
+	if err != nil {
+		t.Fatalf("Restore : %v", err)
+	}
+	if inserted["links"] != 1 || inserted["clicks"] != 1 {
+		t.Errorf("lignes insérées = %v, attendu 1 lien et 1 clic", inserted)
+	}
+	var kept models.Link
+	target.First(&kept, 2)
+	if kept.ShortCode != "cccccc" {
+		t.Errorf("le lien existant a été remplacé : code %q", kept.ShortCode)
+	}
+	var clicks []models.Click
+	target.Find(&clicks)
+	if len(clicks) != 1 || clicks[0].LinkID != 1 {
+		t.Errorf("clics restaurés = %+v, attendu le seul clic du lien 1", clicks)
+	}
+}
+
+func TestRestoreReplaceLoadsBackup(t *testing.T) {
+	source := testutil.NewDB(t)
+	source.Create(&models.Link{ID: 1, LongURL: "https://example.com/a", ShortCode: "aaaaaa"})
+	source.Create(&models.Click{LinkID: 1})
+	data := backupOf(t, source)
+
+	target := testutil.NewDB(t)
+	target.Create(&models.Link{ID: 5, LongURL: "https://example.com/autre", ShortCode: "cccccc"})
+	target.Create(&models.Click{LinkID: 5})
+	target.Create(&models.Click{LinkID: 5})
+	backupService := NewBackupService(repository.NewBackupRepository(target))
+
+	plans, err := backupService.PlanRestore(data, RestoreModeReplace)
+	if err != nil {
+		t.Fatalf("PlanRestore : %v", err)
+	}
+	if plan := planFor(t, plans, "clicks"); plan.ToDelete != 2 || plan.ToInsert != 1 || plan.Missing {
+		t.Errorf("plan des clics = %+v, attendu 2 suppressions et 1 insertion", plan)
+	}
+
+	if _, err := backupService.Restore(data, RestoreModeReplace); err != nil {
+		t.Fatalf("Restore : %v", err)
+	}
+	var links []models.Link
+	target.Find(&links)
+	if len(links) != 1 || links[0].ShortCode != "aaaaaa" {
+		t.Errorf("liens après restauration = %+v, attendu le seul lien sauvegardé", links)
+	}
+	if n := countRows(t, target, &models.Click{}); n != 1 {
+		t.Errorf("%d clic(s) après restauration, attendu 1", n)
+	}
+}
+
+func TestRestoreReplaceKeepsTablesMissingFromBackup(t *testing.T) {
+	source := testutil.NewDB(t)
+	source.Create(&models.Link{ID: 1, LongURL: "https://example.com/a", ShortCode: "aaaaaa"})
+	data := backupOf(t, source)
+	// Une sauvegarde antérieure à la création de la table des clics ne la contient pas.
+	delete(data, "clicks")
+
+	target := testutil.NewDB(t)
+	target.Create(&models.Link{ID: 1, LongURL: "https://example.com/a", ShortCode: "aaaaaa"})
+	target.Create(&models.Click{LinkID: 1})
+	backupService := NewBackupService(repository.NewBackupRepository(target))
+
+	plans, err := backupService.PlanRestore(data, RestoreModeReplace)
+	if err != nil {
+		t.Fatalf("PlanRestore : %v", err)
+	}
+	if plan := planFor(t, plans, "clicks"); !plan.Missing || plan.ToDelete != 0 || plan.CurrentRows != 1 {
+		t.Errorf("plan des clics = %+v, attendu une table absente conservée", plan)
+	}
+	if plan := planFor(t, plans, "links"); plan.Missing || plan.ToDelete != 1 {
+		t.Errorf("plan des liens = %+v, attendu 1 suppression", plan)
+	}
+
+	if _, err := backupService.Restore(data, RestoreModeReplace); err != nil {
+		t.Fatalf("Restore : %v", err)
+	}
+	if n := countRows(t, target, &models.Click{}); n != 1 {
+		t.Errorf("%d clic(s) après restauration, attendu le clic existant conservé", n)
+	}
+}
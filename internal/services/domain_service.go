@@ -0,0 +1,144 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+	"github.com/axellelanca/urlshortener/internal/repository"
+	"github.com/axellelanca/urlshortener/internal/validation"
+)
+
+// Erreurs métier liées aux domaines.
+var (
+	ErrUnknownDomain = errors.New("unknown domain")
+	ErrInvalidDomain = errors.New("invalid domain")
+	ErrDomainExists  = errors.New("domain already exists")
+)
+
+// DomainService gère les domaines courts personnalisés et la construction des URLs courtes complètes.
+type DomainService struct {
+	domainRepo     repository.DomainRepository
+	defaultBaseURL string // URL de base du domaine par défaut (server.base_url)
+}
+
+// NewDomainService crée et retourne une nouvelle instance de DomainService.
+func NewDomainService(domainRepo repository.DomainRepository, defaultBaseURL string) *DomainService {
+	return &DomainService{
+		domainRepo:     domainRepo,
+		defaultBaseURL: strings.TrimRight(defaultBaseURL, "/"),
+	}
+}
+
+// ForWorkspace retourne un DomainService restreint aux domaines d'un espace de travail : les domaines des autres
+// espaces y sont inconnus, et les domaines ajoutés sont rattachés à cet espace.
+func (s *DomainService) ForWorkspace(workspaceID uint) *DomainService {
+	return &DomainService{
+		domainRepo:     s.domainRepo.ForWorkspace(workspaceID),
+		defaultBaseURL: s.defaultBaseURL,
+	}
+}
+
+// normalizeHost met un hôte (éventuellement suivi d'un port) sous la forme stockée en base :
+// minuscules, punycode, sans point final ni port par défaut.
+func normalizeHost(host string) (string, error) {
+	normalized, err := validation.Normalize("http://" + host)
+	if err != nil {
+		return "", err
+	}
+	parsed, _ := url.Parse(normalized)
+	return parsed.Host, nil
+}
+
+// AddDomain enregistre un nouveau domaine à partir de son URL de base (ex: "https://sho.rt").
+func (s *DomainService) AddDomain(baseURL string) (*models.Domain, error) {
+	normalized, err := validation.Normalize(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidDomain, err)
+	}
+	parsed, _ := url.Parse(normalized)
+	if parsed.Path != "/" || parsed.RawQuery != "" {
+		return nil, fmt.Errorf("%w: %q must not contain a path or a query", ErrInvalidDomain, baseURL)
+	}
+
+	taken, err := s.domainRepo.HostTaken(parsed.Host)
+	if err != nil {
+		return nil, fmt.Errorf("database error checking domain: %w", err)
+	}
+	if taken {
+		return nil, fmt.Errorf("%w: %q", ErrDomainExists, parsed.Host)
+	}
+
+	domain := &models.Domain{
+		Host:    parsed.Host,
+		BaseURL: parsed.Scheme + "://" + parsed.Host,
+	}
+	if err := s.domainRepo.CreateDomain(domain); err != nil {
+		return nil, fmt.Errorf("failed to save domain: %w", err)
+	}
+	return domain, nil
+}
+
+// ListDomains retourne tous les domaines personnalisés.
+func (s *DomainService) ListDomains() ([]models.Domain, error) {
+	return s.domainRepo.GetAllDomains()
+}
+
+// DomainIDForName retourne l'identifiant du domaine demandé explicitement (ex: champ "domain" de l'API).
+// Un nom vide désigne le domaine par défaut ; un domaine inconnu renvoie ErrUnknownDomain.
+func (s *DomainService) DomainIDForName(name string) (uint, error) {
+	if name == "" {
+		return models.DefaultDomainID, nil
+	}
+	host, err := normalizeHost(name)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %q", ErrUnknownDomain, name)
+	}
+	domain, err := s.domainRepo.GetDomainByHost(host)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, fmt.Errorf("%w: %q", ErrUnknownDomain, name)
+		}
+		return 0, fmt.Errorf("database error looking up domain: %w", err)
+	}
+	return domain.ID, nil
+}
+
+// DomainIDForHost retourne l'identifiant du domaine servant une requête, d'après son en-tête Host.
+// Un hôte qui n'est pas un domaine personnalisé relève du domaine par défaut.
+func (s *DomainService) DomainIDForHost(host string) (uint, error) {
+	id, err := s.DomainIDForName(host)
+	if errors.Is(err, ErrUnknownDomain) {
+		return models.DefaultDomainID, nil
+	}
+	return id, err
+}
+
+// ShortURL retourne l'URL courte complète d'un lien, construite à partir de l'URL de base de son domaine.
+func (s *DomainService) ShortURL(link *models.Link) (string, error) {
+	if link.DomainID == models.DefaultDomainID {
+		return s.defaultBaseURL + "/" + link.ShortCode, nil
+	}
+	domain, err := s.domainRepo.GetDomainByID(link.DomainID)
+	if err != nil {
+		return "", fmt.Errorf("failed to load domain %d: %w", link.DomainID, err)
+	}
+	return domain.BaseURL + "/" + link.ShortCode, nil
+}
+
+// RegisterOwnHosts déclare les domaines personnalisés auprès du validateur d'URLs,
+// pour refuser les liens qui pointeraient vers l'un d'eux (boucles de redirection).
+func (s *DomainService) RegisterOwnHosts(validator *validation.URLValidator) error {
+	domains, err := s.domainRepo.GetAllDomains()
+	if err != nil {
+		return fmt.Errorf("failed to load domains: %w", err)
+	}
+	for _, domain := range domains {
+		validator.AddOwnHost(domain.Host)
+	}
+	return nil
+}
@@ -0,0 +1,235 @@
+package services
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+	"github.com/axellelanca/urlshortener/internal/repository"
+)
+
+// Formats d'export supportés. "ndjson" est un alias de "jsonl".
+const (
+	ExportFormatCSV    = "csv"
+	ExportFormatJSONL  = "jsonl"
+	ExportFormatNDJSON = "ndjson"
+)
+
+// ExportOptions décrit le contenu et le format d'un export.
+type ExportOptions struct {
+	Format     string
+	WithClicks bool
+	Filter     repository.LinkFilter
+}
+
+// linkExport est la représentation d'un lien dans un export JSON lines. Ses clics, s'ils sont demandés,
+// sont ajoutés au fil de l'eau dans un tableau "clicks" (voir exportJSONL).
+type linkExport struct {
+	ID             uint      `json:"id"`
+	ShortCode      string    `json:"short_code"`
+	LongURL        string    `json:"long_url"`
+	Owner          string    `json:"owner"`
+	CreatedAt      time.Time `json:"created_at"`
+	ImportedClicks int       `json:"imported_clicks"`
+}
+
+// clickExport est la représentation d'un clic dans un export JSON lines.
+type clickExport struct {
+	ID        uint      `json:"id"`
+	Timestamp time.Time `json:"timestamp"`
+	UserAgent string    `json:"user_agent"`
+	IPAddress string    `json:"ip_address"`
+	Source    string    `json:"source"`
+	Rule      string    `json:"rule"`
+	Country   string    `json:"country"`
+	Region    string    `json:"region"`
+	Variant   string    `json:"variant"`
+}
+
+// csvLinkHeader et csvClickHeader sont les en-têtes des exports CSV.
+var (
+	csvLinkHeader  = []string{"id", "short_code", "long_url", "owner", "created_at", "imported_clicks"}
+	csvClickHeader = []string{"click_id", "click_timestamp", "click_user_agent", "click_ip_address",
+		"click_source", "click_rule", "click_country", "click_region", "click_variant"}
+)
+
+// ExportService produit des exports en flux des liens et de leurs clics.
+type ExportService struct {
+	linkRepo  repository.LinkRepository
+	clickRepo repository.ClickRepository
+}
+
+// NewExportService crée et retourne une nouvelle instance de ExportService.
+func NewExportService(linkRepo repository.LinkRepository, clickRepo repository.ClickRepository) *ExportService {
+	return &ExportService{
+		linkRepo:  linkRepo,
+		clickRepo: clickRepo,
+	}
+}
+
+// ForWorkspace retourne un ExportService restreint aux liens d'un espace de travail et à leurs clics.
+func (s *ExportService) ForWorkspace(workspaceID uint) *ExportService {
+	return &ExportService{
+		linkRepo:  s.linkRepo.ForWorkspace(workspaceID),
+		clickRepo: s.clickRepo.ForWorkspace(workspaceID),
+	}
+}
+
+// IsValidExportFormat indique si le format d'export demandé est supporté.
+func IsValidExportFormat(format string) bool {
+	return format == ExportFormatCSV || format == ExportFormatJSONL || format == ExportFormatNDJSON
+}
+
+// ExportContentType retourne le type MIME correspondant à un format d'export.
+func ExportContentType(format string) string {
+	if format == ExportFormatCSV {
+		return "text/csv; charset=utf-8"
+	}
+	return "application/x-ndjson"
+}
+
+// ParseExportDate interprète une borne de date d'export (ex: "2024-01-31" ou RFC 3339).
+func ParseExportDate(value string) (time.Time, error) {
+	return parseImportTime(value)
+}
+
+// Export écrit les liens correspondant aux options dans w, au fil de l'eau.
+// Les liens sont lus par lots : la mémoire utilisée ne dépend pas de la taille de la base.
+func (s *ExportService) Export(w io.Writer, opts ExportOptions) error {
+	switch opts.Format {
+	case ExportFormatCSV:
+		return s.exportCSV(w, opts)
+	case ExportFormatJSONL, ExportFormatNDJSON:
+		return s.exportJSONL(w, opts)
+	default:
+		return fmt.Errorf("unsupported export format %q", opts.Format)
+	}
+}
+
+// exportCSV écrit une ligne par lien, ou une ligne par clic si les clics sont demandés.
+// Un lien sans clic produit alors une ligne avec des colonnes de clic vides.
+func (s *ExportService) exportCSV(w io.Writer, opts ExportOptions) error {
+	writer := csv.NewWriter(w)
+
+	header := csvLinkHeader
+	if opts.WithClicks {
+		header = append(append([]string{}, csvLinkHeader...), csvClickHeader...)
+	}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	err := s.linkRepo.StreamLinks(opts.Filter, func(link *models.Link) error {
+		linkColumns := []string{
+			strconv.FormatUint(uint64(link.ID), 10),
+			link.ShortCode,
+			link.LongURL,
+			link.Owner,
+			link.CreatedAt.Format(time.RFC3339),
+			strconv.Itoa(link.ImportedClicks),
+		}
+		if !opts.WithClicks {
+			return writer.Write(linkColumns)
+		}
+
+		written := false
+		err := s.clickRepo.StreamClicksByLinkID(link.ID, func(click *models.Click) error {
+			written = true
+			return writer.Write(append(linkColumns[:len(linkColumns):len(linkColumns)],
+				strconv.FormatUint(uint64(click.ID), 10),
+				click.Timestamp.Format(time.RFC3339),
+				click.UserAgent,
+				click.IPAddress,
+				click.Source,
+				click.Rule,
+				click.Country,
+				click.Region,
+				click.Variant,
+			))
+		})
+		if err != nil {
+			return err
+		}
+		if !written {
+			return writer.Write(append(linkColumns, make([]string, len(csvClickHeader))...))
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to export links: %w", err)
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// exportJSONL écrit un objet JSON par lien, accompagné de ses clics si demandés. Les clics sont écrits
+// un à un dans le tableau "clicks" de l'objet, sans être chargés en mémoire : un lien très visité ne pèse pas
+// plus qu'un autre. Le tableau est omis pour un lien sans clic.
+func (s *ExportService) exportJSONL(w io.Writer, opts ExportOptions) error {
+	buffered := bufio.NewWriter(w)
+
+	err := s.linkRepo.StreamLinks(opts.Filter, func(link *models.Link) error {
+		entry, err := json.Marshal(linkExport{
+			ID:             link.ID,
+			ShortCode:      link.ShortCode,
+			LongURL:        link.LongURL,
+			Owner:          link.Owner,
+			CreatedAt:      link.CreatedAt,
+			ImportedClicks: link.ImportedClicks,
+		})
+		if err != nil {
+			return err
+		}
+
+		opened := false
+		if opts.WithClicks {
+			err := s.clickRepo.StreamClicksByLinkID(link.ID, func(click *models.Click) error {
+				if opened {
+					buffered.WriteByte(',')
+				} else {
+					// L'objet du lien est rouvert pour y ajouter le tableau des clics.
+					buffered.Write(entry[:len(entry)-1])
+					buffered.WriteString(`,"clicks":[`)
+					opened = true
+				}
+				data, err := json.Marshal(clickExport{
+					ID:        click.ID,
+					Timestamp: click.Timestamp,
+					UserAgent: click.UserAgent,
+					IPAddress: click.IPAddress,
+					Source:    click.Source,
+					Rule:      click.Rule,
+					Country:   click.Country,
+					Region:    click.Region,
+					Variant:   click.Variant,
+				})
+				if err != nil {
+					return err
+				}
+				_, err = buffered.Write(data)
+				return err
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		if opened {
+			_, err = buffered.WriteString("]}\n")
+		} else {
+			buffered.Write(entry)
+			err = buffered.WriteByte('\n')
+		}
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to export links: %w", err)
+	}
+	return buffered.Flush()
+}
@@ -0,0 +1,117 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+	"github.com/axellelanca/urlshortener/internal/repository"
+	"github.com/axellelanca/urlshortener/internal/testutil"
+)
+
+// newTestExportService crée un service d'export sur une base contenant trois liens :
+// "jan" et "feb" appartiennent à alice (deux clics pour "jan"), "bob" à bob.
+func newTestExportService(t *testing.T) *ExportService {
+	t.Helper()
+	db := testutil.NewDB(t)
+	links := []*models.Link{
+		{LongURL: "https://example.com/jan", ShortCode: "jan", Owner: "alice", CreatedAt: time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)},
+		{LongURL: "https://example.com/feb", ShortCode: "feb", Owner: "alice", CreatedAt: time.Date(2024, 2, 15, 0, 0, 0, 0, time.UTC)},
+		{LongURL: "https://example.com/bob", ShortCode: "bob", Owner: "bob", CreatedAt: time.Date(2024, 1, 20, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, link := range links {
+		if err := db.Create(link).Error; err != nil {
+			t.Fatalf("création du lien %s : %v", link.ShortCode, err)
+		}
+	}
+	db.Create(&models.Click{LinkID: links[0].ID, UserAgent: "ua-1", Timestamp: time.Now()})
+	db.Create(&models.Click{LinkID: links[0].ID, UserAgent: "ua-2", Timestamp: time.Now()})
+	return NewExportService(repository.NewLinkRepository(db), repository.NewClickRepository(db))
+}
+
+func TestExportJSONLFiltersLinksAndStreamsClicks(t *testing.T) {
+	exportService := newTestExportService(t)
+
+	var out bytes.Buffer
+	err := exportService.Export(&out, ExportOptions{
+		Format:     ExportFormatJSONL,
+		WithClicks: true,
+		Filter: repository.LinkFilter{
+			Owner:       "alice",
+			CreatedFrom: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			CreatedTo:   time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+		},
+	})
+	if err != nil {
+		t.Fatalf("Export : %v", err)
+	}
+
+	type exportedLink struct {
+		ShortCode string `json:"short_code"`
+		Owner     string `json:"owner"`
+		Clicks    []struct {
+			UserAgent string `json:"user_agent"`
+		} `json:"clicks"`
+	}
+	var entries []exportedLink
+	scanner := bufio.NewScanner(&out)
+	for scanner.Scan() {
+		var entry exportedLink
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatalf("ligne JSON invalide %q : %v", scanner.Text(), err)
+		}
+		entries = append(entries, entry)
+	}
+	if len(entries) != 1 || entries[0].ShortCode != "jan" || entries[0].Owner != "alice" {
+		t.Fatalf("liens exportés = %+v, attendu le seul lien jan", entries)
+	}
+	if clicks := entries[0].Clicks; len(clicks) != 2 || clicks[0].UserAgent != "ua-1" || clicks[1].UserAgent != "ua-2" {
+		t.Errorf("clics exportés = %+v, attendu les deux clics dans l'ordre", clicks)
+	}
+}
+
+func TestExportCSVWritesOneRowPerClick(t *testing.T) {
+	exportService := newTestExportService(t)
+
+	var out bytes.Buffer
+	err := exportService.Export(&out, ExportOptions{
+		Format:     ExportFormatCSV,
+		WithClicks: true,
+		Filter:     repository.LinkFilter{Owner: "alice"},
+	})
+	if err != nil {
+		t.Fatalf("Export : %v", err)
+	}
+
+	rows, err := csv.NewReader(&out).ReadAll()
+	if err != nil {
+		t.Fatalf("CSV invalide : %v", err)
+	}
+	column := make(map[string]int, len(rows[0]))
+	for i, name := range rows[0] {
+		column[name] = i
+	}
+	var got []string
+	for _, row := range rows[1:] {
+		got = append(got, row[column["short_code"]]+":"+row[column["click_user_agent"]])
+	}
+	want := []string{"jan:ua-1", "jan:ua-2", "feb:"}
+	if len(got) != len(want) {
+		t.Fatalf("lignes exportées = %v, attendu %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("ligne %d = %q, attendu %q", i+1, got[i], want[i])
+		}
+	}
+}
+
+func TestExportRejectsUnknownFormat(t *testing.T) {
+	if err := newTestExportService(t).Export(&bytes.Buffer{}, ExportOptions{Format: "xml"}); err == nil {
+		t.Error("un format inconnu doit être refusé")
+	}
+}
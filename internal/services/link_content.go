@@ -0,0 +1,40 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+	"github.com/axellelanca/urlshortener/internal/snapshot"
+)
+
+// ErrInvalidSelector signale un sélecteur CSS de contenu surveillé mal formé ou hors du sous-ensemble supporté.
+var ErrInvalidSelector = snapshot.ErrInvalidSelector
+
+// Taille des pages de l'historique des changements de contenu.
+const (
+	DefaultContentHistoryLimit = 20
+	MaxContentHistoryLimit     = 100
+)
+
+// normalizeWatch valide le sélecteur du contenu surveillé d'un lien. Un sélecteur active la surveillance à lui seul.
+func normalizeWatch(watch bool, selector string) (bool, string, error) {
+	selector = strings.TrimSpace(selector)
+	if _, err := snapshot.ParseSelector(selector); err != nil {
+		return false, "", err
+	}
+	return watch || selector != "", selector, nil
+}
+
+// ContentHistory retourne les limit dernières empreintes du contenu de la destination d'un lien, de la plus récente
+// à la plus ancienne : la plus ancienne sert de référence, chacune des suivantes correspond à un changement.
+func (s *LinkService) ContentHistory(link *models.Link, limit int) ([]models.ContentSnapshot, error) {
+	if limit <= 0 {
+		limit = DefaultContentHistoryLimit
+	}
+	snapshots, err := s.linkRepo.ListContentSnapshots(link.ID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list content snapshots: %w", err)
+	}
+	return snapshots, nil
+}
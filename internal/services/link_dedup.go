@@ -0,0 +1,201 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+	"github.com/axellelanca/urlshortener/internal/validation"
+)
+
+// ErrIdempotencyKeyReused est retournée lorsqu'une clé d'idempotence est rejouée avec une requête différente.
+var ErrIdempotencyKeyReused = errors.New("idempotency key already used for a different request")
+
+// normalizeURL retourne la forme canonique d'une URL, ou l'URL telle quelle si elle est illisible.
+func normalizeURL(rawURL string) string {
+	if normalized, err := validation.Normalize(rawURL); err == nil {
+		return normalized
+	}
+	return rawURL
+}
+
+// HashURL retourne l'empreinte SHA-256 (hexadécimale) de la forme normalisée d'une URL.
+func HashURL(rawURL string) string {
+	sum := sha256.Sum256([]byte(normalizeURL(rawURL)))
+	return hex.EncodeToString(sum[:])
+}
+
+// redirectFingerprint sérialise les champs enregistrés d'un lien qui déterminent sa redirection ou le décrivent
+// (hors destination, code, propriétaire et domaine) : c'est sur elle que reposent la déduplication et l'empreinte
+// d'une demande de création.
+// Seule la présence d'un mot de passe y figure (jamais son empreinte), et chaque réglage seulement s'il diffère
+// de sa valeur par défaut : un lien ordinaire donne une chaîne vide. Les étiquettes du lien doivent être chargées.
+func redirectFingerprint(link *models.Link) string {
+	var fingerprint strings.Builder
+	if link.PasswordHash != "" {
+		fingerprint.WriteString("\nprotected")
+	}
+	if link.AlwaysPreview {
+		fingerprint.WriteString("\npreview")
+	}
+	if link.RedirectRules != "" {
+		fingerprint.WriteString("\nrules:" + link.RedirectRules)
+	}
+	if link.SplitVariants != "" {
+		fingerprint.WriteString("\nvariants:" + link.SplitVariants)
+	}
+	if utm := UTMTemplateOf(link); link.QueryForwarding != QueryForwardingNone || !utm.IsZero() {
+		fmt.Fprintf(&fingerprint, "\ncampaign:%q:%q:%q:%q", link.QueryForwarding, utm.Source, utm.Medium, utm.Campaign)
+	}
+	if status := RedirectStatusOf(link); status != DefaultRedirectStatus || link.CacheControl != "" {
+		fmt.Fprintf(&fingerprint, "\nresponse:%d:%q", status, link.CacheControl)
+	}
+	if link.Kind == models.LinkKindPrefix {
+		fingerprint.WriteString("\n" + link.Kind)
+	}
+	if ViewLimited(link) {
+		fmt.Fprintf(&fingerprint, "\nviews:%d:%t", link.MaxViews, link.SelfDestruct)
+	}
+	if link.ActiveFrom != nil || link.ActiveUntil != nil {
+		fmt.Fprintf(&fingerprint, "\nwindow:%s:%s:%q", scheduleBound(link.ActiveFrom), scheduleBound(link.ActiveUntil), link.InactiveURL)
+	}
+	if link.WatchContent {
+		fmt.Fprintf(&fingerprint, "\nwatch:%q", link.WatchSelector)
+	}
+	if link.Metadata != "" {
+		fingerprint.WriteString("\nmetadata:" + link.Metadata)
+	}
+	if tags := TagNames(link); link.Title != "" || link.Description != "" || len(tags) > 0 {
+		sort.Strings(tags)
+		fmt.Fprintf(&fingerprint, "\ndescribed:%q:%q:%q", link.Title, link.Description, tags)
+	}
+	return fingerprint.String()
+}
+
+// shouldDeduplicate indique si la déduplication s'applique à une demande de création, dont requested est le lien.
+// Un alias explicite désigne un code précis, un mot de passe n'est vérifiable que sur son propre lien et un lien
+// à usage limité ne doit pas voir ses redirections consommées par d'autres : la demande n'est alors jamais dédupliquée.
+func (s *LinkService) shouldDeduplicate(input CreateLinkInput, requested *models.Link) bool {
+	if input.Alias != "" || requested.PasswordHash != "" || ViewLimited(requested) {
+		return false
+	}
+	if input.Deduplicate != nil {
+		return *input.Deduplicate
+	}
+	return s.opts.Deduplicate
+}
+
+// maxDuplicateCandidates est le nombre de liens vers la même URL examinés pour trouver un doublon : au-delà,
+// une nouvelle demande crée simplement un lien de plus.
+const maxDuplicateCandidates = 50
+
+// findDuplicate retourne le plus ancien lien existant du même domaine et du même propriétaire vers la même URL
+// normalisée dont les réglages de redirection, les métadonnées et la description sont ceux du lien demandé
+// (requested, tel que construit par buildLink), ou nil.
+func (s *LinkService) findDuplicate(input CreateLinkInput, requested *models.Link) (*models.Link, error) {
+	if !s.shouldDeduplicate(input, requested) {
+		return nil, nil
+	}
+	candidates, err := s.linkRepo.FindLinksByURLHash(input.DomainID, input.Owner, requested.URLHash, maxDuplicateCandidates)
+	if err != nil {
+		return nil, fmt.Errorf("database error looking for duplicate link: %w", err)
+	}
+	links := make([]*models.Link, len(candidates))
+	for i := range candidates {
+		links[i] = &candidates[i]
+	}
+	if err := s.LoadTags(links...); err != nil {
+		return nil, err
+	}
+	fingerprint := redirectFingerprint(requested)
+	for _, link := range links {
+		if redirectFingerprint(link) == fingerprint {
+			return link, nil
+		}
+	}
+	return nil, nil
+}
+
+// requestHash calcule l'empreinte d'une demande de création, dont requested est le lien, associée à sa clé
+// d'idempotence.
+// Le domaine n'y figure que s'il n'est pas le domaine par défaut, et l'empreinte d'un lien ordinaire se réduit
+// à son URL, son alias et son propriétaire, ce qui préserve les empreintes existantes.
+func requestHash(input CreateLinkInput, requested *models.Link) string {
+	request := normalizeURL(input.LongURL) + "\n" + input.Alias + "\n" + input.Owner
+	if input.DomainID != models.DefaultDomainID {
+		request += fmt.Sprintf("\n%d", input.DomainID)
+	}
+	request += redirectFingerprint(requested)
+	sum := sha256.Sum256([]byte(request))
+	return hex.EncodeToString(sum[:])
+}
+
+// scheduleBound formate une borne de fenêtre d'activité pour l'empreinte d'un lien (vide si absente).
+func scheduleBound(bound *time.Time) string {
+	if bound == nil {
+		return ""
+	}
+	return bound.UTC().Format(time.RFC3339Nano)
+}
+
+// linkForIdempotencyKey retourne le lien déjà créé pour une clé d'idempotence encore valide, ou nil.
+// hash est l'empreinte de la demande (requestHash).
+func (s *LinkService) linkForIdempotencyKey(input CreateLinkInput, hash string) (*models.Link, error) {
+	key, err := s.linkRepo.GetIdempotencyKey(input.IdempotencyKey, time.Now().Add(-s.opts.IdempotencyTTL))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("database error looking up idempotency key: %w", err)
+	}
+	if key.RequestHash != hash {
+		return nil, ErrIdempotencyKeyReused
+	}
+	link, err := s.linkRepo.GetLinkByID(key.LinkID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load link for idempotency key: %w", err)
+	}
+	return link, nil
+}
+
+// createIdempotent crée un lien en enregistrant sa clé d'idempotence dans la même transaction.
+// Si la clé a déjà servi (y compris par une requête concurrente), le lien d'origine est retourné.
+func (s *LinkService) createIdempotent(input CreateLinkInput) (*models.Link, bool, error) {
+	link, err := s.buildLink(input)
+	if err != nil {
+		return nil, false, err
+	}
+	hash := requestHash(input, link)
+	if existing, err := s.linkForIdempotencyKey(input, hash); existing != nil || err != nil {
+		return existing, false, err
+	}
+	if existing, err := s.findDuplicate(input, link); existing != nil || err != nil {
+		return existing, false, err
+	}
+	if err := s.assignShortCode(link, input.Alias, nil); err != nil {
+		return nil, false, err
+	}
+
+	now := time.Now()
+	key := &models.IdempotencyKey{
+		Key:         input.IdempotencyKey,
+		RequestHash: hash,
+		CreatedAt:   now,
+	}
+	if err := s.linkRepo.CreateLinkWithIdempotencyKey(link, key, now.Add(-s.opts.IdempotencyTTL)); err != nil {
+		// Une requête concurrente a pu enregistrer la même clé entre-temps : on renvoie alors son lien.
+		if existing, lookupErr := s.linkForIdempotencyKey(input, hash); existing != nil || lookupErr != nil {
+			return existing, false, lookupErr
+		}
+		return nil, false, s.linkQuotaError(err, "failed to save link")
+	}
+	s.queuePageInfo(link)
+	return link, true, nil
+}
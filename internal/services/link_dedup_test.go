@@ -0,0 +1,103 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/repository"
+	"github.com/axellelanca/urlshortener/internal/testutil"
+)
+
+func TestDeduplicationKeepsCustomLinksApart(t *testing.T) {
+	linkService := NewLinkService(repository.NewLinkRepository(testutil.NewDB(t)), LinkServiceOptions{Deduplicate: true})
+	create := func(input CreateLinkInput) string {
+		t.Helper()
+		input.LongURL = "https://example.com/soldes"
+		link, _, err := linkService.CreateLink(input)
+		if err != nil {
+			t.Fatalf("CreateLink(%+v) : %v", input, err)
+		}
+		return link.ShortCode
+	}
+
+	plain := create(CreateLinkInput{})
+	if again := create(CreateLinkInput{}); again != plain {
+		t.Fatalf("une URL identique doit être dédupliquée : %q puis %q", plain, again)
+	}
+
+	distinct := map[string]CreateLinkInput{
+		"page d'aperçu imposée": {AlwaysPreview: true},
+		"contenu surveillé":     {WatchContent: true},
+		"sélecteur surveillé":   {WatchSelector: "main"},
+		"titre":                 {Title: "Soldes d'hiver"},
+		"description":           {Description: "Campagne newsletter"},
+		"étiquettes":            {Tags: []string{"promo"}},
+		"métadonnées":           {Metadata: map[string]string{"source": "newsletter"}},
+	}
+	codes := make(map[string]string)
+	for name, input := range distinct {
+		code := create(input)
+		if code == plain {
+			t.Errorf("%s : la demande ne doit pas être dédupliquée sur le lien ordinaire", name)
+		}
+		codes[name] = code
+	}
+
+	// Un lien décrit de la même façon est retrouvé, même s'il n'est pas le plus ancien lien vers cette URL.
+	if code := create(CreateLinkInput{Tags: []string{" PROMO ", "promo"}}); code != codes["étiquettes"] {
+		t.Errorf("étiquettes identiques une fois normalisées : %q, attendu %q", code, codes["étiquettes"])
+	}
+	if code := create(CreateLinkInput{Title: "  Soldes d'hiver "}); code != codes["titre"] {
+		t.Errorf("titre identique : %q, attendu %q", code, codes["titre"])
+	}
+	if code := create(CreateLinkInput{Metadata: map[string]string{"source": "newsletter"}}); code != codes["métadonnées"] {
+		t.Errorf("métadonnées identiques : %q, attendu %q", code, codes["métadonnées"])
+	}
+
+	// Dans un lot, seuls les éléments identiques sont dédupliqués entre eux.
+	results, err := linkService.CreateLinks([]CreateLinkInput{
+		{LongURL: "https://example.com/lot", Metadata: map[string]string{"source": "a"}},
+		{LongURL: "https://example.com/lot", Metadata: map[string]string{"source": "b"}},
+		{LongURL: "https://example.com/lot", Metadata: map[string]string{"source": "a"}},
+	})
+	if err != nil {
+		t.Fatalf("CreateLinks : %v", err)
+	}
+	if results[1].Existing || !results[2].Existing || results[2].Link != results[0].Link {
+		t.Errorf("lot : existants %t, %t, attendu le premier lien pour le troisième élément seulement",
+			results[1].Existing, results[2].Existing)
+	}
+}
+
+func TestIdempotencyKeyCoversLinkDescription(t *testing.T) {
+	linkService := NewLinkService(repository.NewLinkRepository(testutil.NewDB(t)), LinkServiceOptions{IdempotencyTTL: time.Hour})
+	input := CreateLinkInput{LongURL: "https://example.com/", IdempotencyKey: "cle-1", Title: "A", Tags: []string{"promo"}}
+
+	first, created, err := linkService.CreateLink(input)
+	if err != nil || !created {
+		t.Fatalf("CreateLink : %v (créé : %t)", err, created)
+	}
+
+	replay := input
+	replay.Tags = []string{"PROMO"}
+	if link, created, err := linkService.CreateLink(replay); err != nil || created || link.ID != first.ID {
+		t.Fatalf("rejouer la même demande doit renvoyer le lien d'origine : %v", err)
+	}
+
+	for name, changed := range map[string]CreateLinkInput{
+		"titre":         {Title: "B", Tags: input.Tags},
+		"description":   {Title: "A", Description: "notes", Tags: input.Tags},
+		"étiquettes":    {Title: "A", Tags: []string{"newsletter"}},
+		"aperçu imposé": {Title: "A", Tags: input.Tags, AlwaysPreview: true},
+		"surveillance":  {Title: "A", Tags: input.Tags, WatchContent: true},
+		"sélecteur":     {Title: "A", Tags: input.Tags, WatchSelector: "main"},
+		"métadonnées":   {Title: "A", Tags: input.Tags, Metadata: map[string]string{"source": "newsletter"}},
+	} {
+		changed.LongURL = input.LongURL
+		changed.IdempotencyKey = input.IdempotencyKey
+		if _, _, err := linkService.CreateLink(changed); !errors.Is(err, ErrIdempotencyKeyReused) {
+			t.Errorf("%s modifié : erreur %v, attendu ErrIdempotencyKeyReused", name, err)
+		}
+	}
+}
@@ -0,0 +1,333 @@
+package services
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+)
+
+// Formats d'import supportés.
+const (
+	ImportFormatCSV    = "csv"    // CSV générique : short_code, long_url, created_at, clicks, owner
+	ImportFormatJSONL  = "jsonl"  // Un objet JSON par ligne avec les mêmes champs que le CSV générique
+	ImportFormatBitly  = "bitly"  // Export CSV de Bitly
+	ImportFormatYOURLS = "yourls" // Export CSV de la table yourls_url de YOURLS
+)
+
+// shortCodePattern définit les codes courts acceptés lors d'un import (alphanumériques, '-' et '_').
+var shortCodePattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,10}$`)
+
+// importTimeLayouts liste les formats de date reconnus dans les fichiers importés.
+var importTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05 -0700 MST",
+	"2006-01-02 15:04:05 -0700",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+	"2006-01-02",
+}
+
+// importColumns associe chaque champ importé aux en-têtes CSV possibles, selon le format.
+var importColumns = map[string]map[string][]string{
+	ImportFormatCSV: {
+		"short_code": {"short_code"},
+		"long_url":   {"long_url"},
+		"created_at": {"created_at"},
+		"clicks":     {"clicks"},
+		"owner":      {"owner"},
+	},
+	ImportFormatBitly: {
+		"short_code": {"bitlink", "short url", "short_url", "link"},
+		"long_url":   {"long url", "long_url", "original url"},
+		"created_at": {"created", "created_at", "date created"},
+		"clicks":     {"clicks", "total clicks", "engagements"},
+	},
+	ImportFormatYOURLS: {
+		"short_code": {"keyword"},
+		"long_url":   {"url"},
+		"created_at": {"timestamp"},
+		"clicks":     {"clicks"},
+	},
+}
+
+// ImportRecord est un lien lu depuis un fichier d'import, avant validation.
+type ImportRecord struct {
+	Line      int // Numéro de ligne dans le fichier source, pour les rapports
+	ShortCode string
+	LongURL   string
+	CreatedAt time.Time
+	Clicks    int
+	Owner     string
+}
+
+// ImportIssue décrit une ligne qui n'a pas été importée.
+type ImportIssue struct {
+	Line      int
+	ShortCode string
+	Reason    string
+}
+
+// ImportReport résume le résultat d'un import.
+type ImportReport struct {
+	Imported  int
+	Conflicts []ImportIssue // Codes courts déjà utilisés (en base ou plus haut dans le fichier)
+	Invalid   []ImportIssue // Lignes illisibles ou URLs invalides
+}
+
+// ParseImportFile lit un fichier d'import dans le format indiqué.
+// Les lignes illisibles ne bloquent pas la lecture : elles sont retournées comme problèmes.
+func ParseImportFile(r io.Reader, format string) ([]ImportRecord, []ImportIssue, error) {
+	switch format {
+	case ImportFormatJSONL:
+		return parseJSONLImport(r)
+	case ImportFormatCSV, ImportFormatBitly, ImportFormatYOURLS:
+		return parseCSVImport(r, importColumns[format], format == ImportFormatBitly)
+	default:
+		return nil, nil, fmt.Errorf("unsupported import format %q", format)
+	}
+}
+
+// parseCSVImport lit un CSV avec en-tête en utilisant la correspondance de colonnes fournie.
+// Si shortCodeIsURL est vrai, la colonne du code court contient une URL courte complète (ex: bit.ly/abc).
+func parseCSVImport(r io.Reader, columns map[string][]string, shortCodeIsURL bool) ([]ImportRecord, []ImportIssue, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read csv header: %w", err)
+	}
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		index[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	position := make(map[string]int, len(columns))
+	for field, aliases := range columns {
+		position[field] = -1
+		for _, alias := range aliases {
+			if i, ok := index[alias]; ok {
+				position[field] = i
+				break
+			}
+		}
+	}
+	if position["long_url"] < 0 {
+		return nil, nil, errors.New("csv header has no long url column")
+	}
+
+	var records []ImportRecord
+	var issues []ImportIssue
+	line := 1
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		line++
+		if err != nil {
+			issues = append(issues, ImportIssue{Line: line, Reason: err.Error()})
+			continue
+		}
+		field := func(name string) string {
+			if i, ok := position[name]; ok && i >= 0 && i < len(row) {
+				return strings.TrimSpace(row[i])
+			}
+			return ""
+		}
+
+		record := ImportRecord{Line: line, ShortCode: field("short_code"), LongURL: field("long_url"), Owner: field("owner")}
+		if shortCodeIsURL && record.ShortCode != "" {
+			record.ShortCode = shortCodeFromURL(record.ShortCode)
+		}
+		if err := fillImportRecord(&record, field("created_at"), field("clicks")); err != nil {
+			issues = append(issues, ImportIssue{Line: line, ShortCode: record.ShortCode, Reason: err.Error()})
+			continue
+		}
+		records = append(records, record)
+	}
+	return records, issues, nil
+}
+
+// parseJSONLImport lit un fichier contenant un objet JSON par ligne.
+func parseJSONLImport(r io.Reader) ([]ImportRecord, []ImportIssue, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var records []ImportRecord
+	var issues []ImportIssue
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		var entry struct {
+			ShortCode string          `json:"short_code"`
+			LongURL   string          `json:"long_url"`
+			CreatedAt string          `json:"created_at"`
+			Clicks    json.RawMessage `json:"clicks"`
+			Owner     string          `json:"owner"`
+		}
+		if err := json.Unmarshal([]byte(text), &entry); err != nil {
+			issues = append(issues, ImportIssue{Line: line, Reason: err.Error()})
+			continue
+		}
+
+		record := ImportRecord{Line: line, ShortCode: entry.ShortCode, LongURL: entry.LongURL, Owner: entry.Owner}
+		if err := fillImportRecord(&record, entry.CreatedAt, strings.Trim(string(entry.Clicks), `"`)); err != nil {
+			issues = append(issues, ImportIssue{Line: line, ShortCode: record.ShortCode, Reason: err.Error()})
+			continue
+		}
+		records = append(records, record)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, fmt.Errorf("failed to read jsonl file: %w", err)
+	}
+	return records, issues, nil
+}
+
+// fillImportRecord interprète la date de création et le nombre de clics d'une ligne importée.
+func fillImportRecord(record *ImportRecord, createdAt, clicks string) error {
+	if createdAt != "" {
+		parsed, err := parseImportTime(createdAt)
+		if err != nil {
+			return err
+		}
+		record.CreatedAt = parsed
+	}
+	if clicks != "" && clicks != "null" {
+		n, err := strconv.Atoi(clicks)
+		if err != nil || n < 0 {
+			return fmt.Errorf("invalid click count %q", clicks)
+		}
+		record.Clicks = n
+	}
+	return nil
+}
+
+// parseImportTime accepte les formats de date courants ainsi que les timestamps Unix.
+func parseImportTime(value string) (time.Time, error) {
+	for _, layout := range importTimeLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(seconds, 0), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q", value)
+}
+
+// shortCodeFromURL extrait le code court d'une URL courte complète (ex: "https://bit.ly/3abcDEF" -> "3abcDEF").
+func shortCodeFromURL(value string) string {
+	if !strings.Contains(value, "://") {
+		value = "https://" + value
+	}
+	parsed, err := url.Parse(value)
+	if err != nil {
+		return value
+	}
+	return strings.Trim(parsed.Path, "/")
+}
+
+// validateImportRecord vérifie le code court d'une ligne importée et normalise son URL longue.
+// Contrairement aux alias, les codes importés ne sont pas soumis à la liste des codes réservés :
+// ils existent déjà chez l'ancien raccourcisseur et doivent être repris tels quels.
+func (s *LinkService) validateImportRecord(record *ImportRecord) error {
+	canonicalURL, err := s.validateLongURL(record.LongURL)
+	if err != nil {
+		return err
+	}
+	record.LongURL = canonicalURL
+	if record.ShortCode != "" && !shortCodePattern.MatchString(record.ShortCode) {
+		return fmt.Errorf("invalid short code %q", record.ShortCode)
+	}
+	return nil
+}
+
+// ImportLinks crée les liens importés en conservant leurs codes courts, dates de création et clics historiques.
+// Avec batchSize <= 0, tous les liens valides sont créés dans une seule transaction.
+// Sinon, chaque lot est créé dans sa propre transaction et onBatch est appelé avec le nombre
+// d'enregistrements traités, ce qui permet de reprendre un import interrompu.
+// Un lot qui dépasserait le quota de liens de l'espace de travail interrompt l'import (ErrLinkQuotaExceeded).
+func (s *LinkService) ImportLinks(records []ImportRecord, batchSize int, onBatch func(processed int) error) (*ImportReport, error) {
+	report := &ImportReport{}
+	if batchSize <= 0 {
+		batchSize = len(records)
+	}
+
+	seen := make(map[string]bool)
+	for start := 0; start < len(records); start += batchSize {
+		end := min(start+batchSize, len(records))
+		batch := records[start:end]
+
+		codes := make([]string, 0, len(batch))
+		for _, record := range batch {
+			if record.ShortCode != "" {
+				codes = append(codes, record.ShortCode)
+			}
+		}
+		existing, err := s.linkRepo.ExistingShortCodes(models.DefaultDomainID, codes)
+		if err != nil {
+			return report, fmt.Errorf("failed to check existing short codes: %w", err)
+		}
+
+		links := make([]*models.Link, 0, len(batch))
+		for _, record := range batch {
+			if err := s.validateImportRecord(&record); err != nil {
+				report.Invalid = append(report.Invalid, ImportIssue{Line: record.Line, ShortCode: record.ShortCode, Reason: err.Error()})
+				continue
+			}
+
+			shortCode := record.ShortCode
+			if shortCode == "" {
+				shortCode, err = s.generateUniqueShortCode(models.DefaultDomainID, seen)
+				if err != nil {
+					return report, err
+				}
+			} else if existing[shortCode] || seen[shortCode] {
+				report.Conflicts = append(report.Conflicts, ImportIssue{Line: record.Line, ShortCode: shortCode, Reason: "short code already exists"})
+				continue
+			}
+			seen[shortCode] = true
+
+			createdAt := record.CreatedAt
+			if createdAt.IsZero() {
+				createdAt = time.Now()
+			}
+			links = append(links, &models.Link{
+				LongURL:        record.LongURL,
+				ShortCode:      shortCode,
+				Owner:          record.Owner,
+				URLHash:        HashURL(record.LongURL),
+				CreatedAt:      createdAt,
+				ImportedClicks: record.Clicks,
+			})
+		}
+
+		if err := s.linkRepo.CreateLinks(links); err != nil {
+			return report, s.linkQuotaError(err, "failed to save imported links")
+		}
+		report.Imported += len(links)
+
+		if onBatch != nil {
+			if err := onBatch(end); err != nil {
+				return report, err
+			}
+		}
+	}
+	return report, nil
+}
@@ -0,0 +1,142 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+	"github.com/axellelanca/urlshortener/internal/repository"
+	"github.com/axellelanca/urlshortener/internal/testutil"
+)
+
+func TestParseImportFileFormats(t *testing.T) {
+	tests := []struct {
+		format  string
+		content string
+	}{
+		{ImportFormatCSV, "short_code,long_url,created_at,clicks\nabc,https://example.com/a,2024-01-31,12\n"},
+		{ImportFormatJSONL, `{"short_code":"abc","long_url":"https://example.com/a","created_at":"2024-01-31","clicks":"12"}` + "\n\n"},
+		{ImportFormatBitly, "Bitlink,Long URL,Created,Total Clicks\nbit.ly/abc,https://example.com/a,2024-01-31 00:00:00,12\n"},
+		{ImportFormatYOURLS, "keyword,url,timestamp,clicks\nabc,https://example.com/a,2024-01-31 00:00:00,12\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			records, issues, err := ParseImportFile(strings.NewReader(tt.content), tt.format)
+			if err != nil {
+				t.Fatalf("ParseImportFile : %v", err)
+			}
+			if len(issues) != 0 {
+				t.Fatalf("problèmes inattendus : %+v", issues)
+			}
+			want := ImportRecord{Line: 2, ShortCode: "abc", LongURL: "https://example.com/a", CreatedAt: time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC), Clicks: 12}
+			if tt.format == ImportFormatJSONL {
+				want.Line = 1
+			}
+			if len(records) != 1 || records[0] != want {
+				t.Errorf("enregistrements = %+v, attendu %+v", records, want)
+			}
+		})
+	}
+}
+
+func TestParseImportFileReportsInvalidLines(t *testing.T) {
+	content := "short_code,long_url,created_at,clicks\nabc,https://example.com/a,hier,1\ndef,https://example.com/b,,-3\nghi,https://example.com/c,,\n"
+	records, issues, err := ParseImportFile(strings.NewReader(content), ImportFormatCSV)
+	if err != nil {
+		t.Fatalf("ParseImportFile : %v", err)
+	}
+	if len(records) != 1 || records[0].ShortCode != "ghi" {
+		t.Errorf("enregistrements = %+v, attendu la seule ligne ghi", records)
+	}
+	if len(issues) != 2 || issues[0].Line != 2 || issues[1].Line != 3 {
+		t.Errorf("problèmes = %+v, attendu les lignes 2 et 3", issues)
+	}
+
+	if _, _, err := ParseImportFile(strings.NewReader("code,url\n"), ImportFormatCSV); err == nil {
+		t.Error("un CSV sans colonne d'URL longue doit être refusé")
+	}
+}
+
+func TestImportLinksReportsConflictsAndInvalidRecords(t *testing.T) {
+	db := testutil.NewDB(t)
+	db.Create(&models.Link{LongURL: "https://example.com/existant", ShortCode: "taken"})
+	linkService := NewLinkService(repository.NewLinkRepository(db), LinkServiceOptions{})
+
+	report, err := linkService.ImportLinks([]ImportRecord{
+		{Line: 2, ShortCode: "abc", LongURL: "https://example.com/a", Clicks: 4},
+		{Line: 3, ShortCode: "taken", LongURL: "https://example.com/b"},
+		{Line: 4, ShortCode: "abc", LongURL: "https://example.com/c"},
+		{Line: 5, ShortCode: "def", LongURL: "ftp://example.com/d"},
+		{Line: 6, LongURL: "https://example.com/e"},
+	}, 0, nil)
+	if err != nil {
+		t.Fatalf("ImportLinks : %v", err)
+	}
+	if report.Imported != 2 {
+		t.Errorf("%d lien(s) importé(s), attendu 2", report.Imported)
+	}
+	if len(report.Conflicts) != 2 || report.Conflicts[0].Line != 3 || report.Conflicts[1].Line != 4 {
+		t.Errorf("conflits = %+v, attendu les lignes 3 et 4", report.Conflicts)
+	}
+	if len(report.Invalid) != 1 || report.Invalid[0].Line != 5 {
+		t.Errorf("lignes invalides = %+v, attendu la ligne 5", report.Invalid)
+	}
+
+	var imported models.Link
+	if err := db.Where("short_code = ?", "abc").First(&imported).Error; err != nil {
+		t.Fatalf("lien importé introuvable : %v", err)
+	}
+	if imported.ImportedClicks != 4 {
+		t.Errorf("clics historiques = %d, attendu 4", imported.ImportedClicks)
+	}
+}
+
+func TestImportLinksResumesFromCheckpoint(t *testing.T) {
+	db := testutil.NewDB(t)
+	linkService := NewLinkService(repository.NewLinkRepository(db), LinkServiceOptions{})
+	records := []ImportRecord{
+		{Line: 2, ShortCode: "a1", LongURL: "https://example.com/1"},
+		{Line: 3, ShortCode: "a2", LongURL: "https://example.com/2"},
+		{Line: 4, ShortCode: "a3", LongURL: "https://example.com/3"},
+		{Line: 5, ShortCode: "a4", LongURL: "https://example.com/4"},
+		{Line: 6, ShortCode: "a5", LongURL: "https://example.com/5"},
+	}
+
+	// L'import est interrompu après le premier lot : seul ce lot est enregistré.
+	interrupted := errors.New("interrompu")
+	checkpoint := 0
+	_, err := linkService.ImportLinks(records, 2, func(processed int) error {
+		checkpoint = processed
+		return interrupted
+	})
+	if !errors.Is(err, interrupted) {
+		t.Fatalf("ImportLinks : erreur %v, attendu l'interruption", err)
+	}
+	if checkpoint != 2 {
+		t.Fatalf("point de reprise = %d, attendu 2", checkpoint)
+	}
+
+	// La reprise repart du point enregistré, comme la commande import avec son fichier de reprise.
+	var checkpoints []int
+	report, err := linkService.ImportLinks(records[checkpoint:], 2, func(processed int) error {
+		checkpoints = append(checkpoints, checkpoint+processed)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("reprise de l'import : %v", err)
+	}
+	if report.Imported != 3 || len(report.Conflicts) != 0 {
+		t.Errorf("reprise : %d importé(s), conflits %+v, attendu 3 importés sans conflit", report.Imported, report.Conflicts)
+	}
+	if len(checkpoints) != 2 || checkpoints[0] != 4 || checkpoints[1] != 5 {
+		t.Errorf("points de reprise = %v, attendu [4 5]", checkpoints)
+	}
+
+	var count int64
+	db.Model(&models.Link{}).Count(&count)
+	if count != int64(len(records)) {
+		t.Errorf("%d lien(s) en base, attendu %d", count, len(records))
+	}
+}
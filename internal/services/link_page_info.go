@@ -0,0 +1,28 @@
+package services
+
+import "github.com/axellelanca/urlshortener/internal/models"
+
+// PageInfoQueue reçoit les liens créés dont les métadonnées de la page de destination (titre, balises OpenGraph,
+// icône) doivent être récupérées en tâche de fond. Enqueue ne doit pas bloquer la création des liens.
+type PageInfoQueue interface {
+	Enqueue(linkID uint)
+}
+
+// queuePageInfo confie les liens créés à la file de récupération des métadonnées, si elle est configurée.
+func (s *LinkService) queuePageInfo(links ...*models.Link) {
+	if s.opts.PageInfo == nil {
+		return
+	}
+	for _, link := range links {
+		s.opts.PageInfo.Enqueue(link.ID)
+	}
+}
+
+// DisplayTitle retourne le titre à afficher pour un lien : celui saisi par son propriétaire, ou à défaut
+// celui de sa page de destination.
+func DisplayTitle(link *models.Link) string {
+	if link.Title != "" {
+		return link.Title
+	}
+	return link.PageTitle
+}
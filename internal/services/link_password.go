@@ -0,0 +1,195 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/axellelanca/urlshortener/internal/config"
+	"github.com/axellelanca/urlshortener/internal/models"
+	"github.com/axellelanca/urlshortener/internal/repository"
+)
+
+// Erreurs retournées lors du déverrouillage d'un lien protégé.
+var (
+	ErrWrongPassword    = errors.New("wrong link password")
+	ErrTooManyAttempts  = errors.New("too many failed password attempts")
+	errLinkNotProtected = errors.New("link is not password protected")
+)
+
+// minCookieSecretLength est la longueur minimale d'une clé de signature configurée.
+const minCookieSecretLength = 32
+
+// hashLinkPassword retourne l'empreinte bcrypt d'un mot de passe de lien, ou une chaîne vide s'il n'y en a pas.
+func hashLinkPassword(password string) (string, error) {
+	if password == "" {
+		return "", nil
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		if errors.Is(err, bcrypt.ErrPasswordTooLong) {
+			return "", fmt.Errorf("%w: password must not exceed 72 bytes", ErrInvalidPassword)
+		}
+		return "", fmt.Errorf("failed to hash link password: %w", err)
+	}
+	return string(hash), nil
+}
+
+// PasswordGateOptions regroupe les réglages d'accès aux liens protégés.
+type PasswordGateOptions struct {
+	CookieSecret  []byte        // Clé HMAC de signature des cookies d'accès
+	CookieTTL     time.Duration // Durée de validité d'un cookie d'accès
+	MaxAttempts   int           // Mots de passe erronés tolérés par adresse IP et par lien sur AttemptWindow
+	AttemptWindow time.Duration
+}
+
+// PasswordGateOptionsFromConfig construit les options du PasswordGate à partir de la configuration chargée.
+// Sans clé configurée, une clé aléatoire est générée : les cookies ne survivent alors pas à un redémarrage.
+func PasswordGateOptionsFromConfig(cfg *config.Config) (PasswordGateOptions, error) {
+	secret := []byte(cfg.LinkPasswords.CookieSecret)
+	if len(secret) == 0 {
+		secret = make([]byte, minCookieSecretLength)
+		if _, err := rand.Read(secret); err != nil {
+			return PasswordGateOptions{}, fmt.Errorf("failed to generate cookie secret: %w", err)
+		}
+		log.Println("[PASSWORDS] Aucune clé link_passwords.cookie_secret configurée : clé aléatoire générée, les accès accordés seront perdus au redémarrage.")
+	} else if len(secret) < minCookieSecretLength {
+		return PasswordGateOptions{}, fmt.Errorf("cookie secret must be at least %d characters long", minCookieSecretLength)
+	}
+	if cfg.LinkPasswords.CookieTTLMinutes <= 0 || cfg.LinkPasswords.MaxAttempts <= 0 || cfg.LinkPasswords.AttemptWindowMinutes <= 0 {
+		return PasswordGateOptions{}, errors.New("cookie TTL, max attempts and attempt window must be positive")
+	}
+	return PasswordGateOptions{
+		CookieSecret:  secret,
+		CookieTTL:     time.Duration(cfg.LinkPasswords.CookieTTLMinutes) * time.Minute,
+		MaxAttempts:   cfg.LinkPasswords.MaxAttempts,
+		AttemptWindow: time.Duration(cfg.LinkPasswords.AttemptWindowMinutes) * time.Minute,
+	}, nil
+}
+
+// attemptWindow compte les tentatives d'une adresse IP pour un lien (erronées, ou en cours de vérification).
+type attemptWindow struct {
+	count int
+	start time.Time
+}
+
+// PasswordGate contrôle l'accès aux liens protégés par mot de passe : vérification du mot de passe,
+// limitation des tentatives erronées et émission de cookies d'accès signés.
+// Il peut être utilisé par plusieurs goroutines à la fois.
+type PasswordGate struct {
+	linkRepo repository.LinkRepository
+	opts     PasswordGateOptions
+
+	mu        sync.Mutex
+	attempts  map[string]*attemptWindow // "IP|ID du lien" -> tentatives comptées
+	lastPrune time.Time
+}
+
+// NewPasswordGate crée et retourne une nouvelle instance de PasswordGate.
+func NewPasswordGate(linkRepo repository.LinkRepository, opts PasswordGateOptions) *PasswordGate {
+	return &PasswordGate{
+		linkRepo:  linkRepo,
+		opts:      opts,
+		attempts:  make(map[string]*attemptWindow),
+		lastPrune: time.Now(),
+	}
+}
+
+// CookieName retourne le nom du cookie d'accès à un lien.
+func (g *PasswordGate) CookieName(link *models.Link) string {
+	return fmt.Sprintf("link_access_%d", link.ID)
+}
+
+// CookieTTL retourne la durée de validité des cookies d'accès.
+func (g *PasswordGate) CookieTTL() time.Duration {
+	return g.opts.CookieTTL
+}
+
+// sign calcule la signature d'un cookie d'accès. L'empreinte du mot de passe y est incluse :
+// changer le mot de passe d'un lien invalide les accès déjà accordés.
+func (g *PasswordGate) sign(link *models.Link, expires int64) string {
+	mac := hmac.New(sha256.New, g.opts.CookieSecret)
+	fmt.Fprintf(mac, "%d|%s|%d", link.ID, link.PasswordHash, expires)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Authorized indique si la valeur d'un cookie d'accès est valide (signature correcte et non expirée) pour ce lien.
+func (g *PasswordGate) Authorized(link *models.Link, cookie string) bool {
+	expiresPart, signature, ok := strings.Cut(cookie, ".")
+	if !ok {
+		return false
+	}
+	expires, err := strconv.ParseInt(expiresPart, 10, 64)
+	if err != nil || time.Now().Unix() > expires {
+		return false
+	}
+	return hmac.Equal([]byte(signature), []byte(g.sign(link, expires)))
+}
+
+// Unlock vérifie le mot de passe saisi pour un lien et retourne la valeur du cookie d'accès à poser.
+// Un mot de passe erroné est compté dans les statistiques du lien et retourne ErrWrongPassword ;
+// au-delà de MaxAttempts erreurs sur la fenêtre, ErrTooManyAttempts est retournée sans vérification.
+func (g *PasswordGate) Unlock(link *models.Link, clientIP, password string) (string, error) {
+	if link.PasswordHash == "" {
+		return "", errLinkNotProtected
+	}
+	key := fmt.Sprintf("%s|%d", clientIP, link.ID)
+	if !g.reserveAttempt(key) {
+		return "", ErrTooManyAttempts
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(link.PasswordHash), []byte(password)); err != nil {
+		if err := g.linkRepo.IncrementFailedPasswordAttempts(link.ID); err != nil {
+			log.Printf("[PASSWORDS] Échec de l'enregistrement d'une tentative erronée pour le lien %d : %v", link.ID, err)
+		}
+		return "", ErrWrongPassword
+	}
+
+	g.mu.Lock()
+	delete(g.attempts, key)
+	g.mu.Unlock()
+
+	expires := time.Now().Add(g.opts.CookieTTL).Unix()
+	return fmt.Sprintf("%d.%s", expires, g.sign(link, expires)), nil
+}
+
+// reserveAttempt compte une tentative pour la clé avant la vérification du mot de passe, en ouvrant une nouvelle
+// fenêtre si la précédente est écoulée, et retourne false si MaxAttempts tentatives ont déjà été comptées sur la
+// fenêtre en cours. Réserver la tentative sous le verrou, plutôt que d'enregistrer l'échec après la comparaison
+// bcrypt, empêche des requêtes parallèles de dépasser la limite ; un déverrouillage réussi efface le compteur.
+// Les fenêtres écoulées sont purgées au plus une fois par fenêtre pour borner la mémoire utilisée.
+func (g *PasswordGate) reserveAttempt(key string) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(g.lastPrune) > g.opts.AttemptWindow {
+		for k, window := range g.attempts {
+			if now.Sub(window.start) >= g.opts.AttemptWindow {
+				delete(g.attempts, k)
+			}
+		}
+		g.lastPrune = now
+	}
+
+	window, ok := g.attempts[key]
+	if !ok || now.Sub(window.start) >= g.opts.AttemptWindow {
+		window = &attemptWindow{start: now}
+		g.attempts[key] = window
+	}
+	if window.count >= g.opts.MaxAttempts {
+		return false
+	}
+	window.count++
+	return true
+}
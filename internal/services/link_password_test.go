@@ -0,0 +1,132 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+	"github.com/axellelanca/urlshortener/internal/repository"
+	"github.com/axellelanca/urlshortener/internal/testutil"
+	"gorm.io/gorm"
+)
+
+// newTestPasswordGate crée un PasswordGate et un lien protégé par le mot de passe "sésame".
+func newTestPasswordGate(t *testing.T, maxAttempts int) (*PasswordGate, *models.Link, *gorm.DB) {
+	t.Helper()
+	db := testutil.NewDB(t)
+	hash, err := hashLinkPassword("sésame")
+	if err != nil {
+		t.Fatalf("hashLinkPassword : %v", err)
+	}
+	link := &models.Link{LongURL: "https://example.com/secret", ShortCode: "secret", PasswordHash: hash}
+	if err := db.Create(link).Error; err != nil {
+		t.Fatalf("création du lien : %v", err)
+	}
+	gate := NewPasswordGate(repository.NewLinkRepository(db), PasswordGateOptions{
+		CookieSecret:  []byte("0123456789abcdef0123456789abcdef"),
+		CookieTTL:     time.Hour,
+		MaxAttempts:   maxAttempts,
+		AttemptWindow: time.Minute,
+	})
+	return gate, link, db
+}
+
+func TestPasswordGateCookieSignature(t *testing.T) {
+	gate, link, _ := newTestPasswordGate(t, 5)
+
+	cookie, err := gate.Unlock(link, "203.0.113.1", "sésame")
+	if err != nil {
+		t.Fatalf("Unlock : %v", err)
+	}
+	if !gate.Authorized(link, cookie) {
+		t.Fatal("le cookie émis doit donner accès au lien")
+	}
+
+	_, signature, _ := strings.Cut(cookie, ".")
+	tampered := cookie[:len(cookie)-1] + "0"
+	if strings.HasSuffix(cookie, "0") {
+		tampered = cookie[:len(cookie)-1] + "1"
+	}
+	other := *link
+	other.ID++
+	changed := *link
+	changed.PasswordHash, _ = hashLinkPassword("nouveau")
+	expired := time.Now().Add(-time.Minute).Unix()
+	otherSecret := NewPasswordGate(nil, PasswordGateOptions{CookieSecret: []byte("fedcba9876543210fedcba9876543210")})
+
+	for name, check := range map[string]bool{
+		"signature modifiée":       gate.Authorized(link, tampered),
+		"expiration prolongée":     gate.Authorized(link, fmt.Sprintf("%d.%s", time.Now().Add(48*time.Hour).Unix(), signature)),
+		"cookie expiré":            gate.Authorized(link, fmt.Sprintf("%d.%s", expired, gate.sign(link, expired))),
+		"autre lien":               gate.Authorized(&other, cookie),
+		"mot de passe changé":      gate.Authorized(&changed, cookie),
+		"autre clé de signature":   otherSecret.Authorized(link, cookie),
+		"valeur sans signature":    gate.Authorized(link, "1234567890"),
+		"expiration non numérique": gate.Authorized(link, "demain."+gate.sign(link, 0)),
+	} {
+		if check {
+			t.Errorf("%s : le cookie ne doit pas donner accès au lien", name)
+		}
+	}
+}
+
+func TestPasswordGateLimitsFailedAttempts(t *testing.T) {
+	gate, link, db := newTestPasswordGate(t, 2)
+
+	for i := 0; i < 2; i++ {
+		if _, err := gate.Unlock(link, "203.0.113.1", "faux"); !errors.Is(err, ErrWrongPassword) {
+			t.Fatalf("tentative %d : erreur %v, attendu ErrWrongPassword", i+1, err)
+		}
+	}
+	// La limite est atteinte : même le bon mot de passe est refusé sans être vérifié.
+	if _, err := gate.Unlock(link, "203.0.113.1", "sésame"); !errors.Is(err, ErrTooManyAttempts) {
+		t.Fatalf("erreur %v, attendu ErrTooManyAttempts", err)
+	}
+	// La limite s'applique par adresse IP.
+	if _, err := gate.Unlock(link, "203.0.113.2", "sésame"); err != nil {
+		t.Fatalf("une autre adresse doit pouvoir déverrouiller le lien : %v", err)
+	}
+
+	var stored models.Link
+	db.First(&stored, link.ID)
+	if stored.FailedPasswordAttempts != 2 {
+		t.Errorf("%d tentative(s) erronée(s) enregistrée(s), attendu 2", stored.FailedPasswordAttempts)
+	}
+
+	// Une fois la fenêtre écoulée, de nouvelles tentatives sont possibles.
+	gate.mu.Lock()
+	gate.attempts[fmt.Sprintf("203.0.113.1|%d", link.ID)].start = time.Now().Add(-2 * time.Minute)
+	gate.mu.Unlock()
+	if _, err := gate.Unlock(link, "203.0.113.1", "sésame"); err != nil {
+		t.Fatalf("après la fenêtre : %v", err)
+	}
+}
+
+func TestPasswordGateLimitsConcurrentAttempts(t *testing.T) {
+	gate, link, _ := newTestPasswordGate(t, 3)
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		checked int
+	)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := gate.Unlock(link, "203.0.113.1", "faux"); errors.Is(err, ErrWrongPassword) {
+				mu.Lock()
+				checked++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if checked != 3 {
+		t.Errorf("%d mot(s) de passe vérifié(s) en parallèle, attendu 3", checked)
+	}
+}
@@ -0,0 +1,67 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"unicode"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+)
+
+// ErrInvalidPath signale un chemin refusé sous un lien préfixe (remontée "..", caractère interdit, chemin trop long).
+var ErrInvalidPath = errors.New("invalid link path")
+
+// ErrInvalidKind signale un type de lien inconnu.
+var ErrInvalidKind = errors.New("invalid link kind")
+
+// maxPathLength est la longueur maximale du chemin ajouté à la destination d'un lien préfixe.
+const maxPathLength = 1024
+
+// normalizeKind vérifie le type de lien demandé et retourne sa forme enregistrée (LinkKindStandard par défaut).
+func normalizeKind(kind string) (string, error) {
+	switch kind {
+	case "", models.LinkKindStandard:
+		return models.LinkKindStandard, nil
+	case models.LinkKindPrefix:
+		return kind, nil
+	default:
+		return "", fmt.Errorf("%w: kind must be %q or %q", ErrInvalidKind, models.LinkKindStandard, models.LinkKindPrefix)
+	}
+}
+
+// JoinPath ajoute le reste du chemin demandé sous un lien préfixe (ex: "/guide/install") au chemin de sa destination.
+// Les segments vides sont ignorés ; les segments "." et "..", les barres obliques inverses et les caractères de contrôle
+// sont refusés, de sorte que le résultat reste toujours sous le chemin de la destination.
+// La requête et le fragment de la destination sont conservés.
+func JoinPath(target, remainder string) (string, error) {
+	if len(remainder) > maxPathLength {
+		return "", fmt.Errorf("%w: path must be at most %d characters", ErrInvalidPath, maxPathLength)
+	}
+	var segments, escaped []string
+	for _, segment := range strings.Split(remainder, "/") {
+		if segment == "" {
+			continue
+		}
+		if segment == "." || segment == ".." || strings.ContainsRune(segment, '\\') || strings.IndexFunc(segment, unicode.IsControl) >= 0 {
+			return "", fmt.Errorf("%w: %q", ErrInvalidPath, remainder)
+		}
+		segments = append(segments, segment)
+		escaped = append(escaped, url.PathEscape(segment))
+	}
+
+	destination, err := url.Parse(target)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse destination %q: %w", target, err)
+	}
+	joined := strings.TrimSuffix(destination.Path, "/") + "/" + strings.Join(segments, "/")
+	joinedRaw := strings.TrimSuffix(destination.EscapedPath(), "/") + "/" + strings.Join(escaped, "/")
+	if len(segments) > 0 && strings.HasSuffix(remainder, "/") {
+		joined += "/"
+		joinedRaw += "/"
+	}
+	destination.Path = joined
+	destination.RawPath = joinedRaw
+	return destination.String(), nil
+}
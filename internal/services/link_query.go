@@ -0,0 +1,126 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+)
+
+// ErrInvalidCampaign signale des réglages de campagne invalides (mode de transmission inconnu, modèle UTM mal formé...).
+var ErrInvalidCampaign = errors.New("invalid campaign settings")
+
+// Modes de transmission des paramètres de requête du lien court vers la destination.
+const (
+	QueryForwardingNone     = ""         // Les paramètres du lien court sont ignorés
+	QueryForwardingMerge    = "merge"    // Ajoutés à la destination, sans remplacer ceux qu'elle porte déjà
+	QueryForwardingOverride = "override" // Ajoutés à la destination, en remplaçant ceux qu'elle porte déjà
+)
+
+// maxUTMLength est la longueur maximale d'un modèle UTM.
+const maxUTMLength = 100
+
+// utmPlaceholder repère les variables d'un modèle UTM, ex: "{source}".
+var utmPlaceholder = regexp.MustCompile(`\{[a-z]+\}`)
+
+// utmVariables liste les variables reconnues dans les modèles UTM.
+var utmVariables = map[string]bool{
+	"{code}":    true, // Code court du lien
+	"{source}":  true, // Marqueur de source du clic (ex: "qr")
+	"{rule}":    true, // Règle de redirection appliquée
+	"{variant}": true, // Variante A/B servie
+}
+
+// UTMTemplate regroupe les paramètres UTM ajoutés à la destination d'un lien de campagne.
+// Les valeurs peuvent contenir les variables {code}, {source}, {rule} et {variant}, remplacées à chaque redirection ;
+// un paramètre dont la valeur finale est vide n'est pas ajouté.
+type UTMTemplate struct {
+	Source   string `json:"source,omitempty"`   // utm_source
+	Medium   string `json:"medium,omitempty"`   // utm_medium
+	Campaign string `json:"campaign,omitempty"` // utm_campaign
+}
+
+// IsZero indique qu'aucun paramètre UTM n'est défini.
+func (t UTMTemplate) IsZero() bool {
+	return t == UTMTemplate{}
+}
+
+// UTMTemplateOf retourne le modèle UTM enregistré sur un lien.
+func UTMTemplateOf(link *models.Link) UTMTemplate {
+	return UTMTemplate{Source: link.UTMSource, Medium: link.UTMMedium, Campaign: link.UTMCampaign}
+}
+
+// params associe chaque paramètre UTM à son modèle.
+func (t UTMTemplate) params() [][2]string {
+	return [][2]string{{"utm_source", t.Source}, {"utm_medium", t.Medium}, {"utm_campaign", t.Campaign}}
+}
+
+// RedirectContext décrit une redirection, pour le remplissage des modèles UTM.
+type RedirectContext struct {
+	Source  string // Marqueur de source du clic
+	Rule    string // Règle de redirection appliquée
+	Variant string // Variante A/B servie
+}
+
+// validateCampaign vérifie le mode de transmission des paramètres de requête et le modèle UTM d'un lien.
+func validateCampaign(forwarding string, utm UTMTemplate) error {
+	switch forwarding {
+	case QueryForwardingNone, QueryForwardingMerge, QueryForwardingOverride:
+	default:
+		return fmt.Errorf("%w: query forwarding must be %q or %q", ErrInvalidCampaign, QueryForwardingMerge, QueryForwardingOverride)
+	}
+	for _, param := range utm.params() {
+		name, template := param[0], param[1]
+		if len(template) > maxUTMLength {
+			return fmt.Errorf("%w: %s must be at most %d characters", ErrInvalidCampaign, name, maxUTMLength)
+		}
+		for _, variable := range utmPlaceholder.FindAllString(template, -1) {
+			if !utmVariables[variable] {
+				return fmt.Errorf("%w: %s: unknown variable %s", ErrInvalidCampaign, name, variable)
+			}
+		}
+	}
+	return nil
+}
+
+// RedirectURL construit l'URL finale d'une redirection vers target : les paramètres UTM du lien sont ajoutés
+// (ils remplacent ceux de la destination), puis les paramètres de la requête du lien court (incoming) sont transmis
+// selon le mode du lien. incoming ne doit plus contenir les paramètres propres au raccourcisseur (aperçu, source...).
+// La destination est retournée telle quelle s'il n'y a rien à ajouter.
+func (s *LinkService) RedirectURL(link *models.Link, target string, incoming url.Values, ctx RedirectContext) string {
+	utm := UTMTemplateOf(link)
+	if utm.IsZero() && (link.QueryForwarding == QueryForwardingNone || len(incoming) == 0) {
+		return target
+	}
+	destination, err := url.Parse(target)
+	if err != nil {
+		return target
+	}
+	query := destination.Query()
+
+	replacer := strings.NewReplacer("{code}", link.ShortCode, "{source}", ctx.Source, "{rule}", ctx.Rule, "{variant}", ctx.Variant)
+	for _, param := range utm.params() {
+		if value := replacer.Replace(param[1]); value != "" {
+			query.Set(param[0], value)
+		}
+	}
+
+	switch link.QueryForwarding {
+	case QueryForwardingMerge:
+		for name, values := range incoming {
+			if !query.Has(name) {
+				query[name] = values
+			}
+		}
+	case QueryForwardingOverride:
+		for name, values := range incoming {
+			query[name] = values
+		}
+	}
+
+	destination.RawQuery = query.Encode()
+	return destination.String()
+}
@@ -0,0 +1,121 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+	"github.com/axellelanca/urlshortener/internal/repository"
+)
+
+// Erreurs retournées lorsqu'un quota d'espace de travail est atteint.
+var (
+	ErrLinkQuotaExceeded  = errors.New("workspace link quota exceeded")
+	ErrClickQuotaExceeded = errors.New("workspace monthly click quota exceeded")
+)
+
+// ForWorkspace retourne un LinkService restreint à un espace de travail : il ne voit que les liens de cet espace,
+// les liens qu'il crée y sont rattachés et le quota de liens de l'espace est appliqué à leur création, dans la
+// transaction même qui les insère.
+func (s *LinkService) ForWorkspace(workspace *models.Workspace) *LinkService {
+	scoped := *s
+	scoped.linkRepo = s.linkRepo.ForWorkspace(workspace.ID).WithLinkLimit(workspace.MaxLinks)
+	scoped.workspace = workspace
+	return &scoped
+}
+
+// remainingLinks retourne le nombre de liens que l'espace de travail du service peut encore créer,
+// ou -1 s'il n'a pas de quota de liens. Ce n'est qu'une estimation, qui permet de signaler les éléments d'un lot
+// hors quota : le quota est garanti par le dépôt lors de la création (voir linkQuotaError).
+func (s *LinkService) remainingLinks() (int, error) {
+	if s.workspace == nil || s.workspace.MaxLinks == 0 {
+		return -1, nil
+	}
+	count, err := s.linkRepo.CountLinks()
+	if err != nil {
+		return 0, fmt.Errorf("database error counting links: %w", err)
+	}
+	return max(s.workspace.MaxLinks-int(count), 0), nil
+}
+
+// linkQuotaError traduit le refus d'une création par le dépôt, faute de place dans l'espace de travail,
+// en ErrLinkQuotaExceeded. Les autres erreurs sont retournées enveloppées avec le message fourni.
+func (s *LinkService) linkQuotaError(err error, message string) error {
+	if errors.Is(err, repository.ErrLinkLimitReached) && s.workspace != nil {
+		return fmt.Errorf("%w: workspace %q may hold %d link(s)", ErrLinkQuotaExceeded, s.workspace.Name, s.workspace.MaxLinks)
+	}
+	return fmt.Errorf("%s: %w", message, err)
+}
+
+// QuotaPeriodStart retourne le début du mois calendaire (UTC) contenant t, sur lequel les clics sont décomptés.
+func QuotaPeriodStart(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// clickQuotaTTL est la durée pendant laquelle l'état du quota de clics d'un espace de travail est réutilisé sans
+// interroger la base : les redirections n'ajoutent ainsi aucune requête, et les clics du mois ne sont comptés
+// qu'une fois par intervalle et par espace de travail. Une modification des quotas s'applique dans ce délai.
+const clickQuotaTTL = 10 * time.Second
+
+// clickQuotaState est l'état du quota de clics d'un espace de travail, relevé en base à checkedAt.
+type clickQuotaState struct {
+	exceeded  error     // ErrClickQuotaExceeded (enveloppée) si le quota est épuisé, nil sinon
+	period    time.Time // Début du mois sur lequel les clics ont été comptés
+	checkedAt time.Time
+}
+
+// clickQuotaCache conserve l'état du quota de clics de chaque espace de travail pendant clickQuotaTTL.
+type clickQuotaCache struct {
+	mu     sync.Mutex
+	states map[uint]clickQuotaState
+}
+
+// CheckClickQuota retourne ErrClickQuotaExceeded si l'espace de travail d'un lien a épuisé son quota de clics
+// du mois en cours : ses liens ne redirigent alors plus jusqu'au mois suivant. L'état du quota est mis en cache
+// pendant clickQuotaTTL ; les clics étant de plus enregistrés de façon asynchrone, le quota peut être légèrement
+// dépassé.
+func (s *LinkService) CheckClickQuota(link *models.Link, now time.Time) error {
+	if link.WorkspaceID == models.DefaultWorkspaceID || s.opts.Workspaces == nil {
+		return nil
+	}
+	period := QuotaPeriodStart(now)
+
+	s.clickQuotas.mu.Lock()
+	state, ok := s.clickQuotas.states[link.WorkspaceID]
+	s.clickQuotas.mu.Unlock()
+	if ok && state.period.Equal(period) && now.Sub(state.checkedAt) < clickQuotaTTL {
+		return state.exceeded
+	}
+
+	exceeded, err := s.clickQuotaExceeded(link.WorkspaceID, period)
+	if err != nil {
+		return err
+	}
+	s.clickQuotas.mu.Lock()
+	s.clickQuotas.states[link.WorkspaceID] = clickQuotaState{exceeded: exceeded, period: period, checkedAt: now}
+	s.clickQuotas.mu.Unlock()
+	return exceeded
+}
+
+// clickQuotaExceeded relève en base le quota de clics d'un espace de travail et ses clics depuis le début de period.
+// Elle retourne ErrClickQuotaExceeded (enveloppée) si le quota est épuisé, ou une erreur de base de données.
+func (s *LinkService) clickQuotaExceeded(workspaceID uint, period time.Time) (exceeded error, err error) {
+	workspace, err := s.opts.Workspaces.GetWorkspaceByID(workspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load workspace %d: %w", workspaceID, err)
+	}
+	if workspace.MaxClicksPerMonth == 0 {
+		return nil, nil
+	}
+	count, err := s.linkRepo.ForWorkspace(workspace.ID).CountClicksSince(period)
+	if err != nil {
+		return nil, fmt.Errorf("database error counting clicks: %w", err)
+	}
+	if count >= int64(workspace.MaxClicksPerMonth) {
+		return fmt.Errorf("%w: workspace %q allows %d click(s) per month", ErrClickQuotaExceeded, workspace.Name, workspace.MaxClicksPerMonth), nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,159 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+	"github.com/axellelanca/urlshortener/internal/repository"
+	"github.com/axellelanca/urlshortener/internal/testutil"
+)
+
+func TestCheckClickQuota(t *testing.T) {
+	db := testutil.NewDB(t)
+	workspaceRepo := repository.NewWorkspaceRepository(db)
+	clickRepo := repository.NewClickRepository(db)
+	linkService := NewLinkService(repository.NewLinkRepository(db), LinkServiceOptions{Workspaces: workspaceRepo})
+
+	workspace := &models.Workspace{Name: "marketing", MaxClicksPerMonth: 2}
+	if err := workspaceRepo.CreateWorkspace(workspace); err != nil {
+		t.Fatalf("création de l'espace de travail : %v", err)
+	}
+	link, _, err := linkService.ForWorkspace(workspace).CreateLink(CreateLinkInput{LongURL: "https://example.com/"})
+	if err != nil {
+		t.Fatalf("création du lien : %v", err)
+	}
+
+	now := time.Date(2024, 6, 15, 12, 0, 0, 0, time.UTC)
+	addClick := func(at time.Time) {
+		t.Helper()
+		if err := clickRepo.CreateClick(&models.Click{LinkID: link.ID, WorkspaceID: workspace.ID, Timestamp: at}); err != nil {
+			t.Fatalf("création du clic : %v", err)
+		}
+	}
+	addClick(now.AddDate(0, -1, 0)) // Mois précédent : non décompté
+	addClick(now.Add(-time.Hour))
+
+	if err := linkService.CheckClickQuota(link, now); err != nil {
+		t.Fatalf("CheckClickQuota avec 1 clic sur 2 : %v", err)
+	}
+
+	// Pendant clickQuotaTTL, l'état en cache est réutilisé sans compter les clics en base.
+	addClick(now)
+	if err := linkService.CheckClickQuota(link, now.Add(clickQuotaTTL/2)); err != nil {
+		t.Fatalf("CheckClickQuota pendant clickQuotaTTL : %v, attendu l'état en cache", err)
+	}
+
+	if err := linkService.CheckClickQuota(link, now.Add(clickQuotaTTL)); !errors.Is(err, ErrClickQuotaExceeded) {
+		t.Fatalf("CheckClickQuota après clickQuotaTTL : %v, attendu ErrClickQuotaExceeded", err)
+	}
+
+	// Le quota repart à zéro au début du mois suivant, sans attendre l'expiration du cache.
+	nextMonth := QuotaPeriodStart(now).AddDate(0, 1, 0)
+	if err := linkService.CheckClickQuota(link, nextMonth); err != nil {
+		t.Fatalf("CheckClickQuota au mois suivant : %v", err)
+	}
+}
+
+func TestCheckClickQuotaIgnoresDefaultWorkspace(t *testing.T) {
+	db := testutil.NewDB(t)
+	linkService := NewLinkService(repository.NewLinkRepository(db), LinkServiceOptions{Workspaces: repository.NewWorkspaceRepository(db)})
+
+	link := &models.Link{ShortCode: "public", LongURL: "https://example.com/"}
+	if err := linkService.CheckClickQuota(link, time.Now()); err != nil {
+		t.Fatalf("CheckClickQuota pour l'espace par défaut : %v", err)
+	}
+}
+
+func TestLinkQuotaHoldsUnderConcurrentCreation(t *testing.T) {
+	db := testutil.NewDB(t)
+	workspaceRepo := repository.NewWorkspaceRepository(db)
+	workspace := &models.Workspace{Name: "marketing", MaxLinks: 3}
+	if err := workspaceRepo.CreateWorkspace(workspace); err != nil {
+		t.Fatalf("création de l'espace de travail : %v", err)
+	}
+	linkService := NewLinkService(repository.NewLinkRepository(db), LinkServiceOptions{Workspaces: workspaceRepo}).ForWorkspace(workspace)
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		created  int
+		rejected int
+		start    = make(chan struct{})
+	)
+	for i := 0; i < 12; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			_, _, err := linkService.CreateLink(CreateLinkInput{LongURL: fmt.Sprintf("https://example.com/%d", i)})
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err == nil:
+				created++
+			case errors.Is(err, ErrLinkQuotaExceeded):
+				rejected++
+			default:
+				t.Errorf("CreateLink : %v", err)
+			}
+		}()
+	}
+	close(start)
+	wg.Wait()
+
+	count, err := repository.NewLinkRepository(db).ForWorkspace(workspace.ID).CountLinks()
+	if err != nil {
+		t.Fatalf("CountLinks : %v", err)
+	}
+	if created != 3 || rejected != 9 || count != 3 {
+		t.Errorf("%d lien(s) créé(s), %d refusé(s), %d en base, attendu 3 créés et 9 refusés", created, rejected, count)
+	}
+
+	// Le quota s'applique aussi à un lot et à un import : aucun lien n'est alors créé.
+	if _, err := linkService.ImportLinks([]ImportRecord{{LongURL: "https://example.com/import"}}, 0, nil); !errors.Is(err, ErrLinkQuotaExceeded) {
+		t.Errorf("ImportLinks au-delà du quota : %v, attendu ErrLinkQuotaExceeded", err)
+	}
+}
+
+func TestMoveLinksRespectsDestinationQuota(t *testing.T) {
+	db := testutil.NewDB(t)
+	workspaceRepo := repository.NewWorkspaceRepository(db)
+	linkRepo := repository.NewLinkRepository(db)
+	workspaceService := NewWorkspaceService(workspaceRepo, linkRepo)
+	linkService := NewLinkService(linkRepo, LinkServiceOptions{Workspaces: workspaceRepo})
+
+	source := &models.Workspace{Name: "source"}
+	target := &models.Workspace{Name: "cible", MaxLinks: 2}
+	for _, workspace := range []*models.Workspace{source, target} {
+		if err := workspaceRepo.CreateWorkspace(workspace); err != nil {
+			t.Fatalf("création de l'espace de travail : %v", err)
+		}
+	}
+	if _, _, err := linkService.ForWorkspace(target).CreateLink(CreateLinkInput{LongURL: "https://example.com/cible"}); err != nil {
+		t.Fatalf("création du lien : %v", err)
+	}
+	var codes []string
+	for i := 0; i < 2; i++ {
+		link, _, err := linkService.ForWorkspace(source).CreateLink(CreateLinkInput{LongURL: fmt.Sprintf("https://example.com/%d", i)})
+		if err != nil {
+			t.Fatalf("création du lien : %v", err)
+		}
+		codes = append(codes, link.ShortCode)
+	}
+
+	if _, err := workspaceService.MoveLinks(MoveLinksInput{From: source, To: target, All: true}); !errors.Is(err, ErrLinkQuotaExceeded) {
+		t.Fatalf("MoveLinks au-delà du quota : %v, attendu ErrLinkQuotaExceeded", err)
+	}
+	if count, _ := linkRepo.ForWorkspace(source.ID).CountLinks(); count != 2 {
+		t.Errorf("%d lien(s) restant(s) dans l'espace d'origine, attendu 2 : le déplacement doit être annulé", count)
+	}
+
+	moved, err := workspaceService.MoveLinks(MoveLinksInput{From: source, To: target, ShortCodes: codes[:1]})
+	if err != nil || moved != 1 {
+		t.Fatalf("MoveLinks dans la limite du quota : %d déplacé(s), %v", moved, err)
+	}
+}
@@ -0,0 +1,90 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+)
+
+// ErrInvalidRedirect signale des réglages de réponse invalides (code de redirection non supporté, Cache-Control mal formé).
+var ErrInvalidRedirect = errors.New("invalid redirect settings")
+
+// DefaultRedirectStatus est le code de redirection des liens qui n'en choisissent pas.
+const DefaultRedirectStatus = http.StatusFound
+
+// redirectStatuses liste les codes de redirection qu'un lien peut choisir.
+var redirectStatuses = map[int]bool{
+	http.StatusMovedPermanently:  true, // 301 : permanente, mise en cache par les navigateurs (référencement)
+	http.StatusFound:             true, // 302 : temporaire
+	http.StatusTemporaryRedirect: true, // 307 : temporaire, méthode conservée
+	http.StatusPermanentRedirect: true, // 308 : permanente, méthode conservée
+}
+
+// maxCacheControlLength est la longueur maximale de l'en-tête Cache-Control d'un lien.
+const maxCacheControlLength = 100
+
+// cacheDirectives liste les directives Cache-Control acceptées, et si elles attendent une durée en secondes.
+var cacheDirectives = map[string]bool{
+	"public":                 false,
+	"private":                false,
+	"no-cache":               false,
+	"no-store":               false,
+	"no-transform":           false,
+	"must-revalidate":        false,
+	"proxy-revalidate":       false,
+	"immutable":              false,
+	"max-age":                true,
+	"s-maxage":               true,
+	"stale-while-revalidate": true,
+	"stale-if-error":         true,
+}
+
+var secondsPattern = regexp.MustCompile(`^[0-9]{1,10}$`)
+
+// RedirectStatusOf retourne le code de redirection d'un lien (DefaultRedirectStatus s'il n'en a pas choisi).
+func RedirectStatusOf(link *models.Link) int {
+	if link.RedirectStatus == 0 {
+		return DefaultRedirectStatus
+	}
+	return link.RedirectStatus
+}
+
+// normalizeRedirect vérifie le code de redirection et l'en-tête Cache-Control demandés pour un lien,
+// et retourne leur forme enregistrée (code par défaut, directives en minuscules séparées par ", ").
+func normalizeRedirect(status int, cacheControl string) (int, string, error) {
+	if status == 0 {
+		status = DefaultRedirectStatus
+	}
+	if !redirectStatuses[status] {
+		return 0, "", fmt.Errorf("%w: redirect status must be 301, 302, 307 or 308", ErrInvalidRedirect)
+	}
+	if strings.TrimSpace(cacheControl) == "" {
+		return status, "", nil
+	}
+
+	var directives []string
+	for _, directive := range strings.Split(cacheControl, ",") {
+		directive = strings.ToLower(strings.TrimSpace(directive))
+		name, value, hasValue := strings.Cut(directive, "=")
+		takesSeconds, known := cacheDirectives[name]
+		if !known {
+			return 0, "", fmt.Errorf("%w: unsupported Cache-Control directive %q", ErrInvalidRedirect, directive)
+		}
+		if takesSeconds != hasValue || (hasValue && !secondsPattern.MatchString(value)) {
+			if takesSeconds {
+				return 0, "", fmt.Errorf("%w: Cache-Control directive %s needs a number of seconds", ErrInvalidRedirect, name)
+			}
+			return 0, "", fmt.Errorf("%w: Cache-Control directive %s takes no value", ErrInvalidRedirect, name)
+		}
+		directives = append(directives, directive)
+	}
+	normalized := strings.Join(directives, ", ")
+	if len(normalized) > maxCacheControlLength {
+		return 0, "", fmt.Errorf("%w: Cache-Control must be at most %d characters", ErrInvalidRedirect, maxCacheControlLength)
+	}
+	return status, normalized, nil
+}
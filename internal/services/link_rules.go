@@ -0,0 +1,48 @@
+package services
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+	"github.com/axellelanca/urlshortener/internal/targeting"
+)
+
+// encodeRules vérifie les règles de redirection d'un lien, valide et normalise leurs URLs cibles
+// comme l'URL longue, puis les encode pour leur stockage.
+func (s *LinkService) encodeRules(rules []targeting.Rule) (string, error) {
+	normalized, err := targeting.Normalize(rules)
+	if err != nil {
+		return "", err
+	}
+	if targeting.UsesCountry(normalized) && !s.opts.GeoIP.Enabled() {
+		return "", fmt.Errorf("%w: country conditions require a GeoIP database (geoip.database_file)", ErrInvalidRule)
+	}
+	for i := range normalized {
+		target, err := s.validateLongURL(normalized[i].Target)
+		if err != nil {
+			return "", fmt.Errorf("rule %d target: %w", i+1, err)
+		}
+		normalized[i].Target = target
+	}
+	return targeting.Encode(normalized)
+}
+
+// RedirectTarget retourne l'URL vers laquelle rediriger un visiteur : la cible de la première règle qui
+// lui correspond, ou l'URL longue. Le nom de la règle appliquée est retourné (vide pour l'URL longue).
+// L'adresse IP du visiteur n'est géolocalisée que si une règle du lien dépend du pays.
+func (s *LinkService) RedirectTarget(link *models.Link, req targeting.Request, clientIP string) (string, string) {
+	rules, err := targeting.Decode(link.RedirectRules)
+	if err != nil {
+		log.Printf("Unreadable redirect rules for link %s, falling back to the long URL: %v", link.ShortCode, err)
+		return link.LongURL, ""
+	}
+	if req.Country == "" && targeting.UsesCountry(rules) {
+		req.Country = s.opts.GeoIP.Lookup(clientIP).Country
+	}
+	index, ok := targeting.Match(rules, req)
+	if !ok {
+		return link.LongURL, ""
+	}
+	return rules[index].Target, targeting.Label(rules, index)
+}
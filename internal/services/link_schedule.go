@@ -0,0 +1,103 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"os"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+	"github.com/axellelanca/urlshortener/internal/repository"
+)
+
+// ErrInvalidSchedule signale une fenêtre d'activité invalide (fin avant le début, URL de repli sans fenêtre...).
+var ErrInvalidSchedule = errors.New("invalid link schedule")
+
+// ParseScheduleTime interprète une borne de fenêtre d'activité (ex: "2024-01-31" ou RFC 3339).
+func ParseScheduleTime(value string) (time.Time, error) {
+	return parseImportTime(value)
+}
+
+// LinkStatus retourne l'état d'un lien vis-à-vis de sa fenêtre d'activité à l'instant now
+// (models.LinkStatusScheduled, LinkStatusActive ou LinkStatusEnded).
+func LinkStatus(link *models.Link, now time.Time) string {
+	if link.ActiveFrom != nil && now.Before(*link.ActiveFrom) {
+		return models.LinkStatusScheduled
+	}
+	if link.ActiveUntil != nil && !now.Before(*link.ActiveUntil) {
+		return models.LinkStatusEnded
+	}
+	return models.LinkStatusActive
+}
+
+// IsValidLinkStatus indique si status est un état de fenêtre d'activité connu, pour le filtrage des listes.
+func IsValidLinkStatus(status string) bool {
+	switch status {
+	case models.LinkStatusScheduled, models.LinkStatusActive, models.LinkStatusEnded:
+		return true
+	}
+	return false
+}
+
+// normalizeSchedule vérifie la fenêtre d'activité demandée pour un lien et retourne ses bornes en UTC.
+// L'URL de repli (servie hors de la fenêtre) doit avoir été validée par l'appelant.
+func normalizeSchedule(from, until *time.Time, inactiveURL string) (*time.Time, *time.Time, error) {
+	if from == nil && until == nil {
+		if inactiveURL != "" {
+			return nil, nil, fmt.Errorf("%w: an inactive URL needs an activation window", ErrInvalidSchedule)
+		}
+		return nil, nil, nil
+	}
+	if from != nil {
+		utc := from.UTC()
+		from = &utc
+	}
+	if until != nil {
+		utc := until.UTC()
+		until = &utc
+	}
+	if from != nil && until != nil && !until.After(*from) {
+		return nil, nil, fmt.Errorf("%w: the window must end after it starts", ErrInvalidSchedule)
+	}
+	return from, until, nil
+}
+
+// loadInactivePage charge le modèle HTML de la page d'indisponibilité configuré (aucun si path est vide).
+func loadInactivePage(path string) (*template.Template, error) {
+	if path == "" {
+		return nil, nil
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read inactive page %s: %w", path, err)
+	}
+	page, err := template.New("inactive").Parse(string(content))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse inactive page %s: %w", path, err)
+	}
+	return page, nil
+}
+
+// InactivePage retourne le modèle configuré de la page servie hors de la fenêtre d'activité d'un lien,
+// ou nil pour la page intégrée.
+func (s *LinkService) InactivePage() *template.Template {
+	return s.opts.InactivePage
+}
+
+// ListLinks retourne une page des liens correspondant au filtre (du plus récent au plus ancien), avec leurs
+// étiquettes, et le nombre total de liens correspondants.
+func (s *LinkService) ListLinks(filter repository.LinkFilter, offset, limit int) ([]models.Link, int64, error) {
+	links, total, err := s.linkRepo.ListLinks(filter, offset, limit)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list links: %w", err)
+	}
+	page := make([]*models.Link, len(links))
+	for i := range links {
+		page[i] = &links[i]
+	}
+	if err := s.LoadTags(page...); err != nil {
+		return nil, 0, err
+	}
+	return links, total, nil
+}
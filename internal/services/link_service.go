@@ -1,21 +1,45 @@
 package services
 
 import (
-	"crypto/rand"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"html/template"
 	"log"
-	"math/big"
+	"strings"
 	"time"
 
-	"gorm.io/gorm"
-
+	"github.com/axellelanca/urlshortener/internal/config"
+	"github.com/axellelanca/urlshortener/internal/experiment"
+	"github.com/axellelanca/urlshortener/internal/geoip"
 	"github.com/axellelanca/urlshortener/internal/models"
 	"github.com/axellelanca/urlshortener/internal/repository"
+	"github.com/axellelanca/urlshortener/internal/screening"
+	"github.com/axellelanca/urlshortener/internal/shortcode"
+	"github.com/axellelanca/urlshortener/internal/targeting"
+	"github.com/axellelanca/urlshortener/internal/validation"
+)
+
+// Erreurs métier retournées lors de la création d'un lien, à distinguer des erreurs techniques.
+var (
+	ErrInvalidURL   = validation.ErrInvalidURL
+	ErrInvalidAlias = errors.New("invalid alias")
+	ErrAliasTaken   = errors.New("alias already in use")
+	// ErrInvalidRule signale une règle de redirection invalide (condition inconnue, plage horaire mal formée...).
+	ErrInvalidRule = targeting.ErrInvalidRule
+	// ErrInvalidVariant signale une répartition A/B invalide (moins de deux variantes, poids hors limites...).
+	ErrInvalidVariant = experiment.ErrInvalidVariant
+	// ErrInvalidPassword signale un mot de passe de lien trop long (bcrypt n'en utilise que les 72 premiers octets).
+	ErrInvalidPassword = errors.New("invalid link password")
+	// ErrBlockedDestination signale une destination présente dans une liste de blocage (phishing, malware...).
+	ErrBlockedDestination = screening.ErrBlocked
 )
 
-// Définition du jeu de caractères pour la génération des codes courts.
-const charset = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+// reservedShortCodes liste les codes qui masqueraient une route de l'API s'ils étaient utilisés comme alias.
+var reservedShortCodes = map[string]bool{
+	"api":    true,
+	"health": true,
+}
 
 // TODO Créer la struct
 // LinkService est une structure qui g fournit des méthodes pour la logique métier des liens.
@@ -23,109 +47,453 @@ const charset = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
 // IMPORTANT : Le champ doit être du type de l'interface (non-pointeur).
 type LinkService struct {
 	linkRepo repository.LinkRepository
+	opts     LinkServiceOptions
+	codes    *shortcode.Adaptive // Génération des codes courts selon la stratégie configurée
+	// workspace est l'espace de travail auquel le service est restreint (ForWorkspace), nil pour tous :
+	// les liens créés vont alors dans l'espace par défaut, sans quota.
+	workspace *models.Workspace
+	// clickQuotas met en cache l'état du quota de clics des espaces de travail, partagé par les copies ForWorkspace.
+	clickQuotas *clickQuotaCache
+}
+
+// LinkServiceOptions regroupe les réglages globaux de création des liens.
+type LinkServiceOptions struct {
+	Deduplicate    bool                           // Retourne le lien existant pour une URL identique du même propriétaire
+	IdempotencyTTL time.Duration                  // Durée de validité des clés d'idempotence
+	URLValidator   *validation.URLValidator       // Validation des URLs longues ; nil pour les règles par défaut
+	Blocklist      *screening.Blocklist           // Listes de blocage des destinations ; nil pour ne rien filtrer
+	ShortCodes     shortcode.Options              // Génération des codes courts ; valeurs nulles pour les réglages par défaut
+	GeoIP          *geoip.Resolver                // Géolocalisation des visiteurs pour les règles par pays ; nil si désactivée
+	VariantTTL     time.Duration                  // Durée pendant laquelle un visiteur garde sa variante A/B
+	InactivePage   *template.Template             // Page servie hors de la fenêtre d'activité d'un lien ; nil pour la page intégrée
+	PageInfo       PageInfoQueue                  // Récupération des métadonnées des destinations des liens créés ; nil pour ne rien récupérer
+	Workspaces     repository.WorkspaceRepository // Espaces de travail, pour leurs quotas de clics ; nil pour ne pas les appliquer
+}
+
+// LinkServiceOptionsFromConfig construit les options du LinkService à partir de la configuration chargée.
+// Une erreur est retournée si un fichier de blocage, la base GeoIP ou la page d'indisponibilité configurés
+// ne peuvent pas être chargés ou si les réglages de génération des codes courts sont incohérents.
+func LinkServiceOptionsFromConfig(cfg *config.Config) (LinkServiceOptions, error) {
+	shortCodes := shortcode.Options{
+		Strategy:        cfg.ShortCodes.Strategy,
+		Length:          cfg.ShortCodes.Length,
+		Alphabet:        cfg.ShortCodes.Alphabet,
+		MaxLength:       cfg.ShortCodes.MaxLength,
+		MaxRetries:      cfg.ShortCodes.MaxRetries,
+		GrowthThreshold: cfg.ShortCodes.GrowthThreshold,
+	}
+	if err := shortCodes.Validate(); err != nil {
+		return LinkServiceOptions{}, fmt.Errorf("invalid short code settings: %w", err)
+	}
+	if cfg.ShortCodes.WordFilter {
+		filter, err := shortcode.NewWordFilter(cfg.ShortCodes.BlockedWordsFile)
+		if err != nil {
+			return LinkServiceOptions{}, fmt.Errorf("failed to load blocked words: %w", err)
+		}
+		shortCodes.Filter = filter
+	}
+
+	blocklist, err := screening.NewBlocklist(screening.Files{
+		Domains: cfg.Screening.DomainFiles,
+		Regexps: cfg.Screening.RegexFiles,
+		Hosts:   cfg.Screening.HostsFiles,
+	})
+	if err != nil {
+		return LinkServiceOptions{}, fmt.Errorf("failed to load blocklists: %w", err)
+	}
+
+	geo, err := geoip.Open(cfg.GeoIP.DatabaseFile)
+	if err != nil {
+		return LinkServiceOptions{}, err
+	}
+
+	inactivePage, err := loadInactivePage(cfg.Links.InactivePageFile)
+	if err != nil {
+		return LinkServiceOptions{}, err
+	}
+
+	return LinkServiceOptions{
+		Deduplicate:    cfg.Links.Deduplicate,
+		IdempotencyTTL: time.Duration(cfg.Links.IdempotencyTTLHours) * time.Hour,
+		VariantTTL:     time.Duration(cfg.Links.VariantCookieDays) * 24 * time.Hour,
+		URLValidator: validation.NewURLValidator(validation.Options{
+			MaxLength:    cfg.Validation.MaxURLLength,
+			BlockPrivate: cfg.Validation.BlockPrivateDestinations,
+			ResolveHosts: cfg.Validation.ResolveHosts,
+			OwnURLs:      []string{cfg.Server.BaseURL},
+		}),
+		Blocklist:    blocklist,
+		ShortCodes:   shortCodes,
+		GeoIP:        geo,
+		InactivePage: inactivePage,
+	}, nil
 }
 
 // NewLinkService crée et retourne une nouvelle instance de LinkService.
-func NewLinkService(linkRepo repository.LinkRepository) *LinkService {
+func NewLinkService(linkRepo repository.LinkRepository, opts LinkServiceOptions) *LinkService {
+	if opts.URLValidator == nil {
+		opts.URLValidator = validation.NewURLValidator(validation.Options{})
+	}
 	return &LinkService{
 		linkRepo: linkRepo,
+		opts:     opts,
+		codes:    shortcode.New(opts.ShortCodes, linkRepo.NextCodeSequence),
+		clickQuotas: &clickQuotaCache{
+			states: make(map[uint]clickQuotaState),
+		},
+	}
+}
+
+// CreateLinkInput regroupe les paramètres de création d'un lien.
+type CreateLinkInput struct {
+	LongURL         string
+	DomainID        uint                 // Domaine du lien, models.DefaultDomainID par défaut
+	Alias           string               // Optionnel : code court personnalisé, généré si vide
+	Owner           string               // Optionnel
+	Metadata        map[string]string    // Optionnel : données libres associées au lien
+	Deduplicate     *bool                // Optionnel : remplace le réglage global de déduplication
+	IdempotencyKey  string               // Optionnel : rejouer la même clé retourne le lien déjà créé
+	Password        string               // Optionnel : mot de passe demandé avant la redirection
+	AlwaysPreview   bool                 // Optionnel : affiche toujours la page d'aperçu avant de rediriger
+	Rules           []targeting.Rule     // Optionnel : règles de redirection évaluées dans l'ordre avant l'URL longue
+	Variants        []experiment.Variant // Optionnel : destinations A/B pondérées, servies à la place de l'URL longue
+	QueryForwarding string               // Optionnel : transmission des paramètres du lien court (QueryForwardingMerge...)
+	UTM             UTMTemplate          // Optionnel : paramètres UTM ajoutés à la destination
+	RedirectStatus  int                  // Optionnel : 301, 302, 307 ou 308 (DefaultRedirectStatus si 0)
+	CacheControl    string               // Optionnel : en-tête Cache-Control de la redirection (ex: "no-store")
+	Kind            string               // Optionnel : models.LinkKindPrefix pour un lien préfixe, lien standard sinon
+	MaxViews        int                  // Optionnel : nombre de redirections autorisées (1 pour un lien à usage unique)
+	SelfDestruct    bool                 // Optionnel : efface la destination après la dernière redirection (avec MaxViews)
+	ActiveFrom      *time.Time           // Optionnel : le lien ne redirige qu'à partir de cet instant
+	ActiveUntil     *time.Time           // Optionnel : le lien ne redirige plus à partir de cet instant
+	InactiveURL     string               // Optionnel : destination servie hors de la fenêtre d'activité, page d'indisponibilité sinon
+	Title           string               // Optionnel : titre du lien, pour le retrouver
+	Description     string               // Optionnel : notes libres sur le lien
+	Tags            []string             // Optionnel : étiquettes libres (ex: "campagne-2024")
+	WatchContent    bool                 // Optionnel : surveille les changements du contenu de la destination
+	WatchSelector   string               // Optionnel : sélecteur CSS du contenu surveillé (ex: "main .fiche-produit"), active la surveillance
+}
+
+// BatchLinkResult est le résultat de la création d'un lien au sein d'un lot.
+// Err est renseignée si l'élément a été rejeté ; Link sinon.
+// Existing indique que Link est un lien existant retourné par la déduplication.
+type BatchLinkResult struct {
+	Index    int
+	Link     *models.Link
+	Existing bool
+	Err      error
+}
+
+// validateLongURL normalise une URL longue, vérifie qu'elle est acceptable selon le validateur configuré
+// puis la confronte aux listes de blocage. Elle retourne la forme canonique de l'URL, qui est celle enregistrée en base.
+func (s *LinkService) validateLongURL(longURL string) (string, error) {
+	canonicalURL, err := s.opts.URLValidator.Validate(longURL)
+	if err != nil {
+		return "", err
+	}
+	if err := s.opts.Blocklist.Check(canonicalURL); err != nil {
+		return "", err
+	}
+	return canonicalURL, nil
+}
+
+// validateAlias vérifie qu'un alias respecte le format des codes courts, n'est pas réservé
+// et ne contient pas de mot offensant.
+func (s *LinkService) validateAlias(alias string) error {
+	if !shortCodePattern.MatchString(alias) {
+		return fmt.Errorf("%w: %q must be 1 to 10 letters, digits, '-' or '_'", ErrInvalidAlias, alias)
+	}
+	if reservedShortCodes[alias] {
+		return fmt.Errorf("%w: %q is reserved", ErrInvalidAlias, alias)
+	}
+	if s.opts.ShortCodes.Filter.MatchAlias(alias) {
+		return fmt.Errorf("%w: %q contains an offensive word", ErrInvalidAlias, alias)
 	}
+	return nil
 }
 
-// TODO Créer la méthode GenerateShortCode
-// GenerateShortCode est une méthode rattachée à LinkService
-// Elle génère un code court aléatoire d'une longueur spécifiée. Elle prend une longueur en paramètre et retourne une string et une erreur
-// Il utilise le package 'crypto/rand' pour éviter la prévisibilité.
-// Je vous laisse chercher un peu :) C'est faisable en une petite dizaine de ligne
-func GenerateShortCode(length int) (string, error) {
-	code := make([]byte, length)
+// buildLink valide une demande de création et construit le lien correspondant, sans code court ni persistance.
+// L'URL longue doit avoir été validée par l'appelant.
+func (s *LinkService) buildLink(input CreateLinkInput) (*models.Link, error) {
+	passwordHash, err := hashLinkPassword(input.Password)
+	if err != nil {
+		return nil, err
+	}
+	rules, err := s.encodeRules(input.Rules)
+	if err != nil {
+		return nil, err
+	}
+	variants, err := s.encodeVariants(input.Variants)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateCampaign(input.QueryForwarding, input.UTM); err != nil {
+		return nil, err
+	}
+	redirectStatus, cacheControl, err := normalizeRedirect(input.RedirectStatus, input.CacheControl)
+	if err != nil {
+		return nil, err
+	}
+	kind, err := normalizeKind(input.Kind)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateViewLimit(input.MaxViews, input.SelfDestruct); err != nil {
+		return nil, err
+	}
+	if err := validateTitle(input.Title, input.Description); err != nil {
+		return nil, err
+	}
+	tags, err := normalizeTags(input.Tags)
+	if err != nil {
+		return nil, err
+	}
+	watchContent, watchSelector, err := normalizeWatch(input.WatchContent, input.WatchSelector)
+	if err != nil {
+		return nil, err
+	}
+	activeFrom, activeUntil, err := normalizeSchedule(input.ActiveFrom, input.ActiveUntil, input.InactiveURL)
+	if err != nil {
+		return nil, err
+	}
+	inactiveURL := input.InactiveURL
+	if inactiveURL != "" {
+		if inactiveURL, err = s.validateLongURL(inactiveURL); err != nil {
+			return nil, fmt.Errorf("inactive URL: %w", err)
+		}
+	}
 
-	for i := 0; i < length; i++ {
-		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(charset))))
+	var metadata string
+	if len(input.Metadata) > 0 {
+		encoded, err := json.Marshal(input.Metadata)
 		if err != nil {
-			return "", fmt.Errorf("failed to generate secure random index: %w", err)
+			return nil, fmt.Errorf("failed to encode metadata: %w", err)
 		}
-		code[i] = charset[n.Int64()]
+		metadata = string(encoded)
 	}
 
-	return string(code), nil
+	// TODO Crée une nouvelle instance du modèle Link.
+	return &models.Link{
+		LongURL:         input.LongURL,
+		DomainID:        input.DomainID,
+		Owner:           input.Owner,
+		URLHash:         HashURL(input.LongURL),
+		Metadata:        metadata,
+		PasswordHash:    passwordHash,
+		AlwaysPreview:   input.AlwaysPreview,
+		RedirectRules:   rules,
+		SplitVariants:   variants,
+		QueryForwarding: input.QueryForwarding,
+		UTMSource:       input.UTM.Source,
+		UTMMedium:       input.UTM.Medium,
+		UTMCampaign:     input.UTM.Campaign,
+		RedirectStatus:  redirectStatus,
+		CacheControl:    cacheControl,
+		Kind:            kind,
+		MaxViews:        input.MaxViews,
+		SelfDestruct:    input.SelfDestruct,
+		ActiveFrom:      activeFrom,
+		ActiveUntil:     activeUntil,
+		InactiveURL:     inactiveURL,
+		Title:           strings.TrimSpace(input.Title),
+		Description:     strings.TrimSpace(input.Description),
+		Tags:            tags,
+		WatchContent:    watchContent,
+		WatchSelector:   watchSelector,
+		CreatedAt:       time.Now(),
+	}, nil
+}
+
+// assignShortCode attribue à un lien construit par buildLink l'alias demandé, après l'avoir validé, ou un code
+// généré.
+// taken contient les codes du domaine déjà pris en dehors de la base (ex: plus haut dans un même lot).
+func (s *LinkService) assignShortCode(link *models.Link, alias string, taken map[string]bool) error {
+	if alias == "" {
+		shortCode, err := s.generateUniqueShortCode(link.DomainID, taken)
+		if err != nil {
+			return err
+		}
+		link.ShortCode = shortCode
+		return nil
+	}
+	if err := s.validateAlias(alias); err != nil {
+		return err
+	}
+	if taken[alias] {
+		return fmt.Errorf("%w: %q", ErrAliasTaken, alias)
+	}
+	// Les codes sont uniques par domaine, tous espaces de travail confondus
+	existing, err := s.linkRepo.ExistingShortCodes(link.DomainID, []string{alias})
+	if err != nil {
+		return fmt.Errorf("database error checking alias availability: %w", err)
+	}
+	if existing[alias] {
+		return fmt.Errorf("%w: %q", ErrAliasTaken, alias)
+	}
+	link.ShortCode = alias
+	return nil
 }
 
 // CreateLink crée un nouveau lien raccourci.
-// Il génère un code court unique, puis persiste le lien dans la base de données.
-func (s *LinkService) CreateLink(longURL string) (*models.Link, error) {
+// Il utilise l'alias fourni ou génère un code court unique, puis persiste le lien dans la base de données.
+// Si la déduplication s'applique ou si la clé d'idempotence a déjà servi, le lien existant est
+// retourné à la place : le booléen indique si un nouveau lien a réellement été créé.
+func (s *LinkService) CreateLink(input CreateLinkInput) (*models.Link, bool, error) {
+	canonicalURL, err := s.validateLongURL(input.LongURL)
+	if err != nil {
+		return nil, false, err
+	}
+	input.LongURL = canonicalURL
 
-	// TODO 1: Implémenter la logique de retry pour générer un code court unique.
-	// Essayez de générer un code, vérifiez s'il existe déjà en base, et retentez si une collision est trouvée.
-	// Limitez le nombre de tentatives pour éviter une boucle infinie.
+	if input.IdempotencyKey != "" {
+		return s.createIdempotent(input)
+	}
+	link, err := s.buildLink(input)
+	if err != nil {
+		return nil, false, err
+	}
+	if existing, err := s.findDuplicate(input, link); existing != nil || err != nil {
+		return existing, false, err
+	}
+	if err := s.assignShortCode(link, input.Alias, nil); err != nil {
+		return nil, false, err
+	}
 
-	// TODO Créer une variable shortcode pour stocker le shortcode créé
-	var shortCode string
+	// TODO Persiste le nouveau lien dans la base de données via le repository (CreateLink)
+	if err := s.linkRepo.CreateLink(link); err != nil {
+		return nil, false, s.linkQuotaError(err, "failed to save link")
+	}
+	s.queuePageInfo(link)
 
-	// TODO Définir un nombre maximum (5) de tentative pour trouver un code unique  (maxRetries)
-	maxRetries := 5
+	// TODO Retourne le lien créé
+	return link, true, nil
+}
 
-	for i := 0; i < maxRetries; i++ {
+// CreateLinks crée un lot de liens. Chaque élément est validé individuellement : les éléments
+// rejetés sont signalés dans leur résultat, et tous les autres sont créés dans une seule transaction.
+// Les éléments qui dépasseraient le quota de liens de l'espace de travail sont rejetés avec ErrLinkQuotaExceeded.
+// Une erreur n'est retournée que si cette transaction échoue, auquel cas aucun lien n'est créé.
+func (s *LinkService) CreateLinks(inputs []CreateLinkInput) ([]BatchLinkResult, error) {
+	results := make([]BatchLinkResult, len(inputs))
+	links := make([]*models.Link, 0, len(inputs))
+	// takenByDomain contient, pour chaque domaine, les codes attribués plus haut dans le lot
+	takenByDomain := make(map[uint]map[string]bool)
+	// pending associe domaine + propriétaire + empreinte d'URL et de redirection aux liens du lot, pour dédupliquer
+	// aussi à l'intérieur du lot
+	pending := make(map[string]*models.Link, len(inputs))
+	remaining, err := s.remainingLinks()
+	if err != nil {
+		return nil, err
+	}
 
-		// TODO : Génère un code de 6 caractères (GenerateShortCode)
-		code, err := GenerateShortCode(6)
+	for i, input := range inputs {
+		results[i].Index = i
+		canonicalURL, err := s.validateLongURL(input.LongURL)
 		if err != nil {
-			return nil, err
+			results[i].Err = err
+			continue
 		}
+		input.LongURL = canonicalURL
 
-		// TODO : Vérifie si le code généré existe déjà en base de données (GetLinkbyShortCode)
-		_, err = s.linkRepo.GetLinkByShortCode(code)
-
-		// On ignore la première valeur
+		link, err := s.buildLink(input)
 		if err != nil {
-			// Si l'erreur est 'record not found' de GORM, cela signifie que le code est unique.
-			if errors.Is(err, gorm.ErrRecordNotFound) {
-				shortCode = code // Le code est unique, on peut l'utiliser
-				break            // Sort de la boucle de retry
+			results[i].Err = err
+			continue
+		}
+		dedupKey := fmt.Sprintf("%d\n%s\n%s%s", input.DomainID, input.Owner, link.URLHash, redirectFingerprint(link))
+		if s.shouldDeduplicate(input, link) {
+			if existing := pending[dedupKey]; existing != nil {
+				results[i].Link, results[i].Existing = existing, true
+				continue
+			}
+			existing, err := s.findDuplicate(input, link)
+			if err != nil {
+				results[i].Err = err
+				continue
+			}
+			if existing != nil {
+				results[i].Link, results[i].Existing = existing, true
+				continue
 			}
-			// Si c'est une autre erreur de base de données, retourne l'erreur.
-			return nil, fmt.Errorf("database error checking short code uniqueness: %w", err)
 		}
 
-		// Si aucune erreur (le code a été trouvé), cela signifie une collision.
-		log.Printf("Short code '%s' already exists, retrying generation (%d/%d)...", code, i+1, maxRetries)
+		if remaining == 0 {
+			results[i].Err = fmt.Errorf("%w: workspace %q may hold %d link(s)", ErrLinkQuotaExceeded, s.workspace.Name, s.workspace.MaxLinks)
+			continue
+		}
+		taken := takenByDomain[input.DomainID]
+		if taken == nil {
+			taken = make(map[string]bool)
+			takenByDomain[input.DomainID] = taken
+		}
+		if err := s.assignShortCode(link, input.Alias, taken); err != nil {
+			results[i].Err = err
+			continue
+		}
+		taken[link.ShortCode] = true
+		remaining--
+		pending[dedupKey] = link
+		results[i].Link = link
+		links = append(links, link)
 	}
 
-	// TODO : Si après toutes les tentatives, aucun code unique n'a été trouvé... Errors.New
-	if shortCode == "" {
-		return nil, errors.New("could not generate a unique short code after several attempts")
+	if err := s.linkRepo.CreateLinks(links); err != nil {
+		return nil, s.linkQuotaError(err, "failed to save links")
 	}
+	s.queuePageInfo(links...)
+	return results, nil
+}
 
-	// TODO Crée une nouvelle instance du modèle Link.
-	link := &models.Link{
-		LongURL:   longURL,
-		ShortCode: shortCode,
-		CreatedAt: time.Now(),
-	}
+// generateUniqueShortCode génère un code court qui n'existe pas encore dans le domaine (tous espaces de travail
+// confondus) ni dans taken (peut être nil).
+// En cas de collision, la génération est retentée un nombre limité de fois ; chaque tentative alimente
+// le suivi du taux de collision, qui allonge les codes générés lorsque l'espace devient trop encombré.
+func (s *LinkService) generateUniqueShortCode(domainID uint, taken map[string]bool) (string, error) {
+	maxRetries := s.codes.MaxRetries()
 
-	// TODO Persiste le nouveau lien dans la base de données via le repository (CreateLink)
-	if err := s.linkRepo.CreateLink(link); err != nil {
-		return nil, fmt.Errorf("failed to save link: %w", err)
+	for i := 0; i < maxRetries; i++ {
+		code, err := s.codes.Generate()
+		if err != nil {
+			return "", err
+		}
+
+		// Vérifie si le code généré existe déjà en base de données
+		existing, err := s.linkRepo.ExistingShortCodes(domainID, []string{code})
+		if err != nil {
+			return "", fmt.Errorf("database error checking short code uniqueness: %w", err)
+		}
+		if !existing[code] && !taken[code] {
+			s.codes.Record(false)
+			return code, nil
+		}
+
+		// Sinon le code est déjà pris : c'est une collision.
+		s.codes.Record(true)
+		log.Printf("Short code '%s' already exists, retrying generation (%d/%d)...", code, i+1, maxRetries)
 	}
 
-	// TODO Retourne le lien créé
-	return link, nil
+	return "", errors.New("could not generate a unique short code after several attempts")
 }
 
-// GetLinkByShortCode récupère un lien via son code court.
+// BackfillURLHashes calcule l'empreinte de déduplication des liens qui n'en ont pas encore.
+func (s *LinkService) BackfillURLHashes() (int, error) {
+	return s.linkRepo.BackfillURLHashes(HashURL)
+}
+
+// GetLinkByShortCode récupère un lien via son domaine et son code court.
 // Il délègue l'opération de recherche au repository.
-func (s *LinkService) GetLinkByShortCode(shortCode string) (*models.Link, error) {
+func (s *LinkService) GetLinkByShortCode(domainID uint, shortCode string) (*models.Link, error) {
 	// TODO : Récupérer un lien par son code court en utilisant s.linkRepo.GetLinkByShortCode.
 	// Retourner le lien trouvé ou une erreur si non trouvé/problème DB.
-	return s.linkRepo.GetLinkByShortCode(shortCode)
+	return s.linkRepo.GetLinkByShortCode(domainID, shortCode)
 }
 
 // GetLinkStats récupère les statistiques pour un lien donné (nombre total de clics).
 // Il interagit avec le LinkRepository pour obtenir le lien, puis avec le ClickRepository
-func (s *LinkService) GetLinkStats(shortCode string) (*models.Link, int, error) {
+func (s *LinkService) GetLinkStats(domainID uint, shortCode string) (*models.Link, int, error) {
 
 	// TODO : Récupérer le lien par son shortCode
-	link, err := s.linkRepo.GetLinkByShortCode(shortCode)
+	link, err := s.linkRepo.GetLinkByShortCode(domainID, shortCode)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -136,6 +504,36 @@ func (s *LinkService) GetLinkStats(shortCode string) (*models.Link, int, error)
 		return nil, 0, err
 	}
 
+	// Les clics repris d'un autre raccourcisseur lors d'un import s'ajoutent aux clics enregistrés ici.
+	count += link.ImportedClicks
+
 	// TODO : on retourne les 3 valeurs
 	return link, count, nil
 }
+
+// ClickBreakdown répartit les clics d'un lien selon leurs attributs. Les clics sans valeur ne sont pas comptés.
+type ClickBreakdown struct {
+	BySource  map[string]int // Par marqueur de source (ex: scans de QR code)
+	ByRule    map[string]int // Par règle de redirection appliquée
+	ByCountry map[string]int // Par pays du visiteur (code ISO), si la géolocalisation est activée
+	ByVariant map[string]int // Par variante A/B servie
+}
+
+// GetClickBreakdown retourne la répartition des clics d'un lien.
+func (s *LinkService) GetClickBreakdown(linkID uint) (ClickBreakdown, error) {
+	var breakdown ClickBreakdown
+	var err error
+	if breakdown.BySource, err = s.linkRepo.CountClicksGroupedBy(linkID, "source"); err != nil {
+		return breakdown, err
+	}
+	if breakdown.ByRule, err = s.linkRepo.CountClicksGroupedBy(linkID, "rule"); err != nil {
+		return breakdown, err
+	}
+	if breakdown.ByCountry, err = s.linkRepo.CountClicksGroupedBy(linkID, "country"); err != nil {
+		return breakdown, err
+	}
+	if breakdown.ByVariant, err = s.linkRepo.CountClicksGroupedBy(linkID, "variant"); err != nil {
+		return breakdown, err
+	}
+	return breakdown, nil
+}
@@ -0,0 +1,96 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+	"github.com/axellelanca/urlshortener/internal/repository"
+)
+
+// ErrInvalidTag signale une étiquette refusée (caractères interdits, trop longue, trop d'étiquettes).
+var ErrInvalidTag = errors.New("invalid tag")
+
+// ErrInvalidTitle signale un titre ou une description trop longs.
+var ErrInvalidTitle = errors.New("invalid title or description")
+
+// Limites des informations descriptives d'un lien.
+const (
+	maxTitleLength       = 200
+	maxDescriptionLength = 2000
+	maxTagsPerLink       = 20
+)
+
+// tagPattern décrit une étiquette normalisée : lettres, chiffres, "-" et "_", 50 caractères au plus.
+var tagPattern = regexp.MustCompile(`^[\p{Ll}\p{Lo}\p{N}][\p{Ll}\p{Lo}\p{N}_-]{0,49}$`)
+
+// normalizeTags vérifie les étiquettes demandées pour un lien et retourne leur forme enregistrée :
+// en minuscules, sans espaces autour ni doublons, dans l'ordre d'origine.
+func normalizeTags(names []string) ([]models.Tag, error) {
+	var tags []models.Tag
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" || seen[name] {
+			continue
+		}
+		if !tagPattern.MatchString(name) {
+			return nil, fmt.Errorf("%w: %q must be 1 to 50 letters, digits, '-' or '_'", ErrInvalidTag, name)
+		}
+		seen[name] = true
+		tags = append(tags, models.Tag{Name: name})
+	}
+	if len(tags) > maxTagsPerLink {
+		return nil, fmt.Errorf("%w: a link can have at most %d tags", ErrInvalidTag, maxTagsPerLink)
+	}
+	return tags, nil
+}
+
+// validateTitle vérifie la longueur du titre et de la description d'un lien.
+func validateTitle(title, description string) error {
+	if utf8.RuneCountInString(title) > maxTitleLength {
+		return fmt.Errorf("%w: title must be at most %d characters", ErrInvalidTitle, maxTitleLength)
+	}
+	if utf8.RuneCountInString(description) > maxDescriptionLength {
+		return fmt.Errorf("%w: description must be at most %d characters", ErrInvalidTitle, maxDescriptionLength)
+	}
+	return nil
+}
+
+// TagNames retourne le nom des étiquettes chargées d'un lien (jamais nil, pour un tableau JSON vide).
+func TagNames(link *models.Link) []string {
+	names := make([]string, 0, len(link.Tags))
+	for _, tag := range link.Tags {
+		names = append(names, tag.Name)
+	}
+	return names
+}
+
+// LoadTags charge les étiquettes des liens fournis.
+func (s *LinkService) LoadTags(links ...*models.Link) error {
+	if err := s.linkRepo.LoadLinkTags(links...); err != nil {
+		return fmt.Errorf("failed to load link tags: %w", err)
+	}
+	return nil
+}
+
+// SearchLinks retourne une page des liens dont le titre, la description, l'URL, le code court ou les étiquettes
+// contiennent tous les mots recherchés (en préfixe), les plus pertinents d'abord, ainsi que le nombre total
+// de résultats. Le filtre peut restreindre la recherche (propriétaire, étiquette, fenêtre d'activité).
+func (s *LinkService) SearchLinks(query string, filter repository.LinkFilter, offset, limit int) ([]models.Link, int64, error) {
+	filter.Search = strings.TrimSpace(query)
+	return s.ListLinks(filter, offset, limit)
+}
+
+// EnsureSearchIndex crée l'index de recherche plein texte des liens s'il n'existe pas encore
+// et indique s'il vient d'être créé.
+func (s *LinkService) EnsureSearchIndex() (bool, error) {
+	created, err := s.linkRepo.EnsureSearchIndex()
+	if err != nil {
+		return false, fmt.Errorf("failed to create search index: %w", err)
+	}
+	return created, nil
+}
@@ -0,0 +1,66 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/experiment"
+	"github.com/axellelanca/urlshortener/internal/models"
+)
+
+// encodeVariants vérifie la répartition A/B d'un lien, valide et normalise les URLs de ses variantes
+// comme l'URL longue, puis l'encode pour son stockage.
+func (s *LinkService) encodeVariants(variants []experiment.Variant) (string, error) {
+	normalized, err := experiment.Normalize(variants)
+	if err != nil {
+		return "", err
+	}
+	for i := range normalized {
+		target, err := s.validateLongURL(normalized[i].Target)
+		if err != nil {
+			return "", fmt.Errorf("variant %s target: %w", normalized[i].Name, err)
+		}
+		normalized[i].Target = target
+	}
+	return experiment.Encode(normalized)
+}
+
+// VariantTTL retourne la durée pendant laquelle un visiteur garde la variante A/B qui lui a été attribuée.
+func (s *LinkService) VariantTTL() time.Duration {
+	return s.opts.VariantTTL
+}
+
+// ChooseVariant retourne la variante A/B à servir à un visiteur : celle qui lui a déjà été attribuée (assigned,
+// lue dans son cookie) si elle existe toujours, sinon une variante tirée selon les poids d'après visitorKey
+// (ex: adresse IP et User-Agent), de sorte qu'un visiteur sans cookie retombe aussi sur la même variante.
+// false est retourné si le lien n'a pas de répartition.
+func (s *LinkService) ChooseVariant(link *models.Link, assigned, visitorKey string) (experiment.Variant, bool) {
+	variants, err := experiment.Decode(link.SplitVariants)
+	if err != nil {
+		log.Printf("Unreadable split variants for link %s, falling back to the long URL: %v", link.ShortCode, err)
+		return experiment.Variant{}, false
+	}
+	if len(variants) == 0 {
+		return experiment.Variant{}, false
+	}
+	if index, ok := experiment.Find(variants, assigned); ok && assigned != "" {
+		return variants[index], true
+	}
+	return variants[experiment.Pick(variants, fmt.Sprintf("%d|%s", link.ID, visitorKey))], true
+}
+
+// VariantReport retourne le rapport de la répartition A/B d'un lien à partir de ses clics par variante,
+// ou nil si le lien n'a pas de répartition.
+func (s *LinkService) VariantReport(link *models.Link, clicksByVariant map[string]int) *experiment.Report {
+	variants, err := experiment.Decode(link.SplitVariants)
+	if err != nil {
+		log.Printf("Unreadable split variants for link %s: %v", link.ShortCode, err)
+		return nil
+	}
+	if len(variants) == 0 {
+		return nil
+	}
+	report := experiment.Analyze(variants, clicksByVariant)
+	return &report
+}
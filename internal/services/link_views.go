@@ -0,0 +1,56 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+)
+
+// ErrInvalidViewLimit signale une limite de redirections invalide (négative, ou effacement sans limite).
+var ErrInvalidViewLimit = errors.New("invalid view limit")
+
+// validateViewLimit vérifie la limite de redirections demandée pour un lien à usage limité.
+func validateViewLimit(maxViews int, selfDestruct bool) error {
+	if maxViews < 0 {
+		return fmt.Errorf("%w: max views must not be negative", ErrInvalidViewLimit)
+	}
+	if selfDestruct && maxViews == 0 {
+		return fmt.Errorf("%w: erasing the destination needs a max views limit", ErrInvalidViewLimit)
+	}
+	return nil
+}
+
+// ViewLimited indique si un lien est à usage limité (ex: lien à usage unique).
+func ViewLimited(link *models.Link) bool {
+	return link.MaxViews > 0
+}
+
+// RemainingViews retourne le nombre de redirections qu'il reste à un lien à usage limité.
+func RemainingViews(link *models.Link) int {
+	if link.Views >= link.MaxViews {
+		return 0
+	}
+	return link.MaxViews - link.Views
+}
+
+// ViewsExhausted indique qu'un lien à usage limité a consommé toutes ses redirections.
+// Le lien peut avoir été lu avant une consommation concurrente : seul ConsumeView fait foi.
+func ViewsExhausted(link *models.Link) bool {
+	return ViewLimited(link) && RemainingViews(link) == 0
+}
+
+// ConsumeView consomme l'une des redirections d'un lien à usage limité avant de rediriger le visiteur.
+// La consommation est atomique en base, de sorte qu'un lien à usage unique ne redirige qu'une seule fois,
+// même sous des requêtes concurrentes traitées par plusieurs instances. false est retourné si toutes les
+// redirections ont déjà été consommées.
+func (s *LinkService) ConsumeView(link *models.Link) (bool, error) {
+	consumed, err := s.linkRepo.ConsumeView(link.ID, link.SelfDestruct)
+	if err != nil {
+		return false, fmt.Errorf("failed to consume link view: %w", err)
+	}
+	if consumed {
+		link.Views++
+	}
+	return consumed, nil
+}
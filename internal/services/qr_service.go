@@ -0,0 +1,136 @@
+package services
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"regexp"
+
+	"github.com/axellelanca/urlshortener/internal/config"
+	"github.com/axellelanca/urlshortener/internal/models"
+	"github.com/axellelanca/urlshortener/internal/qr"
+)
+
+// ErrInvalidQROptions est retournée pour des options de QR code invalides (format, taille, couleur, source...).
+var ErrInvalidQROptions = qr.ErrInvalidOptions
+
+// clickSourcePattern définit les marqueurs de source acceptés (ex: "qr", "affiche-paris").
+var clickSourcePattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,50}$`)
+
+// NormalizeClickSource retourne le marqueur de source d'un clic s'il est valide, une chaîne vide sinon.
+func NormalizeClickSource(source string) string {
+	if clickSourcePattern.MatchString(source) {
+		return source
+	}
+	return ""
+}
+
+// QRServiceOptions regroupe les réglages par défaut des QR codes.
+type QRServiceOptions struct {
+	Defaults    qr.Options // Rendu par défaut (taille, marge, correction d'erreur, couleurs)
+	LogoFile    string     // Logo centré ajouté sur demande ; vide si aucun logo n'est configuré
+	SourceParam string     // Paramètre de requête portant le marqueur de source (ex: "src") ; vide pour ne pas en ajouter
+	SourceValue string     // Marqueur ajouté par défaut à l'URL encodée (ex: "qr")
+}
+
+// QRServiceOptionsFromConfig construit les options du QRService à partir de la configuration chargée.
+func QRServiceOptionsFromConfig(cfg *config.Config) (QRServiceOptions, error) {
+	foreground, err := qr.ParseColor(cfg.QR.Foreground)
+	if err != nil {
+		return QRServiceOptions{}, err
+	}
+	background, err := qr.ParseColor(cfg.QR.Background)
+	if err != nil {
+		return QRServiceOptions{}, err
+	}
+	if cfg.QR.SourceValue != "" && NormalizeClickSource(cfg.QR.SourceValue) == "" {
+		return QRServiceOptions{}, fmt.Errorf("%w: source value %q must be 1 to 50 letters, digits, '.', '-' or '_'", ErrInvalidQROptions, cfg.QR.SourceValue)
+	}
+	return QRServiceOptions{
+		Defaults: qr.Options{
+			Size:       cfg.QR.Size,
+			ECC:        cfg.QR.ECC,
+			Margin:     cfg.QR.Margin,
+			Foreground: foreground,
+			Background: background,
+		},
+		LogoFile:    cfg.QR.LogoFile,
+		SourceParam: cfg.QR.SourceParam,
+		SourceValue: cfg.QR.SourceValue,
+	}, nil
+}
+
+// QRService produit les QR codes des liens courts.
+type QRService struct {
+	domainService *DomainService
+	opts          QRServiceOptions
+}
+
+// NewQRService crée et retourne une nouvelle instance de QRService.
+func NewQRService(domainService *DomainService, opts QRServiceOptions) *QRService {
+	return &QRService{
+		domainService: domainService,
+		opts:          opts,
+	}
+}
+
+// QRRequest décrit un QR code à produire. Les options de rendu nulles prennent les valeurs configurées.
+type QRRequest struct {
+	Render  qr.Options
+	UseLogo bool   // Ajoute le logo configuré (ignoré si Render.Logo est déjà fourni)
+	Source  string // Marqueur de source ; vide pour la valeur configurée
+}
+
+// Content retourne l'URL encodée dans le QR code : l'URL courte complète du lien,
+// suivie du marqueur de source qui permet d'attribuer les scans.
+func (s *QRService) Content(link *models.Link, source string) (string, error) {
+	shortURL, err := s.domainService.ShortURL(link)
+	if err != nil {
+		return "", err
+	}
+	if source == "" {
+		source = s.opts.SourceValue
+	}
+	if source == "" || s.opts.SourceParam == "" {
+		return shortURL, nil
+	}
+	if NormalizeClickSource(source) == "" {
+		return "", fmt.Errorf("%w: source %q must be 1 to 50 letters, digits, '.', '-' or '_'", ErrInvalidQROptions, source)
+	}
+	return shortURL + "?" + url.Values{s.opts.SourceParam: {source}}.Encode(), nil
+}
+
+// Render écrit dans w le QR code d'un lien (PNG si aucun format n'est demandé).
+func (s *QRService) Render(w io.Writer, link *models.Link, req QRRequest) error {
+	content, err := s.Content(link, req.Source)
+	if err != nil {
+		return err
+	}
+
+	opts := req.Render
+	if opts.Size == 0 {
+		opts.Size = s.opts.Defaults.Size
+	}
+	if opts.ECC == "" {
+		opts.ECC = s.opts.Defaults.ECC
+	}
+	if opts.Margin == 0 {
+		opts.Margin = s.opts.Defaults.Margin
+	}
+	if opts.Foreground == nil {
+		opts.Foreground = s.opts.Defaults.Foreground
+	}
+	if opts.Background == nil {
+		opts.Background = s.opts.Defaults.Background
+	}
+	if req.UseLogo && opts.Logo == nil {
+		if s.opts.LogoFile == "" {
+			return fmt.Errorf("%w: no logo is configured (qr.logo_file)", ErrInvalidQROptions)
+		}
+		if opts.Logo, err = qr.LoadLogo(s.opts.LogoFile); err != nil {
+			return err
+		}
+	}
+
+	return qr.Encode(w, content, opts)
+}
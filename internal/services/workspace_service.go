@@ -0,0 +1,236 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+	"github.com/axellelanca/urlshortener/internal/repository"
+)
+
+// Erreurs métier liées aux espaces de travail.
+var (
+	ErrUnknownWorkspace = errors.New("unknown workspace")
+	ErrInvalidWorkspace = errors.New("invalid workspace")
+	ErrWorkspaceExists  = errors.New("workspace already exists")
+	ErrInvalidAPIKey    = errors.New("invalid API key")
+	// ErrLinkNotMovable signale un lien qui ne peut pas changer d'espace de travail (ex: lien d'un domaine personnalisé).
+	ErrLinkNotMovable = errors.New("link cannot be moved")
+)
+
+// workspaceNamePattern est le format des noms d'espaces de travail : minuscules, chiffres, '-' et '_'.
+var workspaceNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,49}$`)
+
+// apiKeyPrefix précède les clés d'API générées, pour qu'elles soient reconnaissables (ex: dans un dépôt de code).
+const apiKeyPrefix = "usk_"
+
+// WorkspaceQuotas regroupe les quotas d'un espace de travail ; 0 signifie illimité.
+type WorkspaceQuotas struct {
+	MaxLinks          int
+	MaxClicksPerMonth int
+}
+
+// WorkspaceService gère les espaces de travail, leurs clés d'API et le déplacement de liens entre eux.
+type WorkspaceService struct {
+	workspaceRepo repository.WorkspaceRepository
+	linkRepo      repository.LinkRepository
+}
+
+// NewWorkspaceService crée et retourne une nouvelle instance de WorkspaceService.
+func NewWorkspaceService(workspaceRepo repository.WorkspaceRepository, linkRepo repository.LinkRepository) *WorkspaceService {
+	return &WorkspaceService{
+		workspaceRepo: workspaceRepo,
+		linkRepo:      linkRepo,
+	}
+}
+
+// validateQuotas vérifie que des quotas ne sont pas négatifs.
+func validateQuotas(quotas WorkspaceQuotas) error {
+	if quotas.MaxLinks < 0 || quotas.MaxClicksPerMonth < 0 {
+		return fmt.Errorf("%w: quotas must be positive (0 for unlimited)", ErrInvalidWorkspace)
+	}
+	return nil
+}
+
+// CreateWorkspace enregistre un nouvel espace de travail.
+func (s *WorkspaceService) CreateWorkspace(name string, quotas WorkspaceQuotas) (*models.Workspace, error) {
+	if !workspaceNamePattern.MatchString(name) {
+		return nil, fmt.Errorf("%w: %q must be 1 to 50 lowercase letters, digits, '-' or '_'", ErrInvalidWorkspace, name)
+	}
+	if name == models.DefaultWorkspaceName {
+		return nil, fmt.Errorf("%w: %q is reserved", ErrInvalidWorkspace, name)
+	}
+	if err := validateQuotas(quotas); err != nil {
+		return nil, err
+	}
+
+	_, err := s.workspaceRepo.GetWorkspaceByName(name)
+	if err == nil {
+		return nil, fmt.Errorf("%w: %q", ErrWorkspaceExists, name)
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("database error checking workspace: %w", err)
+	}
+
+	workspace := &models.Workspace{
+		Name:              name,
+		MaxLinks:          quotas.MaxLinks,
+		MaxClicksPerMonth: quotas.MaxClicksPerMonth,
+	}
+	if err := s.workspaceRepo.CreateWorkspace(workspace); err != nil {
+		return nil, fmt.Errorf("failed to save workspace: %w", err)
+	}
+	return workspace, nil
+}
+
+// ListWorkspaces retourne tous les espaces de travail, hors espace par défaut.
+func (s *WorkspaceService) ListWorkspaces() ([]models.Workspace, error) {
+	return s.workspaceRepo.GetAllWorkspaces()
+}
+
+// GetWorkspace retourne un espace de travail par son nom. Un nom vide ou DefaultWorkspaceName désigne
+// l'espace par défaut ; un espace inconnu renvoie ErrUnknownWorkspace.
+func (s *WorkspaceService) GetWorkspace(name string) (*models.Workspace, error) {
+	if name == "" || name == models.DefaultWorkspaceName {
+		return models.DefaultWorkspace(), nil
+	}
+	workspace, err := s.workspaceRepo.GetWorkspaceByName(name)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: %q", ErrUnknownWorkspace, name)
+		}
+		return nil, fmt.Errorf("database error looking up workspace: %w", err)
+	}
+	return workspace, nil
+}
+
+// SetQuotas remplace les quotas d'un espace de travail. L'espace par défaut n'a pas de quota.
+func (s *WorkspaceService) SetQuotas(workspace *models.Workspace, quotas WorkspaceQuotas) error {
+	if workspace.ID == models.DefaultWorkspaceID {
+		return fmt.Errorf("%w: the default workspace has no quotas", ErrInvalidWorkspace)
+	}
+	if err := validateQuotas(quotas); err != nil {
+		return err
+	}
+	workspace.MaxLinks = quotas.MaxLinks
+	workspace.MaxClicksPerMonth = quotas.MaxClicksPerMonth
+	if err := s.workspaceRepo.UpdateWorkspaceQuotas(workspace); err != nil {
+		return fmt.Errorf("failed to save quotas: %w", err)
+	}
+	return nil
+}
+
+// hashAPIKey retourne l'empreinte SHA-256 (hexadécimale) d'une clé d'API, seule forme enregistrée en base.
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// CreateAPIKey génère une nouvelle clé d'API pour un espace de travail et la retourne en clair :
+// elle ne pourra plus être affichée ensuite.
+func (s *WorkspaceService) CreateAPIKey(workspace *models.Workspace, name string) (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("failed to generate API key: %w", err)
+	}
+	key := apiKeyPrefix + hex.EncodeToString(secret)
+	record := &models.APIKey{
+		WorkspaceID: workspace.ID,
+		Name:        strings.TrimSpace(name),
+		KeyHash:     hashAPIKey(key),
+	}
+	if err := s.workspaceRepo.CreateAPIKey(record); err != nil {
+		return "", fmt.Errorf("failed to save API key: %w", err)
+	}
+	return key, nil
+}
+
+// WorkspaceForAPIKey retourne l'espace de travail auquel une clé d'API donne accès.
+// Une clé inconnue renvoie ErrInvalidAPIKey.
+func (s *WorkspaceService) WorkspaceForAPIKey(key string) (*models.Workspace, error) {
+	if !strings.HasPrefix(key, apiKeyPrefix) {
+		return nil, ErrInvalidAPIKey
+	}
+	record, err := s.workspaceRepo.GetAPIKeyByHash(hashAPIKey(key))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidAPIKey
+		}
+		return nil, fmt.Errorf("database error looking up API key: %w", err)
+	}
+	if record.WorkspaceID == models.DefaultWorkspaceID {
+		return models.DefaultWorkspace(), nil
+	}
+	workspace, err := s.workspaceRepo.GetWorkspaceByID(record.WorkspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load workspace %d: %w", record.WorkspaceID, err)
+	}
+	return workspace, nil
+}
+
+// MoveLinksInput décrit les liens à déplacer d'un espace de travail à un autre.
+type MoveLinksInput struct {
+	From       *models.Workspace
+	To         *models.Workspace
+	ShortCodes []string // Codes courts des liens à déplacer, dans le domaine par défaut ; ignorés avec All
+	All        bool     // Déplace tous les liens de l'espace d'origine
+}
+
+// MoveLinks déplace des liens, avec leurs clics, vers un autre espace de travail, et retourne le nombre de liens
+// déplacés. Le quota de liens de l'espace de destination est respecté. Les liens d'un domaine personnalisé
+// restent attachés à l'espace de travail de ce domaine : ils ne peuvent pas être déplacés.
+// Soit tous les liens demandés sont déplacés, soit aucun.
+func (s *WorkspaceService) MoveLinks(input MoveLinksInput) (int, error) {
+	if input.From.ID == input.To.ID {
+		return 0, fmt.Errorf("%w: links are already in workspace %q", ErrInvalidWorkspace, input.To.Name)
+	}
+
+	source := s.linkRepo.ForWorkspace(input.From.ID)
+	var ids []uint
+	customDomain := 0
+	collect := func(link *models.Link) error {
+		if link.DomainID != models.DefaultDomainID {
+			customDomain++
+			return nil
+		}
+		ids = append(ids, link.ID)
+		return nil
+	}
+	if input.All {
+		if err := source.StreamLinks(repository.LinkFilter{}, collect); err != nil {
+			return 0, fmt.Errorf("failed to list links: %w", err)
+		}
+	} else {
+		for _, code := range input.ShortCodes {
+			link, err := source.GetLinkByShortCode(models.DefaultDomainID, code)
+			if err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return 0, fmt.Errorf("%w: no link %q in workspace %q", ErrLinkNotMovable, code, input.From.Name)
+				}
+				return 0, fmt.Errorf("database error looking up link %q: %w", code, err)
+			}
+			ids = append(ids, link.ID)
+		}
+	}
+	if customDomain > 0 {
+		return 0, fmt.Errorf("%w: %d link(s) use a custom domain of workspace %q", ErrLinkNotMovable, customDomain, input.From.Name)
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	if err := s.workspaceRepo.MoveLinks(ids, input.To.ID, input.To.MaxLinks); err != nil {
+		if errors.Is(err, repository.ErrLinkLimitReached) {
+			return 0, fmt.Errorf("%w: workspace %q may hold %d link(s)", ErrLinkQuotaExceeded, input.To.Name, input.To.MaxLinks)
+		}
+		return 0, fmt.Errorf("failed to move links: %w", err)
+	}
+	return len(ids), nil
+}
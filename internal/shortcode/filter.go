@@ -0,0 +1,141 @@
+package shortcode
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// builtinBlockedWords est la liste intégrée des mots à ne jamais voir apparaître dans un code court.
+// Elle peut être complétée par un fichier (short_codes.blocked_words_file).
+var builtinBlockedWords = []string{
+	// Anglais
+	"anal", "anus", "arse", "ass", "bastard", "bitch", "boob", "cock", "cum", "cunt", "dick", "dildo",
+	"fag", "faggot", "fuck", "hitler", "jizz", "kkk", "nazi", "nigga", "nigger", "penis", "piss", "porn",
+	"pussy", "rape", "retard", "sex", "shit", "slut", "tits", "twat", "vagina", "wank", "whore",
+	// Français
+	"batard", "bite", "branle", "chier", "con", "conne", "connard", "couille", "encule", "enfoire",
+	"gouine", "merde", "nique", "pd", "pede", "pute", "putain", "salope", "teub",
+}
+
+// shortWordLength est la longueur en dessous de laquelle un mot n'est recherché dans un alias que s'il
+// le constitue entièrement : "con" ou "ass" apparaissent dans trop de mots anodins ("contact", "classic").
+const shortWordLength = 4
+
+// leetReplacements ramène les substitutions courantes du leetspeak à la lettre d'origine.
+// Le chiffre 1 peut remplacer un "i" comme un "l" : les deux lectures sont vérifiées.
+var leetReplacements = map[rune]rune{
+	'0': 'o', '3': 'e', '4': 'a', '5': 's', '6': 'g', '7': 't', '8': 'b', '9': 'g', '@': 'a', '$': 's',
+}
+
+// WordFilter détecte les mots offensants dans les codes courts, y compris écrits en leetspeak
+// ("sh1t"), séparés par des tirets ("f-u-c-k") ou avec des lettres répétées ("fuuuck").
+type WordFilter struct {
+	words []string
+}
+
+// NewWordFilter crée un filtre à partir de la liste intégrée, complétée par les mots du fichier path
+// (un mot par ligne, lignes vides et commentaires # ignorés). path peut être vide.
+func NewWordFilter(path string) (*WordFilter, error) {
+	words := append([]string{}, builtinBlockedWords...)
+	if path != "" {
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open blocked words file: %w", err)
+		}
+		defer file.Close()
+
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			line := scanner.Text()
+			if i := strings.Index(line, "#"); i >= 0 {
+				line = line[:i]
+			}
+			if line = strings.TrimSpace(line); line != "" {
+				words = append(words, line)
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("failed to read blocked words file: %w", err)
+		}
+	}
+
+	f := &WordFilter{}
+	for _, word := range words {
+		if normalized := normalizeWord(word, 'i'); normalized != "" {
+			f.words = append(f.words, normalized)
+		}
+	}
+	return f, nil
+}
+
+// Match indique si un code généré contient un mot bloqué, quelle que soit sa longueur.
+// Il est volontairement strict : un code généré refusé est simplement régénéré.
+func (f *WordFilter) Match(code string) bool {
+	return f.match(code, true)
+}
+
+// MatchAlias indique si un alias choisi par un utilisateur contient un mot bloqué.
+// Les mots courts ne sont refusés que s'ils constituent tout l'alias, pour ne pas rejeter des alias légitimes.
+func (f *WordFilter) MatchAlias(alias string) bool {
+	return f.match(alias, false)
+}
+
+// match compare les lectures normalisées du code aux mots bloqués. La version du code sans lettres
+// répétées n'est comparée qu'aux mots longs, eux aussi débarrassés de leurs répétitions.
+func (f *WordFilter) match(code string, strict bool) bool {
+	if f == nil {
+		return false
+	}
+	for _, one := range []rune{'i', 'l'} {
+		normalized := normalizeWord(code, one)
+		collapsed := collapseRepeats(normalized)
+		for _, word := range f.words {
+			if containsWord(normalized, word, strict) {
+				return true
+			}
+			if collapsedWord := collapseRepeats(word); len(collapsedWord) >= shortWordLength && containsWord(collapsed, collapsedWord, strict) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// containsWord indique si code contient word ; hors mode strict, un mot court doit constituer tout le code.
+func containsWord(code, word string, strict bool) bool {
+	if strict || len(word) >= shortWordLength {
+		return strings.Contains(code, word)
+	}
+	return code == word
+}
+
+// normalizeWord passe un mot en minuscules, remplace le leetspeak (1, ! et | deviennent one) et retire les séparateurs.
+func normalizeWord(word string, one rune) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(word) {
+		if replacement, ok := leetReplacements[r]; ok {
+			r = replacement
+		} else if r == '1' || r == '!' || r == '|' {
+			r = one
+		}
+		if r >= 'a' && r <= 'z' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// collapseRepeats réduit les suites d'une même lettre à une seule occurrence ("fuuuck" devient "fuck").
+func collapseRepeats(word string) string {
+	var b strings.Builder
+	var previous rune
+	for _, r := range word {
+		if r != previous {
+			b.WriteRune(r)
+		}
+		previous = r
+	}
+	return b.String()
+}
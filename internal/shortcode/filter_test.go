@@ -0,0 +1,92 @@
+package shortcode
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func newTestFilter(t *testing.T) *WordFilter {
+	t.Helper()
+	filter, err := NewWordFilter("")
+	if err != nil {
+		t.Fatalf("NewWordFilter : %v", err)
+	}
+	return filter
+}
+
+func TestWordFilterMatch(t *testing.T) {
+	filter := newTestFilter(t)
+	blocked := []string{
+		"shit",
+		"xShItx",
+		"sh1t",    // leetspeak
+		"5h!t",    // leetspeak, "!" lu comme un "i"
+		"f-u-c-k", // séparateurs
+		"f_u_c_k",
+		"fuuuck", // lettres répétées
+		"merde42",
+		"4ss",      // mot court : bloqué partout dans un code généré
+		"contact",  // idem, le filtre des codes générés est volontairement strict
+		"bul1shit", // "1" lu comme un "l"
+	}
+	for _, code := range blocked {
+		if !filter.Match(code) {
+			t.Errorf("Match(%q) = false, attendu true", code)
+		}
+	}
+
+	allowed := []string{"aZ3kq9", "bikoda", "tralumi", "x7Yb2P"}
+	for _, code := range allowed {
+		if filter.Match(code) {
+			t.Errorf("Match(%q) = true, attendu false", code)
+		}
+	}
+}
+
+func TestWordFilterMatchAlias(t *testing.T) {
+	filter := newTestFilter(t)
+	for _, alias := range []string{"con", "C0N", "ass", "sh1t-happens", "promo-fuuuck"} {
+		if !filter.MatchAlias(alias) {
+			t.Errorf("MatchAlias(%q) = false, attendu true", alias)
+		}
+	}
+	// Les mots courts ne sont refusés dans un alias que s'ils le constituent entièrement.
+	for _, alias := range []string{"contact", "classic", "passage", "soldes-2024"} {
+		if filter.MatchAlias(alias) {
+			t.Errorf("MatchAlias(%q) = true, attendu false", alias)
+		}
+	}
+}
+
+func TestWordFilterFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "blocked.txt")
+	content := "# Concurrents\nacme\n\n  globex  # marque déposée\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	filter, err := NewWordFilter(path)
+	if err != nil {
+		t.Fatalf("NewWordFilter : %v", err)
+	}
+	for _, alias := range []string{"acme-promo", "GL0BEX", "shit"} {
+		if !filter.MatchAlias(alias) {
+			t.Errorf("MatchAlias(%q) = false, attendu true", alias)
+		}
+	}
+	if filter.MatchAlias("concurrents") {
+		t.Error("les commentaires du fichier ne doivent pas être lus comme des mots")
+	}
+
+	if _, err := NewWordFilter(filepath.Join(t.TempDir(), "absent.txt")); err == nil {
+		t.Error("NewWordFilter avec un fichier absent : une erreur était attendue")
+	}
+}
+
+func TestNilWordFilter(t *testing.T) {
+	var filter *WordFilter
+	if filter.Match("shit") || filter.MatchAlias("shit") {
+		t.Error("un filtre nil ne doit rien bloquer")
+	}
+}
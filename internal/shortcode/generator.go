@@ -0,0 +1,206 @@
+package shortcode
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+)
+
+// Stratégies de génération des codes courts.
+const (
+	StrategyRandom        = "random"        // Caractères tirés au hasard dans l'alphabet
+	StrategySequential    = "sequential"    // Compteur persistant encodé dans l'alphabet : aucune collision possible
+	StrategyPronounceable = "pronounceable" // Mots inventés faits de syllabes (ex: "bikoda", "tralumi"), faciles à dicter
+)
+
+// Valeurs par défaut des options de génération.
+const (
+	DefaultAlphabet        = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+	DefaultLength          = 6
+	DefaultMaxRetries      = 5
+	DefaultGrowthThreshold = 0.1
+	// MaxLength est la longueur maximale d'un code court, imposée par la colonne short_code.
+	MaxLength = 10
+)
+
+// maxFilteredAttempts est le nombre de codes successifs pouvant être écartés par le filtre de mots
+// avant d'abandonner (un alphabet très restreint peut ne produire que des codes refusés).
+const maxFilteredAttempts = 100
+
+// collisionWindow est le nombre de tentatives récentes prises en compte pour calculer le taux de collision.
+const collisionWindow = 50
+
+// ErrExhausted est retournée lorsque plus aucun code n'est disponible dans la longueur maximale.
+var ErrExhausted = errors.New("short code space exhausted")
+
+// Generator produit des codes courts candidats. L'unicité est vérifiée par l'appelant.
+type Generator interface {
+	Generate() (string, error)
+}
+
+// growable est implémentée par les générateurs dont la longueur peut augmenter
+// lorsque l'espace des codes devient trop encombré.
+type growable interface {
+	// Grow allonge d'un caractère les codes produits ; false si maxLength est déjà atteinte.
+	Grow(maxLength int) bool
+}
+
+// Counter retourne la prochaine valeur d'un compteur persistant, partagé par tous les processus.
+type Counter func() (uint64, error)
+
+// Options configure la génération des codes courts. Les valeurs nulles sont remplacées par les valeurs par défaut.
+type Options struct {
+	Strategy        string
+	Length          int         // Longueur initiale des codes
+	Alphabet        string      // Caractères utilisés par les stratégies random et sequential
+	MaxLength       int         // Longueur au-delà de laquelle les codes ne sont plus allongés
+	MaxRetries      int         // Nombre de tentatives en cas de collision
+	GrowthThreshold float64     // Taux de collision (entre 0 et 1) au-delà duquel la longueur augmente
+	Filter          *WordFilter // Mots interdits dans les codes générés ; nil pour ne rien filtrer
+}
+
+// withDefaults retourne les options complétées par les valeurs par défaut.
+func (o Options) withDefaults() Options {
+	if o.Strategy == "" {
+		o.Strategy = StrategyRandom
+	}
+	if o.Length <= 0 {
+		o.Length = DefaultLength
+	}
+	if o.Alphabet == "" {
+		o.Alphabet = DefaultAlphabet
+	}
+	if o.MaxLength <= 0 {
+		o.MaxLength = MaxLength
+	}
+	if o.MaxRetries <= 0 {
+		o.MaxRetries = DefaultMaxRetries
+	}
+	if o.GrowthThreshold <= 0 {
+		o.GrowthThreshold = DefaultGrowthThreshold
+	}
+	return o
+}
+
+// Validate vérifie la cohérence des options, une fois complétées par les valeurs par défaut.
+func (o Options) Validate() error {
+	o = o.withDefaults()
+	switch o.Strategy {
+	case StrategyRandom, StrategySequential, StrategyPronounceable:
+	default:
+		return fmt.Errorf("unknown strategy %q (expected %s, %s or %s)", o.Strategy, StrategyRandom, StrategySequential, StrategyPronounceable)
+	}
+	if o.MaxLength > MaxLength {
+		return fmt.Errorf("max length %d exceeds the limit of %d characters", o.MaxLength, MaxLength)
+	}
+	if o.Length > o.MaxLength {
+		return fmt.Errorf("length %d exceeds max length %d", o.Length, o.MaxLength)
+	}
+	if o.GrowthThreshold > 1 {
+		return fmt.Errorf("growth threshold %v must be between 0 and 1", o.GrowthThreshold)
+	}
+
+	seen := make(map[rune]bool, len(o.Alphabet))
+	for _, r := range o.Alphabet {
+		isValid := (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '-' || r == '_'
+		if !isValid {
+			return fmt.Errorf("alphabet character %q is not allowed in short codes", r)
+		}
+		if seen[r] {
+			return fmt.Errorf("alphabet character %q is repeated", r)
+		}
+		seen[r] = true
+	}
+	if len(seen) < 2 {
+		return errors.New("alphabet must contain at least 2 characters")
+	}
+	return nil
+}
+
+// Adaptive enveloppe un Generator et suit le taux de collision des dernières tentatives :
+// lorsqu'il dépasse le seuil configuré, la longueur des codes est augmentée.
+type Adaptive struct {
+	generator  Generator
+	filter     *WordFilter
+	maxLength  int
+	maxRetries int
+	threshold  float64
+
+	mu         sync.Mutex
+	window     [collisionWindow]bool
+	next       int
+	filled     int
+	collisions int
+}
+
+// New crée le générateur correspondant aux options. Les options doivent avoir été validées avec Validate ;
+// une stratégie inconnue retombe sur la stratégie random. counter n'est utilisé que par la stratégie sequential.
+func New(opts Options, counter Counter) *Adaptive {
+	opts = opts.withDefaults()
+
+	var generator Generator
+	switch opts.Strategy {
+	case StrategySequential:
+		generator = newSequentialGenerator(opts.Alphabet, opts.Length, opts.MaxLength, counter)
+	case StrategyPronounceable:
+		generator = newPronounceableGenerator(opts.Length)
+	default:
+		generator = newRandomGenerator(opts.Alphabet, opts.Length)
+	}
+
+	return &Adaptive{
+		generator:  generator,
+		filter:     opts.Filter,
+		maxLength:  opts.MaxLength,
+		maxRetries: opts.MaxRetries,
+		threshold:  opts.GrowthThreshold,
+	}
+}
+
+// Generate produit un code candidat. Les codes contenant un mot interdit sont écartés et régénérés.
+func (a *Adaptive) Generate() (string, error) {
+	for i := 0; i < maxFilteredAttempts; i++ {
+		code, err := a.generator.Generate()
+		if err != nil || !a.filter.Match(code) {
+			return code, err
+		}
+	}
+	return "", fmt.Errorf("no acceptable short code after %d attempts, every candidate contained a blocked word", maxFilteredAttempts)
+}
+
+// MaxRetries retourne le nombre de tentatives autorisées pour trouver un code libre.
+func (a *Adaptive) MaxRetries() int {
+	return a.maxRetries
+}
+
+// Record enregistre l'issue d'une tentative (collision ou non) et allonge les codes
+// si le taux de collision des dernières tentatives dépasse le seuil.
+func (a *Adaptive) Record(collision bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.filled == collisionWindow {
+		if a.window[a.next] {
+			a.collisions--
+		}
+	} else {
+		a.filled++
+	}
+	a.window[a.next] = collision
+	if collision {
+		a.collisions++
+	}
+	a.next = (a.next + 1) % collisionWindow
+
+	if a.filled < collisionWindow || float64(a.collisions)/float64(a.filled) <= a.threshold {
+		return
+	}
+	g, ok := a.generator.(growable)
+	if !ok || !g.Grow(a.maxLength) {
+		return
+	}
+	log.Printf("Short code collision rate reached %d/%d, growing generated codes by one character.", a.collisions, a.filled)
+	a.window = [collisionWindow]bool{}
+	a.next, a.filled, a.collisions = 0, 0, 0
+}
@@ -0,0 +1,192 @@
+package shortcode
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// sliceCounter retourne un compteur en mémoire qui commence à 0.
+func sliceCounter() Counter {
+	var mu sync.Mutex
+	var next uint64
+	return func() (uint64, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		value := next
+		next++
+		return value, nil
+	}
+}
+
+func TestOptionsValidate(t *testing.T) {
+	valid := []Options{
+		{},
+		{Strategy: StrategySequential, Alphabet: "23456789abcdefghijkmnpqrstuvwxyz"},
+		{Strategy: StrategyPronounceable, Length: 8},
+	}
+	for _, opts := range valid {
+		if err := opts.Validate(); err != nil {
+			t.Errorf("Validate(%+v) : erreur inattendue : %v", opts, err)
+		}
+	}
+
+	invalid := []Options{
+		{Strategy: "uuid"},
+		{Length: 12},
+		{Length: 8, MaxLength: 7},
+		{MaxLength: 11},
+		{GrowthThreshold: 1.5},
+		{Alphabet: "abc/"},
+		{Alphabet: "abca"},
+		{Alphabet: "a"},
+	}
+	for _, opts := range invalid {
+		if err := opts.Validate(); err == nil {
+			t.Errorf("Validate(%+v) : une erreur était attendue", opts)
+		}
+	}
+}
+
+func TestRandomGenerator(t *testing.T) {
+	const alphabet = "abcdefghjkmnpqrstuvwxyz23456789" // sans 0, O, 1, l ni I
+	g := New(Options{Alphabet: alphabet, Length: 8}, nil)
+	for i := 0; i < 200; i++ {
+		code, err := g.Generate()
+		if err != nil {
+			t.Fatalf("Generate : %v", err)
+		}
+		if len(code) != 8 {
+			t.Fatalf("code %q de longueur %d, attendu 8", code, len(code))
+		}
+		if strings.Trim(code, alphabet) != "" {
+			t.Fatalf("code %q contient un caractère hors de l'alphabet", code)
+		}
+	}
+}
+
+func TestSequentialGeneratorNeverRepeats(t *testing.T) {
+	// Alphabet de 3 caractères : 9 codes de 2 caractères, puis 27 de 3 caractères, puis l'espace est épuisé.
+	g := New(Options{Strategy: StrategySequential, Alphabet: "abc", Length: 2, MaxLength: 3}, sliceCounter())
+	seen := make(map[string]bool)
+	for i := 0; i < 36; i++ {
+		code, err := g.Generate()
+		if err != nil {
+			t.Fatalf("Generate n°%d : %v", i, err)
+		}
+		wantLength := 2
+		if i >= 9 {
+			wantLength = 3
+		}
+		if len(code) != wantLength {
+			t.Errorf("code n°%d %q de longueur %d, attendu %d", i, code, len(code), wantLength)
+		}
+		if seen[code] {
+			t.Fatalf("code %q produit deux fois", code)
+		}
+		seen[code] = true
+	}
+	if _, err := g.Generate(); !errors.Is(err, ErrExhausted) {
+		t.Errorf("Generate après épuisement : erreur %v, attendu ErrExhausted", err)
+	}
+}
+
+func TestSequentialGeneratorScattersConsecutiveValues(t *testing.T) {
+	g := newSequentialGenerator(DefaultAlphabet, 6, MaxLength, nil)
+	first, _ := g.encode(1)
+	second, _ := g.encode(2)
+	if first[:4] == second[:4] {
+		t.Errorf("codes de valeurs consécutives trop proches : %q et %q", first, second)
+	}
+}
+
+func TestSequentialGeneratorRequiresCounter(t *testing.T) {
+	if _, err := New(Options{Strategy: StrategySequential}, nil).Generate(); err == nil {
+		t.Error("Generate sans compteur : une erreur était attendue")
+	}
+}
+
+func TestPronounceableGenerator(t *testing.T) {
+	for length := 1; length <= MaxLength; length++ {
+		g := newPronounceableGenerator(length)
+		for i := 0; i < 200; i++ {
+			code, err := g.Generate()
+			if err != nil {
+				t.Fatalf("Generate : %v", err)
+			}
+			if len(code) != length {
+				t.Fatalf("code %q de longueur %d, attendu %d", code, len(code), length)
+			}
+			if !isPronounceable(code) {
+				t.Fatalf("code %q n'est pas fait de syllabes", code)
+			}
+		}
+	}
+}
+
+// isPronounceable indique si code se découpe en syllabes connues, suivies au plus d'une consonne finale.
+func isPronounceable(code string) bool {
+	if len(code) == 1 {
+		return strings.Contains(vowels, code)
+	}
+	syllables := make(map[string]bool)
+	for _, syllable := range append(append([]string{}, shortSyllables...), longSyllables...) {
+		syllables[syllable] = true
+	}
+	var split func(rest string) bool
+	split = func(rest string) bool {
+		switch {
+		case rest == "":
+			return true
+		case len(rest) == 1:
+			return strings.Contains(finals, rest)
+		}
+		return (syllables[rest[:2]] && split(rest[2:])) || (len(rest) >= 3 && syllables[rest[:3]] && split(rest[3:]))
+	}
+	return split(code)
+}
+
+func TestAdaptiveGrowsOnCollisions(t *testing.T) {
+	g := New(Options{Length: 4, MaxLength: 5, GrowthThreshold: 0.5}, nil)
+	for i := 0; i < collisionWindow; i++ {
+		g.Record(i%4 == 0) // 25 % de collisions, sous le seuil
+	}
+	if code, _ := g.Generate(); len(code) != 4 {
+		t.Fatalf("code %q : la longueur ne doit pas augmenter sous le seuil", code)
+	}
+
+	for i := 0; i < collisionWindow; i++ {
+		g.Record(true)
+	}
+	if code, _ := g.Generate(); len(code) != 5 {
+		t.Fatalf("code %q : la longueur doit augmenter au-delà du seuil", code)
+	}
+
+	for i := 0; i < collisionWindow; i++ {
+		g.Record(true)
+	}
+	if code, _ := g.Generate(); len(code) != 5 {
+		t.Fatalf("code %q : la longueur ne doit pas dépasser MaxLength", code)
+	}
+}
+
+func TestAdaptiveSkipsBlockedWords(t *testing.T) {
+	filter := &WordFilter{words: []string{"aa"}}
+	g := New(Options{Alphabet: "ab", Length: 3, Filter: filter}, nil)
+	for i := 0; i < 100; i++ {
+		code, err := g.Generate()
+		if err != nil {
+			t.Fatalf("Generate : %v", err)
+		}
+		if strings.Contains(code, "aa") {
+			t.Fatalf("code %q contient un mot bloqué", code)
+		}
+	}
+
+	// Avec un alphabet dont tous les codes sont refusés, la génération abandonne au lieu de boucler.
+	g = New(Options{Alphabet: "ab", Length: 3, Filter: &WordFilter{words: []string{"a", "b"}}}, nil)
+	if _, err := g.Generate(); err == nil {
+		t.Error("Generate : une erreur était attendue lorsque tous les codes sont refusés")
+	}
+}
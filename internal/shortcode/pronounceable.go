@@ -0,0 +1,102 @@
+package shortcode
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync/atomic"
+)
+
+// Lettres des syllabes prononçables. Les consonnes ambiguës à l'oral (c, q, w, x, y) sont exclues.
+const (
+	consonants = "bdfghjklmnprstvz"
+	vowels     = "aeiou"
+	// finals sont les consonnes qui peuvent terminer un mot ("lumen", "tabor") lorsqu'il reste une seule lettre.
+	finals = "lmnrst"
+)
+
+// clusters sont des attaques de deux consonnes courantes, qui donnent des syllabes de trois lettres ("tra", "blo").
+var clusters = []string{"bl", "br", "dr", "fl", "fr", "gl", "gr", "kl", "kr", "pl", "pr", "st", "tr", "vr"}
+
+// shortSyllables (consonne + voyelle, "ba", "ko") et longSyllables (attaque double + voyelle, ou consonne +
+// diphtongue, "tra", "mou") sont les syllabes dont sont faits les codes prononçables.
+var shortSyllables, longSyllables = buildSyllables()
+
+// buildSyllables construit les syllabes de deux et de trois lettres.
+func buildSyllables() (short, long []string) {
+	for _, c := range consonants {
+		for _, v := range vowels {
+			short = append(short, string(c)+string(v))
+		}
+	}
+	for _, cluster := range clusters {
+		for _, v := range vowels {
+			long = append(long, cluster+string(v))
+		}
+	}
+	for _, c := range consonants {
+		for _, diphthong := range []string{"ai", "au", "ei", "ou"} {
+			long = append(long, string(c)+diphthong)
+		}
+	}
+	return short, long
+}
+
+// pronounceableGenerator produit des mots inventés faits de syllabes enchaînées (ex: "bikoda", "tralumi"),
+// faciles à lire et à dicter. Les codes ont exactement la longueur demandée.
+type pronounceableGenerator struct {
+	length atomic.Int32
+}
+
+// newPronounceableGenerator crée un générateur de codes prononçables de la longueur donnée.
+func newPronounceableGenerator(length int) *pronounceableGenerator {
+	g := &pronounceableGenerator{}
+	g.length.Store(int32(length))
+	return g
+}
+
+// Generate produit un code prononçable : des syllabes de deux ou trois lettres tirées au hasard, et une consonne
+// finale si une seule lettre reste à écrire.
+func (g *pronounceableGenerator) Generate() (string, error) {
+	length := int(g.length.Load())
+	var code strings.Builder
+	for remaining := length; remaining > 0; remaining = length - code.Len() {
+		var syllable string
+		var err error
+		switch {
+		case remaining == 1 && code.Len() == 0:
+			syllable, err = randomString(vowels, 1)
+		case remaining == 1:
+			syllable, err = randomString(finals, 1)
+		case remaining == 2 || remaining == 4:
+			// Deux syllabes courtes plutôt qu'une longue suivie d'une consonne finale isolée
+			syllable, err = randomSyllable(shortSyllables, nil)
+		default:
+			syllable, err = randomSyllable(shortSyllables, longSyllables)
+		}
+		if err != nil {
+			return "", err
+		}
+		code.WriteString(syllable)
+	}
+	return code.String(), nil
+}
+
+// randomSyllable tire une syllabe au hasard parmi short et long réunies.
+func randomSyllable(short, long []string) (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(int64(len(short)+len(long))))
+	if err != nil {
+		return "", fmt.Errorf("failed to generate secure random index: %w", err)
+	}
+	i := int(n.Int64())
+	if i < len(short) {
+		return short[i], nil
+	}
+	return long[i-len(short)], nil
+}
+
+// Grow allonge d'un caractère les codes produits.
+func (g *pronounceableGenerator) Grow(maxLength int) bool {
+	return growLength(&g.length, maxLength)
+}
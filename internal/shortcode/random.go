@@ -0,0 +1,58 @@
+package shortcode
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"sync/atomic"
+)
+
+// randomGenerator tire chaque caractère au hasard dans l'alphabet.
+// Il utilise le package 'crypto/rand' pour éviter la prévisibilité.
+type randomGenerator struct {
+	alphabet string
+	length   atomic.Int32
+}
+
+// newRandomGenerator crée un générateur aléatoire de codes de la longueur donnée.
+func newRandomGenerator(alphabet string, length int) *randomGenerator {
+	g := &randomGenerator{alphabet: alphabet}
+	g.length.Store(int32(length))
+	return g
+}
+
+// Generate produit un code aléatoire.
+func (g *randomGenerator) Generate() (string, error) {
+	return randomString(g.alphabet, int(g.length.Load()))
+}
+
+// Grow allonge d'un caractère les codes produits.
+func (g *randomGenerator) Grow(maxLength int) bool {
+	return growLength(&g.length, maxLength)
+}
+
+// randomString retourne une chaîne de la longueur donnée, dont chaque caractère est tiré dans alphabet.
+func randomString(alphabet string, length int) (string, error) {
+	code := make([]byte, length)
+	for i := range code {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(alphabet))))
+		if err != nil {
+			return "", fmt.Errorf("failed to generate secure random index: %w", err)
+		}
+		code[i] = alphabet[n.Int64()]
+	}
+	return string(code), nil
+}
+
+// growLength incrémente une longueur partagée sans dépasser maxLength.
+func growLength(length *atomic.Int32, maxLength int) bool {
+	for {
+		current := length.Load()
+		if int(current) >= maxLength {
+			return false
+		}
+		if length.CompareAndSwap(current, current+1) {
+			return true
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package shortcode
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// scatterRatio (2 - φ ≈ 0,382, φ étant le nombre d'or) sert à choisir le multiplicateur
+// qui disperse les valeurs successives du compteur.
+var scatterRatio = big.NewRat(381966011250105, 1000000000000000)
+
+// multiplier retourne un entier proche de space × 0,382, premier avec base : la multiplication
+// modulo space (= base^longueur) est alors une bijection, qui ne produit jamais deux fois le même code
+// tout en éloignant les codes de valeurs consécutives du compteur.
+func multiplier(base, space *big.Int) *big.Int {
+	m := new(big.Rat).Mul(new(big.Rat).SetInt(space), scatterRatio)
+	candidate := new(big.Int).Quo(m.Num(), m.Denom())
+	one := big.NewInt(1)
+	if candidate.Cmp(one) < 0 {
+		candidate.Set(one)
+	}
+	for new(big.Int).GCD(nil, nil, candidate, base).Cmp(one) != 0 {
+		candidate.Add(candidate, one)
+	}
+	return candidate
+}
+
+// sequentialGenerator encode un compteur persistant dans l'alphabet. Chaque valeur du compteur
+// donne un code distinct : les collisions ne peuvent venir que des alias personnalisés ou des imports.
+// Les codes sont de longueur minLength tant que l'espace correspondant n'est pas épuisé, puis s'allongent.
+type sequentialGenerator struct {
+	alphabet  string
+	minLength int
+	maxLength int
+	counter   Counter
+}
+
+// newSequentialGenerator crée un générateur séquentiel alimenté par counter.
+func newSequentialGenerator(alphabet string, minLength, maxLength int, counter Counter) *sequentialGenerator {
+	return &sequentialGenerator{
+		alphabet:  alphabet,
+		minLength: minLength,
+		maxLength: maxLength,
+		counter:   counter,
+	}
+}
+
+// Generate encode la prochaine valeur du compteur.
+func (g *sequentialGenerator) Generate() (string, error) {
+	if g.counter == nil {
+		return "", errors.New("sequential short codes require a persistent counter")
+	}
+	value, err := g.counter()
+	if err != nil {
+		return "", fmt.Errorf("failed to read short code sequence: %w", err)
+	}
+	return g.encode(value)
+}
+
+// encode convertit une valeur du compteur en code. Les valeurs sont réparties par longueur croissante :
+// les base^minLength premières donnent des codes de minLength caractères, les suivantes un caractère de plus, etc.
+func (g *sequentialGenerator) encode(value uint64) (string, error) {
+	base := big.NewInt(int64(len(g.alphabet)))
+	remaining := new(big.Int).SetUint64(value)
+
+	for length := g.minLength; length <= g.maxLength; length++ {
+		space := new(big.Int).Exp(base, big.NewInt(int64(length)), nil)
+		if remaining.Cmp(space) >= 0 {
+			remaining.Sub(remaining, space)
+			continue
+		}
+
+		scrambled := new(big.Int).Mul(remaining, multiplier(base, space))
+		scrambled.Mod(scrambled, space)
+
+		code := make([]byte, length)
+		digit := new(big.Int)
+		for i := length - 1; i >= 0; i-- {
+			scrambled.DivMod(scrambled, base, digit)
+			code[i] = g.alphabet[digit.Int64()]
+		}
+		return string(code), nil
+	}
+	return "", fmt.Errorf("%w: sequence value %d does not fit in %d characters", ErrExhausted, value, g.maxLength)
+}
@@ -0,0 +1,174 @@
+package snapshot
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// MaxSelectorLength est la longueur maximale d'un sélecteur, comme la colonne watch_selector.
+const MaxSelectorLength = 200
+
+// ErrInvalidSelector signale un sélecteur CSS mal formé ou hors du sous-ensemble supporté.
+var ErrInvalidSelector = errors.New("invalid selector")
+
+// Selector est un sélecteur CSS restreint au sous-ensemble utile pour cibler le contenu principal d'une page :
+// type (main, article...), identifiant (#produit), classes (.prix.promo), combinateur descendant (espace) et
+// alternatives séparées par des virgules, ex: "main article.fiche, #contenu". Le sélecteur vide cible tout le document.
+type Selector struct {
+	alternatives [][]compound // Chaque alternative est une suite de sélecteurs composés, du plus externe au plus interne
+}
+
+// compound est un sélecteur composé : tag#id.classe1.classe2 (chaque partie est facultative, "*" pour tout type).
+type compound struct {
+	tag     string
+	id      string
+	classes []string
+}
+
+// ParseSelector analyse un sélecteur CSS. Une chaîne vide donne le sélecteur vide.
+func ParseSelector(s string) (Selector, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return Selector{}, nil
+	}
+	if len(s) > MaxSelectorLength {
+		return Selector{}, fmt.Errorf("%w: longer than %d characters", ErrInvalidSelector, MaxSelectorLength)
+	}
+
+	var sel Selector
+	for _, alternative := range strings.Split(s, ",") {
+		fields := strings.Fields(alternative)
+		if len(fields) == 0 {
+			return Selector{}, fmt.Errorf("%w: empty alternative in %q", ErrInvalidSelector, s)
+		}
+		chain := make([]compound, 0, len(fields))
+		for _, field := range fields {
+			c, err := parseCompound(field)
+			if err != nil {
+				return Selector{}, err
+			}
+			chain = append(chain, c)
+		}
+		sel.alternatives = append(sel.alternatives, chain)
+	}
+	return sel, nil
+}
+
+// parseCompound analyse un sélecteur composé (sans espace).
+func parseCompound(s string) (compound, error) {
+	var c compound
+	// Découpe "tag#id.a.b" en parties préfixées par "#" ou "."
+	parts := strings.FieldsFunc(s, func(r rune) bool { return r == '#' || r == '.' })
+	rest := s
+	if !strings.HasPrefix(s, "#") && !strings.HasPrefix(s, ".") {
+		c.tag = strings.ToLower(parts[0])
+		rest = s[len(parts[0]):]
+		if c.tag == "*" {
+			c.tag = ""
+		} else if !validName(c.tag) {
+			return compound{}, fmt.Errorf("%w: unsupported element %q", ErrInvalidSelector, parts[0])
+		}
+	}
+	for rest != "" {
+		prefix := rest[0]
+		rest = rest[1:]
+		end := strings.IndexAny(rest, "#.")
+		if end < 0 {
+			end = len(rest)
+		}
+		name := rest[:end]
+		rest = rest[end:]
+		if !validName(name) {
+			return compound{}, fmt.Errorf("%w: unsupported part %q in %q", ErrInvalidSelector, string(prefix)+name, s)
+		}
+		if prefix == '#' {
+			if c.id != "" {
+				return compound{}, fmt.Errorf("%w: several ids in %q", ErrInvalidSelector, s)
+			}
+			c.id = name
+		} else {
+			c.classes = append(c.classes, name)
+		}
+	}
+	return c, nil
+}
+
+// validName indique si name est un nom d'élément, d'identifiant ou de classe supporté (lettres, chiffres, "-" et "_").
+func validName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, r := range name {
+		if !(r == '-' || r == '_' || r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r > 0x7f) {
+			return false
+		}
+	}
+	return true
+}
+
+// Empty indique si le sélecteur est vide (tout le document est ciblé).
+func (s Selector) Empty() bool {
+	return len(s.alternatives) == 0
+}
+
+// Matches indique si l'élément n est ciblé par l'une des alternatives du sélecteur.
+func (s Selector) Matches(n *html.Node) bool {
+	for _, chain := range s.alternatives {
+		if matchesChain(n, chain) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesChain vérifie que n correspond au dernier sélecteur composé de chain et que ses ancêtres
+// correspondent aux précédents, dans l'ordre (combinateur descendant).
+func matchesChain(n *html.Node, chain []compound) bool {
+	last := len(chain) - 1
+	if !chain[last].matches(n) {
+		return false
+	}
+	i := last - 1
+	for ancestor := n.Parent; ancestor != nil && i >= 0; ancestor = ancestor.Parent {
+		if ancestor.Type == html.ElementNode && chain[i].matches(ancestor) {
+			i--
+		}
+	}
+	return i < 0
+}
+
+// matches indique si l'élément n correspond au sélecteur composé.
+func (c compound) matches(n *html.Node) bool {
+	if c.tag != "" && n.Data != c.tag {
+		return false
+	}
+	var id, class string
+	for _, attr := range n.Attr {
+		switch attr.Key {
+		case "id":
+			id = attr.Val
+		case "class":
+			class = attr.Val
+		}
+	}
+	if c.id != "" && id != c.id {
+		return false
+	}
+	classes := strings.Fields(class)
+	for _, want := range c.classes {
+		found := false
+		for _, have := range classes {
+			if have == want {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
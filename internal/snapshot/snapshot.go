@@ -0,0 +1,167 @@
+package snapshot
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"math/bits"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Réglages de l'empreinte de similarité.
+const (
+	DefaultThreshold = 16 // Bits SimHash différents (sur 64) à partir desquels un changement est significatif
+	shingleSize      = 3  // Nombre de mots consécutifs formant chaque caractéristique du SimHash
+)
+
+// Fingerprint est l'empreinte du contenu textuel normalisé d'une page (ou de la partie ciblée par un sélecteur).
+type Fingerprint struct {
+	Hash    string // SHA-256 du texte normalisé, en hexadécimal : change au moindre écart
+	SimHash uint64 // Empreinte de similarité : proche (peu de bits différents) pour des contenus proches
+	Words   int    // Nombre de mots du texte normalisé ; 0 si le sélecteur ne cible rien
+}
+
+// ignoredElements sont les éléments dont le contenu n'est pas du texte affiché.
+var ignoredElements = map[atom.Atom]bool{
+	atom.Head:     true,
+	atom.Script:   true,
+	atom.Style:    true,
+	atom.Noscript: true,
+	atom.Template: true,
+	atom.Svg:      true,
+}
+
+// Take lit un document HTML et calcule l'empreinte de son texte affiché, limité aux éléments ciblés par sel
+// (tout le document si sel est vide). Le texte est normalisé avant le calcul : casse, ponctuation, espaces et
+// chiffres (dates, compteurs, prix...) sont ignorés, pour que seuls les changements de rédaction comptent.
+func Take(r io.Reader, sel Selector) (Fingerprint, error) {
+	doc, err := html.Parse(r)
+	if err != nil {
+		return Fingerprint{}, fmt.Errorf("failed to parse HTML: %w", err)
+	}
+
+	var text strings.Builder
+	var visit func(n *html.Node, selected bool)
+	visit = func(n *html.Node, selected bool) {
+		if n.Type == html.ElementNode {
+			if ignoredElements[n.DataAtom] {
+				return
+			}
+			selected = selected || sel.Matches(n)
+		}
+		if n.Type == html.TextNode && selected {
+			text.WriteString(n.Data)
+			text.WriteByte(' ')
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			visit(child, selected)
+		}
+	}
+	visit(doc, sel.Empty())
+
+	words := normalize(text.String())
+	sum := sha256.Sum256([]byte(strings.Join(words, " ")))
+	return Fingerprint{
+		Hash:    hex.EncodeToString(sum[:]),
+		SimHash: simHash(words),
+		Words:   len(words),
+	}, nil
+}
+
+// Compare retourne le nombre de bits SimHash qui diffèrent entre deux empreintes, et indique si le changement
+// est significatif : au moins threshold bits différents (DefaultThreshold si threshold <= 0), ou un contenu
+// apparu ou disparu (sélecteur qui ne cible plus rien, page vidée...).
+func Compare(previous, current Fingerprint, threshold int) (int, bool) {
+	if threshold <= 0 {
+		threshold = DefaultThreshold
+	}
+	if previous.Hash == current.Hash {
+		return 0, false
+	}
+	distance := bits.OnesCount64(previous.SimHash ^ current.SimHash)
+	if (previous.Words == 0) != (current.Words == 0) {
+		return distance, true
+	}
+	return distance, distance >= threshold
+}
+
+// FormatSimHash encode une empreinte SimHash pour son stockage (16 chiffres hexadécimaux).
+func FormatSimHash(simHash uint64) string {
+	return fmt.Sprintf("%016x", simHash)
+}
+
+// ParseSimHash décode une empreinte SimHash stockée par FormatSimHash.
+func ParseSimHash(s string) (uint64, error) {
+	simHash, err := strconv.ParseUint(s, 16, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid simhash %q: %w", s, err)
+	}
+	return simHash, nil
+}
+
+// normalize découpe un texte en mots en minuscules, sans ponctuation, chaque suite de chiffres étant remplacée
+// par "0" ("2024" et "12" deviennent "0", "mp3" devient "mp0").
+func normalize(text string) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+	for i, word := range words {
+		var b strings.Builder
+		inDigits := false
+		for _, r := range word {
+			if unicode.IsDigit(r) {
+				if !inDigits {
+					b.WriteByte('0')
+				}
+				inDigits = true
+				continue
+			}
+			inDigits = false
+			b.WriteRune(r)
+		}
+		words[i] = b.String()
+	}
+	return words
+}
+
+// simHash calcule l'empreinte de similarité (Charikar) des suites de shingleSize mots consécutifs :
+// chaque bit de l'empreinte est le bit majoritaire parmi les hachages de ces suites.
+func simHash(words []string) uint64 {
+	if len(words) == 0 {
+		return 0
+	}
+	var weights [64]int
+	add := func(feature string) {
+		h := fnv.New64a()
+		h.Write([]byte(feature))
+		sum := h.Sum64()
+		for bit := 0; bit < 64; bit++ {
+			if sum&(1<<uint(bit)) != 0 {
+				weights[bit]++
+			} else {
+				weights[bit]--
+			}
+		}
+	}
+	if len(words) < shingleSize {
+		add(strings.Join(words, " "))
+	}
+	for i := 0; i+shingleSize <= len(words); i++ {
+		add(strings.Join(words[i:i+shingleSize], " "))
+	}
+
+	var simHash uint64
+	for bit, weight := range weights {
+		if weight > 0 {
+			simHash |= 1 << uint(bit)
+		}
+	}
+	return simHash
+}
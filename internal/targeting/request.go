@@ -0,0 +1,106 @@
+package targeting
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Request décrit le visiteur d'un lien, tel que les règles de redirection le voient.
+type Request struct {
+	OS       string    // Système d'exploitation déduit du User-Agent (OSIOS, OSAndroid...)
+	Device   string    // Type d'appareil déduit du User-Agent (DeviceMobile, DeviceTablet...)
+	Language string    // Langue préférée (Accept-Language), en minuscules ; vide si absente
+	Country  string    // Pays de l'adresse IP (code ISO en majuscules), renseigné par l'appelant ; vide si inconnu
+	Time     time.Time // Instant de la requête
+}
+
+// NewRequest construit la description d'un visiteur à partir des en-têtes de sa requête.
+func NewRequest(userAgent, acceptLanguage string, now time.Time) Request {
+	os, device := ParseUserAgent(userAgent)
+	return Request{
+		OS:       os,
+		Device:   device,
+		Language: PreferredLanguage(acceptLanguage),
+		Time:     now,
+	}
+}
+
+// botMarkers sont des fragments de User-Agent propres aux robots (moteurs de recherche, aperçus de liens...).
+var botMarkers = []string{"bot", "crawler", "spider", "slurp", "facebookexternalhit", "preview", "curl/", "wget/", "python-requests"}
+
+// ParseUserAgent déduit le système d'exploitation et le type d'appareil d'un User-Agent.
+// La détection est volontairement simple : elle repose sur les marqueurs usuels des navigateurs.
+func ParseUserAgent(userAgent string) (os, device string) {
+	ua := strings.ToLower(userAgent)
+
+	switch {
+	case strings.Contains(ua, "windows phone"):
+		os = OSWindows
+	case strings.Contains(ua, "iphone"), strings.Contains(ua, "ipad"), strings.Contains(ua, "ipod"):
+		os = OSIOS
+	case strings.Contains(ua, "android"):
+		os = OSAndroid
+	case strings.Contains(ua, "cros"):
+		os = OSChromeOS
+	case strings.Contains(ua, "windows"):
+		os = OSWindows
+	case strings.Contains(ua, "macintosh"), strings.Contains(ua, "mac os x"):
+		os = OSMacOS
+	case strings.Contains(ua, "linux"):
+		os = OSLinux
+	default:
+		os = OSOther
+	}
+
+	for _, marker := range botMarkers {
+		if strings.Contains(ua, marker) {
+			return os, DeviceBot
+		}
+	}
+	switch {
+	case strings.Contains(ua, "ipad"), strings.Contains(ua, "tablet"),
+		os == OSAndroid && !strings.Contains(ua, "mobile"):
+		device = DeviceTablet
+	case strings.Contains(ua, "mobi"), strings.Contains(ua, "iphone"), strings.Contains(ua, "ipod"), strings.Contains(ua, "windows phone"):
+		device = DeviceMobile
+	default:
+		device = DeviceDesktop
+	}
+	return os, device
+}
+
+// PreferredLanguage retourne la langue de plus haute priorité d'un en-tête Accept-Language, en minuscules,
+// ou une chaîne vide si l'en-tête est absent ou n'accepte que "*".
+func PreferredLanguage(acceptLanguage string) string {
+	type weighted struct {
+		tag     string
+		quality float64
+	}
+	var languages []weighted
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || tag == "*" {
+			continue
+		}
+		quality := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			q, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			quality = q
+		}
+		if quality > 0 {
+			languages = append(languages, weighted{tag: tag, quality: quality})
+		}
+	}
+	if len(languages) == 0 {
+		return ""
+	}
+	// Tri stable : à qualité égale, l'ordre de l'en-tête est conservé
+	sort.SliceStable(languages, func(i, j int) bool { return languages[i].quality > languages[j].quality })
+	return languages[0].tag
+}
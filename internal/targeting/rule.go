@@ -0,0 +1,329 @@
+package targeting
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+	_ "time/tzdata" // Fuseaux horaires embarqués : les fenêtres horaires ne dépendent pas de la base du système
+)
+
+// ErrInvalidRule est retournée (enveloppée avec la raison précise) pour une règle de redirection invalide.
+var ErrInvalidRule = errors.New("invalid redirect rule")
+
+// MaxRules est le nombre maximal de règles par lien.
+const MaxRules = 20
+
+// Systèmes d'exploitation reconnus dans le User-Agent.
+const (
+	OSIOS      = "ios"
+	OSAndroid  = "android"
+	OSWindows  = "windows"
+	OSMacOS    = "macos"
+	OSLinux    = "linux"
+	OSChromeOS = "chromeos"
+	OSOther    = "other"
+)
+
+// Types d'appareils reconnus dans le User-Agent.
+const (
+	DeviceMobile  = "mobile"
+	DeviceTablet  = "tablet"
+	DeviceDesktop = "desktop"
+	DeviceBot     = "bot"
+)
+
+var (
+	knownOS      = map[string]bool{OSIOS: true, OSAndroid: true, OSWindows: true, OSMacOS: true, OSLinux: true, OSChromeOS: true, OSOther: true}
+	knownDevices = map[string]bool{DeviceMobile: true, DeviceTablet: true, DeviceDesktop: true, DeviceBot: true}
+	weekdays     = map[string]time.Weekday{"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday, "thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday}
+
+	namePattern     = regexp.MustCompile(`^[A-Za-z0-9._-]{1,50}$`)
+	languagePattern = regexp.MustCompile(`^[a-z]{2,3}(-[a-z0-9]{2,8})?$`)
+	countryPattern  = regexp.MustCompile(`^[a-z]{2}$`)
+)
+
+// Rule est une règle de redirection : si toutes ses conditions renseignées correspondent à la requête,
+// le visiteur est redirigé vers Target au lieu de l'URL longue du lien. Une condition vide correspond à tout.
+type Rule struct {
+	Name      string      `json:"name,omitempty"`      // Nom enregistré sur les clics ; "rule-<position>" par défaut
+	OS        []string    `json:"os,omitempty"`        // Systèmes d'exploitation acceptés (ios, android, windows, macos, linux, chromeos, other)
+	Devices   []string    `json:"devices,omitempty"`   // Types d'appareils acceptés (mobile, tablet, desktop, bot)
+	Languages []string    `json:"languages,omitempty"` // Langue préférée du visiteur (Accept-Language) : "fr" accepte aussi "fr-CA"
+	Countries []string    `json:"countries,omitempty"` // Pays de l'adresse IP du visiteur, codes ISO 3166-1 alpha-2 (ex: "FR")
+	Time      *TimeWindow `json:"time,omitempty"`      // Plage horaire pendant laquelle la règle s'applique
+	Target    string      `json:"target"`              // URL de destination
+}
+
+// TimeWindow est une plage horaire quotidienne, éventuellement restreinte à certains jours de la semaine.
+// Si To précède From, la plage passe minuit (ex: 22:00 - 06:00). Les jours sont ceux de la date locale de la requête.
+type TimeWindow struct {
+	From     string   `json:"from,omitempty"`     // Début inclus, "HH:MM"
+	To       string   `json:"to,omitempty"`       // Fin exclue, "HH:MM"
+	Days     []string `json:"days,omitempty"`     // mon, tue, wed, thu, fri, sat, sun ; tous les jours si vide
+	TimeZone string   `json:"timezone,omitempty"` // Fuseau IANA (ex: "Europe/Paris"), UTC par défaut
+}
+
+// Label retourne le nom d'une règle tel qu'il est enregistré sur les clics, d'après sa position (à partir de 0).
+func Label(rules []Rule, index int) string {
+	return rules[index].label(index)
+}
+
+// label retourne le nom de la règle, ou "rule-<position>" si elle n'en a pas.
+func (r Rule) label(index int) string {
+	if r.Name != "" {
+		return r.Name
+	}
+	return fmt.Sprintf("rule-%d", index+1)
+}
+
+// Normalize vérifie une liste de règles et retourne sa forme normalisée (valeurs en minuscules, codes pays en majuscules).
+// La validité des URLs cibles n'est pas vérifiée ici : elle relève du validateur d'URLs de l'appelant.
+func Normalize(rules []Rule) ([]Rule, error) {
+	if len(rules) > MaxRules {
+		return nil, fmt.Errorf("%w: at most %d rules are allowed", ErrInvalidRule, MaxRules)
+	}
+	names := make(map[string]bool)
+	normalized := make([]Rule, len(rules))
+	for i, rule := range rules {
+		position := i + 1
+		if rule.Name != "" && !namePattern.MatchString(rule.Name) {
+			return nil, fmt.Errorf("%w: rule %d: name must be 1 to 50 letters, digits, '.', '-' or '_'", ErrInvalidRule, position)
+		}
+		if rule.Target == "" {
+			return nil, fmt.Errorf("%w: rule %d: target is required", ErrInvalidRule, position)
+		}
+		if len(rule.OS) == 0 && len(rule.Devices) == 0 && len(rule.Languages) == 0 && len(rule.Countries) == 0 && rule.Time == nil {
+			return nil, fmt.Errorf("%w: rule %d: at least one condition is required", ErrInvalidRule, position)
+		}
+
+		var err error
+		if rule.OS, err = normalizeValues(rule.OS, position, "os", func(v string) bool { return knownOS[v] }); err != nil {
+			return nil, err
+		}
+		if rule.Devices, err = normalizeValues(rule.Devices, position, "device", func(v string) bool { return knownDevices[v] }); err != nil {
+			return nil, err
+		}
+		if rule.Languages, err = normalizeValues(rule.Languages, position, "language", languagePattern.MatchString); err != nil {
+			return nil, err
+		}
+		if rule.Countries, err = normalizeValues(rule.Countries, position, "country", countryPattern.MatchString); err != nil {
+			return nil, err
+		}
+		for j, country := range rule.Countries {
+			rule.Countries[j] = strings.ToUpper(country) // Les codes pays s'écrivent en majuscules, comme sur les clics
+		}
+		if rule.Time != nil {
+			window, err := normalizeWindow(*rule.Time, position)
+			if err != nil {
+				return nil, err
+			}
+			rule.Time = &window
+		}
+
+		label := rule.label(i)
+		if names[label] {
+			return nil, fmt.Errorf("%w: rule %d: name %q is already used", ErrInvalidRule, position, label)
+		}
+		names[label] = true
+		normalized[i] = rule
+	}
+	return normalized, nil
+}
+
+// UsesCountry indique si au moins une règle dépend du pays du visiteur.
+func UsesCountry(rules []Rule) bool {
+	for _, rule := range rules {
+		if len(rule.Countries) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Encode sérialise une liste de règles pour son stockage avec le lien ; une liste vide donne une chaîne vide.
+func Encode(rules []Rule) (string, error) {
+	if len(rules) == 0 {
+		return "", nil
+	}
+	encoded, err := json.Marshal(rules)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode redirect rules: %w", err)
+	}
+	return string(encoded), nil
+}
+
+// Decode relit une liste de règles stockée avec un lien (chaîne vide pour aucune règle).
+func Decode(encoded string) ([]Rule, error) {
+	if encoded == "" {
+		return nil, nil
+	}
+	var rules []Rule
+	if err := json.Unmarshal([]byte(encoded), &rules); err != nil {
+		return nil, fmt.Errorf("failed to decode redirect rules: %w", err)
+	}
+	return rules, nil
+}
+
+// normalizeValues met en minuscules les valeurs d'une condition et vérifie qu'elles sont reconnues.
+func normalizeValues(values []string, position int, kind string, valid func(string) bool) ([]string, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+	normalized := make([]string, len(values))
+	for i, value := range values {
+		value = strings.ToLower(strings.TrimSpace(value))
+		if !valid(value) {
+			return nil, fmt.Errorf("%w: rule %d: unknown %s %q", ErrInvalidRule, position, kind, values[i])
+		}
+		normalized[i] = value
+	}
+	return normalized, nil
+}
+
+// normalizeWindow vérifie une plage horaire.
+func normalizeWindow(window TimeWindow, position int) (TimeWindow, error) {
+	if (window.From == "") != (window.To == "") {
+		return window, fmt.Errorf("%w: rule %d: time window needs both from and to", ErrInvalidRule, position)
+	}
+	if window.From == "" && len(window.Days) == 0 {
+		return window, fmt.Errorf("%w: rule %d: time window needs hours or days", ErrInvalidRule, position)
+	}
+	if window.From != "" {
+		from, errFrom := parseClock(window.From)
+		to, errTo := parseClock(window.To)
+		if errFrom != nil || errTo != nil {
+			return window, fmt.Errorf("%w: rule %d: time window hours must be formatted as HH:MM", ErrInvalidRule, position)
+		}
+		if from == to {
+			return window, fmt.Errorf("%w: rule %d: time window must not be empty", ErrInvalidRule, position)
+		}
+	}
+	days, err := normalizeValues(window.Days, position, "day", func(v string) bool { _, ok := weekdays[v]; return ok })
+	if err != nil {
+		return window, err
+	}
+	window.Days = days
+	if _, err := loadLocation(window.TimeZone); err != nil {
+		return window, fmt.Errorf("%w: rule %d: unknown time zone %q", ErrInvalidRule, position, window.TimeZone)
+	}
+	return window, nil
+}
+
+// parseClock convertit une heure "HH:MM" en minutes depuis minuit.
+func parseClock(value string) (int, error) {
+	clock, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, err
+	}
+	return clock.Hour()*60 + clock.Minute(), nil
+}
+
+// locations met en cache les fuseaux horaires déjà chargés.
+var locations sync.Map
+
+// loadLocation retourne le fuseau horaire IANA demandé (UTC si vide).
+func loadLocation(name string) (*time.Location, error) {
+	if name == "" {
+		return time.UTC, nil
+	}
+	if location, ok := locations.Load(name); ok {
+		return location.(*time.Location), nil
+	}
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, err
+	}
+	locations.Store(name, location)
+	return location, nil
+}
+
+// Match retourne la position de la première règle correspondant à la requête, et false si aucune ne correspond.
+func Match(rules []Rule, req Request) (int, bool) {
+	for i := range rules {
+		if rules[i].matches(req) {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// matches indique si toutes les conditions renseignées de la règle correspondent à la requête.
+func (r Rule) matches(req Request) bool {
+	if len(r.OS) > 0 && !contains(r.OS, req.OS) {
+		return false
+	}
+	if len(r.Devices) > 0 && !contains(r.Devices, req.Device) {
+		return false
+	}
+	if len(r.Languages) > 0 && !matchesLanguage(r.Languages, req.Language) {
+		return false
+	}
+	if len(r.Countries) > 0 && !contains(r.Countries, req.Country) {
+		return false
+	}
+	if r.Time != nil && !r.Time.contains(req.Time) {
+		return false
+	}
+	return true
+}
+
+// contains indique si value figure dans values.
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesLanguage indique si la langue préférée correspond à l'une des langues de la règle :
+// "fr" accepte "fr" et ses variantes régionales, "fr-ca" uniquement "fr-ca".
+func matchesLanguage(languages []string, language string) bool {
+	if language == "" {
+		return false
+	}
+	for _, l := range languages {
+		if language == l || strings.HasPrefix(language, l+"-") {
+			return true
+		}
+	}
+	return false
+}
+
+// contains indique si l'instant t se trouve dans la plage horaire.
+func (w TimeWindow) contains(t time.Time) bool {
+	location, err := loadLocation(w.TimeZone)
+	if err != nil {
+		return false
+	}
+	local := t.In(location)
+
+	if len(w.Days) > 0 {
+		found := false
+		for _, day := range w.Days {
+			if weekdays[day] == local.Weekday() {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if w.From == "" {
+		return true
+	}
+	from, _ := parseClock(w.From)
+	to, _ := parseClock(w.To)
+	minute := local.Hour()*60 + local.Minute()
+	if from < to {
+		return minute >= from && minute < to
+	}
+	return minute >= from || minute < to // La plage passe minuit
+}
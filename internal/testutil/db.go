@@ -0,0 +1,32 @@
+// Package testutil regroupe les utilitaires partagés par les tests des différents packages.
+package testutil
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// NewDB ouvre une base SQLite temporaire migrée, fermée à la fin du test. Le délai d'attente du verrou permet
+// aux écritures concurrentes des tests de se succéder au lieu d'échouer.
+func NewDB(t testing.TB) *gorm.DB {
+	t.Helper()
+	dsn := filepath.Join(t.TempDir(), "test.db") + "?_pragma=busy_timeout(10000)&_pragma=journal_mode(WAL)"
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("ouverture de la base : %v", err)
+	}
+	if err := db.AutoMigrate(models.All...); err != nil {
+		t.Fatalf("migration de la base : %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return db
+}
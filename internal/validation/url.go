@@ -0,0 +1,176 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/idna"
+)
+
+// ErrInvalidURL est retournée (enveloppée avec la raison précise) pour toute URL de destination refusée.
+var ErrInvalidURL = errors.New("invalid url")
+
+// DefaultMaxLength est la longueur maximale par défaut d'une URL de destination.
+const DefaultMaxLength = 2048
+
+// defaultPorts associe chaque schéma accepté à son port par défaut, retiré lors de la normalisation.
+var defaultPorts = map[string]string{
+	"http":  "80",
+	"https": "443",
+}
+
+// URLValidator est le pipeline de validation partagé par l'API, la CLI et les imports.
+// Il normalise une URL de destination puis applique les règles de sécurité configurées.
+type URLValidator struct {
+	maxLength    int
+	blockPrivate bool
+	ownHosts     map[string]bool
+	lookupIP     func(host string) ([]net.IP, error)
+}
+
+// Options configure un URLValidator.
+type Options struct {
+	MaxLength    int      // 0 pour DefaultMaxLength
+	BlockPrivate bool     // Refuse les destinations privées, loopback ou link-local
+	ResolveHosts bool     // Avec BlockPrivate, résout aussi les noms d'hôte via DNS pour vérifier leurs adresses
+	OwnURLs      []string // URLs du service lui-même (ex: server.base_url), refusées comme destination pour éviter les boucles
+}
+
+// NewURLValidator crée un validateur à partir des options fournies.
+// Les URLs de OwnURLs illisibles sont ignorées.
+func NewURLValidator(opts Options) *URLValidator {
+	v := &URLValidator{
+		maxLength:    opts.MaxLength,
+		blockPrivate: opts.BlockPrivate,
+		ownHosts:     make(map[string]bool),
+	}
+	if v.maxLength <= 0 {
+		v.maxLength = DefaultMaxLength
+	}
+	if opts.BlockPrivate && opts.ResolveHosts {
+		v.lookupIP = net.LookupIP
+	}
+	for _, own := range opts.OwnURLs {
+		if normalized, err := Normalize(own); err == nil {
+			parsed, _ := url.Parse(normalized)
+			v.ownHosts[parsed.Host] = true
+		}
+	}
+	return v
+}
+
+// AddOwnHost déclare un hôte supplémentaire servi par l'application (ex: "sho.rt" ou "sho.rt:8080").
+func (v *URLValidator) AddOwnHost(host string) {
+	if normalized, err := Normalize("http://" + host); err == nil {
+		parsed, _ := url.Parse(normalized)
+		v.ownHosts[parsed.Host] = true
+	}
+}
+
+// Validate normalise une URL de destination et vérifie qu'elle est acceptable.
+// Elle retourne la forme canonique de l'URL, à stocker à la place de l'URL d'origine.
+func (v *URLValidator) Validate(rawURL string) (string, error) {
+	if len(rawURL) > v.maxLength {
+		return "", fmt.Errorf("%w: url is too long (maximum %d characters)", ErrInvalidURL, v.maxLength)
+	}
+
+	normalized, err := Normalize(rawURL)
+	if err != nil {
+		return "", err
+	}
+	parsed, _ := url.Parse(normalized)
+
+	if v.ownHosts[parsed.Host] {
+		return "", fmt.Errorf("%w: destination %q points back to this service", ErrInvalidURL, parsed.Host)
+	}
+
+	if v.blockPrivate {
+		if err := v.checkPublicHost(parsed.Hostname()); err != nil {
+			return "", err
+		}
+	}
+	return normalized, nil
+}
+
+// checkPublicHost refuse les hôtes qui désignent le réseau local ou la machine elle-même.
+// Un nom d'hôte impossible à résoudre est accepté : il peut être temporairement indisponible.
+func (v *URLValidator) checkPublicHost(host string) error {
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return fmt.Errorf("%w: loopback destination %q is not allowed", ErrInvalidURL, host)
+	}
+
+	ips := []net.IP{net.ParseIP(host)}
+	if ips[0] == nil {
+		if v.lookupIP == nil {
+			return nil
+		}
+		resolved, err := v.lookupIP(host)
+		if err != nil {
+			return nil
+		}
+		ips = resolved
+	}
+
+	for _, ip := range ips {
+		if IsPrivateIP(ip) {
+			return fmt.Errorf("%w: private destination %q (%s) is not allowed", ErrInvalidURL, host, ip)
+		}
+	}
+	return nil
+}
+
+// IsPrivateIP indique si une adresse est privée, loopback, link-local ou non spécifiée.
+func IsPrivateIP(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast()
+}
+
+// Normalize retourne la forme canonique d'une URL http(s) absolue :
+//   - schéma et hôte en minuscules, noms de domaine internationalisés convertis en punycode ;
+//   - point final du nom d'hôte et port par défaut retirés ;
+//   - chemin vide remplacé par "/".
+//
+// Les barres obliques finales des autres chemins sont conservées : "/docs" et "/docs/" peuvent
+// désigner deux ressources différentes pour le serveur de destination. Le fragment l'est aussi :
+// il désigne une route d'application monopage (ex: "#/settings") ou une ancre de la page.
+// Tout autre schéma (javascript:, data:, ftp:...) est refusé.
+func Normalize(rawURL string) (string, error) {
+	parsed, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidURL, err)
+	}
+
+	parsed.Scheme = strings.ToLower(parsed.Scheme)
+	defaultPort, ok := defaultPorts[parsed.Scheme]
+	if !ok {
+		return "", fmt.Errorf("%w: scheme %q is not allowed, only http and https are accepted", ErrInvalidURL, parsed.Scheme)
+	}
+	if parsed.Opaque != "" || parsed.Hostname() == "" {
+		return "", fmt.Errorf("%w: %q has no host", ErrInvalidURL, rawURL)
+	}
+
+	host := strings.TrimSuffix(parsed.Hostname(), ".")
+	if ip := net.ParseIP(host); ip == nil {
+		host, err = idna.Lookup.ToASCII(host)
+		if err != nil {
+			return "", fmt.Errorf("%w: invalid host: %v", ErrInvalidURL, err)
+		}
+	} else if ip.To4() == nil {
+		host = "[" + ip.String() + "]"
+	} else {
+		host = ip.String()
+	}
+	if port := parsed.Port(); port != "" && port != defaultPort {
+		host += ":" + port
+	}
+
+	parsed.Host = host
+	if parsed.Path == "" {
+		parsed.Path = "/"
+		parsed.RawPath = ""
+	}
+	return parsed.String(), nil
+}
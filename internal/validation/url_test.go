@@ -0,0 +1,103 @@
+package validation
+
+import (
+	"errors"
+	"net"
+	"strings"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"schéma et hôte en minuscules", "HTTPS://Example.COM/Path", "https://example.com/Path"},
+		{"port par défaut retiré", "http://example.com:80/a", "http://example.com/a"},
+		{"port https par défaut retiré", "https://example.com:443/a", "https://example.com/a"},
+		{"autre port conservé", "https://example.com:8443/a", "https://example.com:8443/a"},
+		{"point final retiré", "https://example.com./a", "https://example.com/a"},
+		{"chemin vide", "https://example.com", "https://example.com/"},
+		{"barre oblique finale conservée", "https://example.com/docs/", "https://example.com/docs/"},
+		{"requête conservée", "https://example.com/a?b=1&c=2", "https://example.com/a?b=1&c=2"},
+		{"route d'application monopage conservée", "https://app.example.com/#/settings/profile", "https://app.example.com/#/settings/profile"},
+		{"ancre conservée", "https://example.com/guide#install", "https://example.com/guide#install"},
+		{"nom de domaine internationalisé", "https://bücher.example/", "https://xn--bcher-kva.example/"},
+		{"IPv6", "http://[::1]:80/", "http://[::1]/"},
+		{"espaces retirés", "  https://example.com/a  ", "https://example.com/a"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Normalize(tt.in)
+			if err != nil {
+				t.Fatalf("Normalize(%q) : erreur inattendue : %v", tt.in, err)
+			}
+			if got != tt.want {
+				t.Errorf("Normalize(%q) = %q, attendu %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeRejects(t *testing.T) {
+	for _, in := range []string{
+		"javascript:alert(1)",
+		"data:text/html,<script>alert(1)</script>",
+		"ftp://example.com/file",
+		"example.com/sans-schema",
+		"https://",
+		"http:example.com",
+		"://example.com",
+	} {
+		if _, err := Normalize(in); !errors.Is(err, ErrInvalidURL) {
+			t.Errorf("Normalize(%q) : erreur %v, attendu ErrInvalidURL", in, err)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	v := NewURLValidator(Options{
+		MaxLength:    64,
+		BlockPrivate: true,
+		OwnURLs:      []string{"http://localhost:8080"},
+	})
+	v.AddOwnHost("sho.rt")
+
+	if got, err := v.Validate("HTTPS://Example.com/#/a"); err != nil || got != "https://example.com/#/a" {
+		t.Errorf("Validate : %q, %v ; attendu la forme canonique", got, err)
+	}
+
+	for _, in := range []string{
+		"https://example.com/" + strings.Repeat("a", 64),
+		"http://localhost:8080/abc",
+		"https://SHO.RT/abc",
+		"http://127.0.0.1/",
+		"http://10.0.0.1/",
+		"http://192.168.1.1/",
+		"http://169.254.169.254/latest/meta-data",
+		"http://[::1]/",
+		"http://app.localhost/",
+	} {
+		if _, err := v.Validate(in); !errors.Is(err, ErrInvalidURL) {
+			t.Errorf("Validate(%q) : erreur %v, attendu ErrInvalidURL", in, err)
+		}
+	}
+}
+
+func TestValidateResolvesHosts(t *testing.T) {
+	v := NewURLValidator(Options{BlockPrivate: true})
+	v.lookupIP = func(host string) ([]net.IP, error) {
+		if host == "intranet.example" {
+			return []net.IP{net.ParseIP("10.1.2.3")}, nil
+		}
+		return []net.IP{net.ParseIP("93.184.216.34")}, nil
+	}
+
+	if _, err := v.Validate("https://intranet.example/"); !errors.Is(err, ErrInvalidURL) {
+		t.Errorf("un nom d'hôte résolu en adresse privée doit être refusé, erreur %v", err)
+	}
+	if _, err := v.Validate("https://example.com/"); err != nil {
+		t.Errorf("un nom d'hôte public doit être accepté : %v", err)
+	}
+}
@@ -3,32 +3,42 @@ package workers
 import (
 	"log"
 
+	"github.com/axellelanca/urlshortener/internal/geoip"
 	"github.com/axellelanca/urlshortener/internal/models"
 	"github.com/axellelanca/urlshortener/internal/repository" // Nécessaire pour interagir avec le ClickRepository
 )
 
 // StartClickWorkers lance un pool de goroutines "workers" pour traiter les événements de clic.
 // Chaque worker lira depuis le même 'clickEventsChan' et utilisera le 'clickRepo' pour la persistance.
-func StartClickWorkers(workerCount int, clickEventsChan <-chan models.ClickEvent, clickRepo repository.ClickRepository) {
+// L'adresse IP de chaque clic est géolocalisée par les workers avec 'geo' (nil pour ne pas géolocaliser),
+// afin de ne pas ralentir les redirections.
+func StartClickWorkers(workerCount int, clickEventsChan <-chan models.ClickEvent, clickRepo repository.ClickRepository, geo *geoip.Resolver) {
 	log.Printf("Starting %d click worker(s)...", workerCount)
 	for i := 0; i < workerCount; i++ {
 		// Lance chaque worker dans sa propre goroutine.
 		// Le channel est passé en lecture seule (<-chan) pour renforcer l'immutabilité du channel à l'intérieur du worker.
-		go clickWorker(clickEventsChan, clickRepo)
+		go clickWorker(clickEventsChan, clickRepo, geo)
 	}
 }
 
 // clickWorker est la fonction exécutée par chaque goroutine worker.
 // Elle tourne indéfiniment, lisant les événements de clic dès qu'ils sont disponibles dans le channel.
-func clickWorker(clickEventsChan <-chan models.ClickEvent, clickRepo repository.ClickRepository) {
+func clickWorker(clickEventsChan <-chan models.ClickEvent, clickRepo repository.ClickRepository, geo *geoip.Resolver) {
 	for event := range clickEventsChan { // Boucle qui lit les événements du channel
 		// TODO 1: Convertir le 'ClickEvent' (reçu du channel) en un modèle 'models.Click'.
 		click := models.Click{
-			LinkID:    event.LinkID,
-			Timestamp: event.Timestamp,
-			UserAgent: event.UserAgent,
-			IPAddress: event.IPAddress,
+			LinkID:      event.LinkID,
+			WorkspaceID: event.WorkspaceID,
+			Timestamp:   event.Timestamp,
+			UserAgent:   event.UserAgent,
+			IPAddress:   event.IPAddress,
+			Source:      event.Source,
+			Rule:        event.Rule,
+			Variant:     event.Variant,
 		}
+		location := geo.Lookup(event.IPAddress)
+		click.Country = location.Country
+		click.Region = location.Region
 		// TODO 2: Persister le clic en base de données via le 'clickRepo' (CreateClick).
 		// Implémentez ici une gestion d'erreur simple : loggez l'erreur si la persistance échoue.
 		// Pour un système en production, une logique de retry